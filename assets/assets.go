@@ -0,0 +1,21 @@
+// Package assets embeds the default ops table, dialect card and syntax
+// guide, so binaries do not depend on the working directory.
+package assets
+
+import _ "embed"
+
+// OpsJSON is the built-in ops table (ops.json), generated by
+// `envllm ops dump`.
+//
+//go:embed ops.json
+var OpsJSON []byte
+
+// DialectCard is the short DSL reference given to models (dialect_card.md).
+//
+//go:embed dialect_card.md
+var DialectCard string
+
+// SyntaxGuide is the full DSL syntax guide (syntax_guide.md).
+//
+//go:embed syntax_guide.md
+var SyntaxGuide string
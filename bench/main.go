@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/agenthands/envllm/assets"
 	"github.com/agenthands/envllm/bench/runner"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/googleai"
@@ -33,7 +34,7 @@ func (m *MockModel) Complete(ctx context.Context, caseID, task, prompt string) (
 		case "G":
 			return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"llm\"\nCELL main:\n  SUBCALL SOURCE PROMPT TASK \"Summarize this\" DEPTH_COST 1 INTO out: JSON\n  SET_FINAL SOURCE out\nOUTPUT out\n", nil
 		case "H":
-			return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\nCELL main:\n  READ_FILE PATH \"/etc/shadow\" INTO out: TEXT\n  SET_FINAL SOURCE out\nOUTPUT out\n", nil
+			return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\nCELL main:\n  READ_FILE PATH \"/etc/shadow\" MODE TEXT INTO out: TEXT\n  SET_FINAL SOURCE out\nOUTPUT out\n", nil
 		case "I":
 			return "TASK test:\n  INPUT PROMPT: TEXT\nCELL main:\n  FIND_TEXT SOURCE PROMPT NEEDLE \"Needle\" MODE FIRST IGNORE_CASE false INTO pos: OFFSET\n  SET_FINAL SOURCE pos\nOUTPUT pos\n", nil
 		}
@@ -51,11 +52,11 @@ func (m *MockModel) Complete(ctx context.Context, caseID, task, prompt string) (
 	case "Enforce step budget":
 		return "TASK test:\n  INPUT PROMPT: TEXT\nCELL main:\n  STATS SOURCE PROMPT INTO stats: STRUCT\n  GET_FIELD SOURCE stats FIELD \"lines\" INTO count: JSON\n  TO_TEXT VALUE count INTO out: TEXT\n  SET_FINAL SOURCE out\n  OUTPUT out\n", nil
 	case "Denied capability access":
-		return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\n  CELL main:\n    READ_FILE PATH \"/etc/passwd\" INTO out: TEXT\n    SET_FINAL SOURCE out\n  OUTPUT out\n", nil
+		return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\n  CELL main:\n    READ_FILE PATH \"/etc/passwd\" MODE TEXT INTO out: TEXT\n    SET_FINAL SOURCE out\n  OUTPUT out\n", nil
 	case "Enforce recursion depth limit":
 		return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"llm\"\n  CELL main:\n    SUBCALL SOURCE PROMPT TASK \"recursive\" DEPTH_COST 10 INTO out: JSON\n    SET_FINAL SOURCE out\n  OUTPUT out\n", nil
 	case "Mandatory REQUIRES declaration":
-		return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\nCELL main:\n  READ_FILE PATH \"foo\" INTO out: TEXT\n  SET_FINAL SOURCE out\n  OUTPUT out\n", nil
+		return "TASK test:\n  INPUT PROMPT: TEXT\n  REQUIRES capability=\"fs_read\"\nCELL main:\n  READ_FILE PATH \"foo\" MODE TEXT INTO out: TEXT\n  SET_FINAL SOURCE out\n  OUTPUT out\n", nil
 	case "Extract credentials JSON":
 		return "TASK test:\n  INPUT PROMPT: TEXT\n  CELL main:\n    JSON_PARSE SOURCE \"{\\\"user\\\": \\\"admin\\\", \\\"pass\\\": \\\"hunter2\\\"}\" INTO out: JSON\n    SET_FINAL SOURCE out\n  OUTPUT out\n", nil
 	case "Find error offset":
@@ -107,6 +108,8 @@ type Stats struct {
 func main() {
 	useLLM := flag.Bool("llm", false, "Use a real LLM for benchmarks")
 	suiteFilter := flag.String("suite", "", "Filter by suite name (e.g. suiteA.jsonl)")
+	opsPath := flag.String("ops", "", "Path to a custom ops table (default: built-in)")
+	cardPath := flag.String("dialect-card", "", "Path to a custom dialect card (default: built-in)")
 	flag.Parse()
 
 	fmt.Println("EnvLLM Benchmark Runner v0.1")
@@ -138,8 +141,16 @@ func main() {
 			fmt.Printf("Error creating model: %v\n", err)
 			os.Exit(1)
 		}
-		card, _ := os.ReadFile("assets/dialect_card.md")
-		m = &RealLLMModel{model: model, dialectCard: string(card)}
+		card := assets.DialectCard
+		if *cardPath != "" {
+			data, err := os.ReadFile(*cardPath)
+			if err != nil {
+				fmt.Printf("Error reading dialect card: %v\n", err)
+				os.Exit(1)
+			}
+			card = string(data)
+		}
+		m = &RealLLMModel{model: model, dialectCard: card}
 		fmt.Println("Mode: Real LLM (Default)")
 	} else {
 		m = &MockModel{}
@@ -158,7 +169,7 @@ func main() {
 
 		fmt.Printf("\nRunning suite: %s\n", f.Name())
 		s := &Stats{}
-		runSuite(ctx, filepath.Join(casesDir, f.Name()), m, baseDir, s)
+		runSuite(ctx, filepath.Join(casesDir, f.Name()), m, baseDir, *opsPath, s)
 		summary[f.Name()] = s
 	}
 
@@ -189,7 +200,7 @@ func main() {
 	fmt.Printf("%-20s %-10d %-10d %-10.1f%%\n", "GRAND TOTAL", grandTotal, grandPassed, grandPct)
 }
 
-func runSuite(ctx context.Context, path string, m runner.Model, baseDir string, s *Stats) {
+func runSuite(ctx context.Context, path string, m runner.Model, baseDir, opsPath string, s *Stats) {
 	file, err := os.Open(path)
 	if err != nil {
 		fmt.Printf("  Error opening suite: %v\n", err)
@@ -205,6 +216,10 @@ func runSuite(ctx context.Context, path string, m runner.Model, baseDir string,
 			continue
 		}
 
+		if opsPath != "" {
+			c.OpsTable = opsPath
+		}
+
 		s.Total++
 		res, err := runner.RunCase(ctx, c, m, baseDir)
 		if err != nil {
@@ -42,6 +42,9 @@ type Case struct {
 	Scoring     ScoringConfig  `json:"scoring"`
 	Mode        string         `json:"mode,omitempty"` // "compat" or "strict"
 	Host        runtime.Host   `json:"-"`
+	// OpsTable is the path of a custom ops table; empty means the built-in
+	// table.
+	OpsTable string `json:"ops_table,omitempty"`
 }
 
 type dummyHost struct {
@@ -80,9 +83,12 @@ func RunCase(ctx context.Context, c Case, m Model, baseDir string) (Result, erro
 	}
 
 	// Setup ops table
-	tbl, err := ops.LoadTable("assets/ops.json")
+	tbl, err := ops.LoadTableOrDefault(c.OpsTable)
 	if err != nil {
-		tbl, _ = ops.LoadTable("../assets/ops.json")
+		return Result{}, fmt.Errorf("failed to load ops table: %v", err)
+	}
+	if _, err := ops.NewRegistry(tbl); err != nil {
+		return Result{}, fmt.Errorf("failed to load ops table: %v", err)
 	}
 
 	mode := envllm.ModeCompat
@@ -171,6 +177,7 @@ func RunCase(ctx context.Context, c Case, m Model, baseDir string) (Result, erro
 		Policy:    c.Policy,
 		TextStore: ts,
 		Host:      host,
+		OpsTable:  c.OpsTable,
 		Inputs: map[string]runtime.Value{
 			"PROMPT": {Kind: runtime.KindText, V: ph},
 		},
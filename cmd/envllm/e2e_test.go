@@ -19,7 +19,10 @@ func TestEndToEnd(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadTable failed: %v", err)
 	}
-	reg := ops.NewRegistry(tbl)
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
 	ts := store.NewTextStore()
 	s := runtime.NewSession(runtime.Policy{MaxStmtsPerCell: 100}, ts)
 	s.Dispatcher = reg
@@ -66,7 +69,10 @@ CELL find:
 
 func TestEndToEnd_Recursive(t *testing.T) {
 	tbl, _ := ops.LoadTable("../../assets/ops.json")
-	reg := ops.NewRegistry(tbl)
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
 	ts := store.NewTextStore()
 	policy := runtime.Policy{
 		MaxSubcalls:         5,
@@ -108,7 +114,10 @@ CELL recurse:
 
 func TestEndToEnd_RegexAndCapabilities(t *testing.T) {
 	tbl, _ := ops.LoadTable("../../assets/ops.json")
-	reg := ops.NewRegistry(tbl)
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
 	ts := store.NewTextStore()
 
 	// Policy WITHOUT 'llm' capability
@@ -156,7 +165,7 @@ CELL denied:
 	p2 := parse.NewParser(l2, parse.ModeCompat)
 	prog2, _ := p2.Parse()
 
-	err := s.ExecuteTask(context.Background(), prog2.Task)
+	err = s.ExecuteTask(context.Background(), prog2.Task)
 	if err == nil || !strings.Contains(err.Error(), "denied by policy") {
 		t.Errorf("expected capability denied error, got %v", err)
 	}
@@ -165,7 +174,10 @@ CELL denied:
 func TestEndToEnd_FileSystem(t *testing.T) {
 	tmpDir := t.TempDir()
 	tbl, _ := ops.LoadTable("../../assets/ops.json")
-	reg := ops.NewRegistry(tbl)
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
 	ts := store.NewTextStore()
 
 	policy := runtime.Policy{
@@ -187,7 +199,7 @@ func TestEndToEnd_FileSystem(t *testing.T) {
 	code := `RLMDSL 0.1
 CELL fs:
   WRITE_FILE PATH FILE_PATH SOURCE "e2e content" INTO ok
-  READ_FILE PATH FILE_PATH INTO content
+  READ_FILE PATH FILE_PATH MODE TEXT INTO content
   SET_FINAL SOURCE content
 `
 	l := lex.NewLexer("fs.rlm", code)
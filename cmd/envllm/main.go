@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/agenthands/envllm/internal/extension"
 	dfmt "github.com/agenthands/envllm/internal/fmt"
 	"github.com/agenthands/envllm/internal/lint"
 	"github.com/agenthands/envllm/internal/migrate"
@@ -36,6 +37,8 @@ func main() {
 		migrateCmd()
 	case "check":
 		checkCmd()
+	case "ops":
+		opsCmd()
 	case "help", "-h", "--help":
 		usage()
 	default:
@@ -54,6 +57,8 @@ func usage() {
 	fmt.Println("  fmt <file>      Format script to canonical form")
 	fmt.Println("  migrate <file>  Migrate v0.1 script to v0.2 STRICT")
 	fmt.Println("  check <file>    Check script for v0.2 canonical errors")
+	fmt.Println("  ops dump        Print the ops table defined by the modules")
+	fmt.Println("  ops check       Check an ops table file against the modules")
 	fmt.Println("  help            Show this help text")
 }
 
@@ -63,6 +68,9 @@ func run() {
 	timeout := runCmd.Duration("timeout", 0, "Maximum wall time for execution")
 	modeStr := runCmd.String("mode", "compat", "Parser mode (compat or strict)")
 	tracePath := runCmd.String("trace", "", "Path to emit JSONL trace certificates")
+	storeDir := runCmd.String("store-dir", "", "Keep TEXT values in files under this directory instead of memory")
+	opsPath := runCmd.String("ops", "", "Path to a custom ops table (default: built-in)")
+	extDir := runCmd.String("ext", "", "Directory of extension manifests whose ops to register")
 
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: envllm run <file> [flags]")
@@ -108,16 +116,37 @@ func run() {
 		os.Exit(1)
 	}
 
+	ts := envllm.NewTextStore()
+	if *storeDir != "" {
+		ds, err := envllm.NewDiskTextStore(*storeDir)
+		if err != nil {
+			fmt.Printf("Text store error: %v\n", err)
+			os.Exit(1)
+		}
+		defer ds.Close()
+		ts = ds
+	}
+
 	opt := envllm.ExecOptions{
 		Policy: runtime.Policy{
 			MaxStmtsPerCell: *maxStmts,
 			MaxWallTime:     *timeout,
 		},
-		TextStore: envllm.NewTextStore(),
+		TextStore: ts,
 		TraceSink: sink,
 	}
 
-	res, err := prog.Execute(context.Background(), opt)
+	engineOpts := []envllm.EngineOption{envllm.WithOpsTable(*opsPath)}
+	if *extDir != "" {
+		engineOpts = append(engineOpts, envllm.WithExtensions(*extDir))
+	}
+	e, err := envllm.NewEngine(engineOpts...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	res, err := e.Execute(context.Background(), prog, opt)
 	if err != nil {
 		fmt.Printf("Execution error: %v\n", err)
 		os.Exit(1)
@@ -128,7 +157,14 @@ func run() {
 }
 
 func replCmd() {
-	repl.Start(os.Stdin, os.Stdout)
+	replFlagSet := flag.NewFlagSet("repl", flag.ExitOnError)
+	opsPath := replFlagSet.String("ops", "", "Path to a custom ops table (default: built-in)")
+	replFlagSet.Parse(os.Args[2:])
+
+	if err := repl.StartWithOpsTable(os.Stdin, os.Stdout, *opsPath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func validate() {
@@ -156,6 +192,8 @@ func validate() {
 func fmtCmd() {
 	fmtFlagSet := flag.NewFlagSet("fmt", flag.ExitOnError)
 	modeStr := fmtFlagSet.String("mode", "strict", "Format mode (compat or strict)")
+	opsPath := fmtFlagSet.String("ops", "", "Path to a custom ops table (default: built-in)")
+	extDir := fmtFlagSet.String("ext", "", "Directory of extension manifests whose ops to register")
 
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: envllm fmt <file> [flags]")
@@ -170,19 +208,27 @@ func fmtCmd() {
 		mode = envllm.ModeCompat
 	}
 
+	tbl, err := loadOpsTable(*opsPath, *extDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	src, _ := os.ReadFile(filename)
 	prog, err := envllm.Compile(filename, string(src), mode)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Print(dfmt.Format(prog.AST))
+	// Strict output spells out every default; compat only reorders clauses.
+	fmt.Print(dfmt.FormatWith(prog.AST, dfmt.Options{Table: tbl, FillDefaults: mode == envllm.ModeStrict}))
 }
 
 func migrateCmd() {
 	migrateFlagSet := flag.NewFlagSet("migrate", flag.ExitOnError)
 	from := migrateFlagSet.String("from", "v0.1", "Source version")
 	to := migrateFlagSet.String("to", "v0.2", "Target version")
+	opsPath := migrateFlagSet.String("ops", "", "Path to a custom ops table (default: built-in)")
 
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: envllm migrate <file> [flags]")
@@ -194,9 +240,17 @@ func migrateCmd() {
 
 	fmt.Printf("Migrating %s from %s to %s...\n", filename, *from, *to)
 
-	src, _ := os.ReadFile(filename)
-	
-	tbl, _ := ops.LoadTable("assets/ops.json")
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	tbl, err := loadOpsTable(*opsPath, "")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 	canonical, report, err := migrate.Migrate(string(src), tbl)
 	if err != nil {
 		fmt.Printf("Migration failed: %v\n", err)
@@ -214,6 +268,8 @@ func migrateCmd() {
 func checkCmd() {
 	checkFlagSet := flag.NewFlagSet("check", flag.ExitOnError)
 	modeStr := checkFlagSet.String("mode", "strict", "Check mode (compat or strict)")
+	opsPath := checkFlagSet.String("ops", "", "Path to a custom ops table (default: built-in)")
+	extDir := checkFlagSet.String("ext", "", "Directory of extension manifests whose ops to register")
 
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: envllm check <file> [flags]")
@@ -237,7 +293,11 @@ func checkCmd() {
 		os.Exit(1)
 	}
 
-	tbl, _ := ops.LoadTable("assets/ops.json")
+	tbl, err := loadOpsTable(*opsPath, *extDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 	lnt := lint.NewLinter(tbl)
 	if mode == envllm.ModeStrict {
 		lnt.WithMode(lint.ModeStrict)
@@ -257,3 +317,66 @@ func checkCmd() {
 	
 	fmt.Println("Canonical check passed (STRICT mode)")
 }
+
+// loadOpsTable loads the ops table at path, or the built-in one when path
+// is empty, and checks it against the modules the runtime would register.
+// The ops of the extension manifests in extDir, if set, are added to it.
+func loadOpsTable(path, extDir string) (*ops.Table, error) {
+	tbl, err := ops.LoadTableOrDefault(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ops table: %v", err)
+	}
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ops table: %v", err)
+	}
+	if extDir != "" {
+		if _, err := extension.RegisterDir(reg, extDir); err != nil {
+			return nil, fmt.Errorf("failed to load extensions: %v", err)
+		}
+	}
+	return tbl, nil
+}
+
+func opsCmd() {
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: envllm ops <dump|check> [flags]")
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "dump":
+		if err := ops.DumpTable(os.Stdout, ops.DefaultModules()...); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "check":
+		checkFlagSet := flag.NewFlagSet("ops check", flag.ExitOnError)
+		tablePath := checkFlagSet.String("table", "assets/ops.json", "Path to the ops table to check")
+		checkFlagSet.Parse(os.Args[3:])
+
+		want, err := ops.BuildTable(ops.DefaultModules()...)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		got, err := ops.LoadTable(*tablePath)
+		if err != nil {
+			fmt.Printf("Error loading %s: %v\n", *tablePath, err)
+			os.Exit(1)
+		}
+		diffs := ops.Diff(want, got)
+		if len(diffs) > 0 {
+			fmt.Printf("%s has drifted from the modules (%d differences):\n", *tablePath, len(diffs))
+			for _, d := range diffs {
+				fmt.Printf("- %s\n", d)
+			}
+			fmt.Println("Run 'envllm ops dump' to regenerate it.")
+			os.Exit(1)
+		}
+		fmt.Printf("%s matches the modules\n", *tablePath)
+	default:
+		fmt.Printf("Unknown ops command: %s\n", os.Args[2])
+		os.Exit(1)
+	}
+}
@@ -4,37 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
-	"github.com/agenthands/envllm/internal/ops"
-	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/assets"
 	"github.com/agenthands/envllm/pkg/envllm"
 	"github.com/tmc/langchaingo/llms"
 )
 
-// LangChainHost implements runtime.Host using LangChainGo.
+// LangChainHost implements envllm.Host using LangChainGo.
 type LangChainHost struct {
 	Model       llms.Model
-	Store       runtime.TextStore
+	Store       envllm.TextStore
 	DialectCard string
+	// OpsTable is the path of a custom ops table; empty means the built-in
+	// table.
+	OpsTable string
 }
 
-func NewLangChainHost(model llms.Model, store runtime.TextStore) *LangChainHost {
-	card, _ := os.ReadFile("assets/syntax_guide.md")
-	// If syntax guide missing, fall back to dialect card or empty
-	if len(card) == 0 {
-		card, _ = os.ReadFile("assets/dialect_card.md")
-	}
+// NewLangChainHost returns a host prompting with the built-in syntax guide.
+// Set DialectCard and OpsTable to override them.
+func NewLangChainHost(model llms.Model, store envllm.TextStore) *LangChainHost {
 	return &LangChainHost{
 		Model:       model,
 		Store:       store,
-		DialectCard: string(card),
+		DialectCard: assets.SyntaxGuide,
 	}
 }
 
 // Subcall implements the recursive call logic.
-func (h *LangChainHost) Subcall(ctx context.Context, req runtime.SubcallRequest) (runtime.SubcallResponse, error) {
+func (h *LangChainHost) Subcall(ctx context.Context, req envllm.SubcallRequest) (envllm.SubcallResponse, error) {
 	prompt := fmt.Sprintf(`%s
 
 TASK: %s
@@ -53,7 +51,7 @@ TASK sub_task:
 
 	completion, err := llms.GenerateFromSinglePrompt(ctx, h.Model, prompt)
 	if err != nil {
-		return runtime.SubcallResponse{}, err
+		return envllm.SubcallResponse{}, err
 	}
 
 	dslCode := h.StripMarkdown(completion)
@@ -64,31 +62,31 @@ TASK sub_task:
 	
 	prog, err := envllm.Compile("subcall.rlm", dslCode, envllm.ModeCompat)
 	if err != nil {
-		return runtime.SubcallResponse{}, fmt.Errorf("subcall DSL compilation failed: %v\nCode:\n%s", err, dslCode)
+		return envllm.SubcallResponse{}, fmt.Errorf("subcall DSL compilation failed: %v\nCode:\n%s", err, dslCode)
 	}
 
 	opt := envllm.ExecOptions{
-		Policy:    runtime.Policy{MaxStmtsPerCell: 50},
+		Policy:    envllm.Policy{MaxStmtsPerCell: 50},
 		TextStore: ts,
 		Host:      h,
-		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+		Inputs:    map[string]envllm.Value{"PROMPT": {Kind: envllm.KindText, V: ph}},
 	}
 
 	res, err := prog.Execute(ctx, opt)
 	if err != nil {
-		return runtime.SubcallResponse{}, err
+		return envllm.SubcallResponse{}, err
 	}
 
 	if res.Final == nil {
-		return runtime.SubcallResponse{}, fmt.Errorf("subcall did not produce a final result")
+		return envllm.SubcallResponse{}, fmt.Errorf("subcall did not produce a final result")
 	}
 
-	return runtime.SubcallResponse{
+	return envllm.SubcallResponse{
 		Result: *res.Final,
 	}, nil
 }
 
-func (h *LangChainHost) resolveHandle(handle runtime.TextHandle) string {
+func (h *LangChainHost) resolveHandle(handle envllm.TextHandle) string {
 	text, ok := h.Store.Get(handle)
 	if !ok {
 		return "[MISSING CONTENT]"
@@ -97,25 +95,24 @@ func (h *LangChainHost) resolveHandle(handle runtime.TextHandle) string {
 }
 
 // RunSession executes the RLM loop until completion or error.
-func (h *LangChainHost) RunSession(ctx context.Context, task string, ph runtime.TextHandle, policy runtime.Policy) (runtime.ExecResult, error) {
-	s := runtime.NewSession(policy, h.Store)
-	s.Host = h
-
-	// Setup dispatcher
-	tbl, err := ops.LoadTable("../../assets/ops.json")
+func (h *LangChainHost) RunSession(ctx context.Context, task string, ph envllm.TextHandle, policy envllm.Policy) (envllm.ExecResult, error) {
+	engine, err := envllm.NewEngine(envllm.WithOpsTable(h.OpsTable))
 	if err != nil {
-		tbl, _ = ops.LoadTable("assets/ops.json")
+		return envllm.ExecResult{}, err
 	}
-	s.Dispatcher = ops.NewRegistry(tbl)
-	
-	// Set initial prompt
-	if err := s.Env.Define("PROMPT", runtime.Value{Kind: runtime.KindText, V: ph}); err != nil {
-		return runtime.ExecResult{}, err
+	run, err := engine.NewRun(envllm.ExecOptions{
+		Host:      h,
+		Policy:    policy,
+		TextStore: h.Store,
+		Inputs:    map[string]envllm.Value{"PROMPT": {Kind: envllm.KindText, V: ph}},
+	})
+	if err != nil {
+		return envllm.ExecResult{}, err
 	}
 
 	for i := 0; i < 5; i++ {
-		obs := s.GenerateResult("ok", nil)
-		if s.Final != nil {
+		obs := run.Result()
+		if obs.Final != nil {
 			return obs, nil
 		}
 
@@ -161,12 +158,19 @@ func (h *LangChainHost) RunSession(ctx context.Context, task string, ph runtime.
 			return obs, fmt.Errorf("turn %d: DSL compilation failed: %v", i, err)
 		}
 
-		if err := s.ExecuteTask(ctx, prog.AST.Task); err != nil {
-			return s.GenerateResult("error", []runtime.Error{{Code: "EXEC_ERROR", Message: err.Error()}}), nil
+		res, err := run.Execute(ctx, prog)
+		if err != nil {
+			return res, err
+		}
+		if res.Status != "ok" {
+			return res, nil
 		}
 	}
 
-	return s.GenerateResult("error", []runtime.Error{{Code: "TIMEOUT", Message: "Max turns reached"}}), nil
+	obs := run.Result()
+	obs.Status = "error"
+	obs.Errors = []envllm.Error{{Code: "TIMEOUT", Message: "Max turns reached"}}
+	return obs, nil
 }
 
 func (h *LangChainHost) CleanDSL(s string) string {
@@ -244,7 +248,7 @@ TASK find_data:
   REQUIRES capability="fs_read"
 
   CELL read:
-    READ_FILE PATH "data.txt" INTO content: TEXT
+    READ_FILE PATH "data.txt" MODE TEXT INTO content: TEXT
     SET_FINAL SOURCE content
   
   OUTPUT content
@@ -6,15 +6,13 @@ import (
 	"os"
 
 	"github.com/agenthands/envllm/examples/bridge"
-	"github.com/agenthands/envllm/internal/runtime"
-	"github.com/agenthands/envllm/internal/store"
 	"github.com/agenthands/envllm/pkg/envllm"
 )
 
 func main() {
 	// 1. Initialize dependencies
 	ctx := context.Background()
-	ts := store.NewTextStore()
+	ts := envllm.NewTextStore()
 	
 	// Note: In a real run, you would use a real LangChainGo model provider.
 	// For this example, we show the setup logic.
@@ -43,10 +41,10 @@ Finally, click "Submit".`
 
 	opt := envllm.ExecOptions{
 		Host:      host,
-		Policy:    runtime.Policy{MaxStmtsPerCell: 50, MaxSubcalls: 5},
+		Policy:    envllm.Policy{MaxStmtsPerCell: 50, MaxSubcalls: 5},
 		TextStore: ts,
-		Inputs: map[string]runtime.Value{
-			"PROMPT": {Kind: runtime.KindText, V: ph},
+		Inputs: map[string]envllm.Value{
+			"PROMPT": {Kind: envllm.KindText, V: ph},
 		},
 	}
 
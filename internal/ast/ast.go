@@ -165,11 +165,23 @@ type NullExpr struct {
 func (e *NullExpr) Pos() lex.Loc { return e.Loc }
 func (e *NullExpr) exprNode()   {}
 
-// SetFinalStmt represents the SET_FINAL command.
+// ListExpr represents a list literal: [expr, expr, ...].
+type ListExpr struct {
+	Loc   lex.Loc `json:"-"`
+	Kind  string  `json:"kind"` // "LIST"
+	Items []Expr  `json:"items"`
+}
+
+func (e *ListExpr) Pos() lex.Loc { return e.Loc }
+func (e *ListExpr) exprNode()   {}
+
+// SetFinalStmt represents the SET_FINAL command. Citing, if present, holds
+// the CITE results offered as evidence for the final value.
 type SetFinalStmt struct {
 	Loc    lex.Loc `json:"-"`
 	Type   string  `json:"type"` // "set_final"
 	Source Expr    `json:"source"`
+	Citing Expr    `json:"citing,omitempty"`
 }
 
 func (s *SetFinalStmt) Pos() lex.Loc { return s.Loc }
@@ -258,6 +270,9 @@ func Walk(v Visitor, node Node) {
 		}
 	case *SetFinalStmt:
 		Walk(v, n.Source)
+		if n.Citing != nil {
+			Walk(v, n.Citing)
+		}
 	case *AssertStmt:
 		Walk(v, n.Cond)
 	case *PrintStmt:
@@ -266,6 +281,10 @@ func Walk(v Visitor, node Node) {
 		for _, stmt := range n.Body {
 			Walk(v, stmt)
 		}
+	case *ListExpr:
+		for _, item := range n.Items {
+			Walk(v, item)
+		}
 	case *IdentExpr, *StringExpr, *IntExpr, *BoolExpr, *NullExpr:
 		// Leaf
 	}
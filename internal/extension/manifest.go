@@ -1,5 +1,13 @@
 package extension
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
 // Manifest represents the extension's metadata and capabilities.
 type Manifest struct {
 	Name     string            `json:"name"`
@@ -15,17 +23,49 @@ type Provides struct {
 }
 
 // OpDef matches the internal registry format but exported for manifest.
+// ResultType is a value type or the name of one of the manifest's structs.
+// The op is implemented either by Steps or by Regex.
 type OpDef struct {
-	Name         string   `json:"name"`
-	Capabilities []string `json:"capabilities"`
-	ResultType   string   `json:"result_type"`
-	Signature    []Param  `json:"signature"`
+	Name         string         `json:"name"`
+	Capabilities []string       `json:"capabilities"`
+	ResultType   string         `json:"result_type"`
+	Signature    []Param        `json:"signature"`
+	Steps        []Step         `json:"steps,omitempty"`
+	Result       string         `json:"result,omitempty"`
+	Regex        *RegexTemplate `json:"regex,omitempty"`
 }
 
 type Param struct {
-	Kw   string   `json:"kw"`
-	Type string   `json:"type"`
-	Enum []string `json:"enum,omitempty"`
+	Kw       string   `json:"kw"`
+	Type     string   `json:"type"`
+	Enum     []string `json:"enum,omitempty"`
+	Optional bool     `json:"optional,omitempty"`
+	Default  string   `json:"default,omitempty"`
+}
+
+// Step runs an existing op, or one declared earlier in the manifest, and
+// binds its result to Into. Arg values are
+// JSON literals, except strings starting with "$", which name a param of
+// the op or the Into of an earlier step ("$$" escapes a literal "$").
+//
+// The op returns the step named by OpDef.Result, the last step by default.
+// If its result type is a struct, it returns the steps named after the
+// struct's fields instead.
+type Step struct {
+	Op   string                 `json:"op"`
+	Args map[string]interface{} `json:"args"`
+	Into string                 `json:"into"`
+}
+
+// RegexTemplate matches Pattern against the TEXT param named by Source
+// (SOURCE by default). "{{KW}}" in Pattern is replaced by the quoted value
+// of param KW. The op returns capture group Group as TEXT, or, if its
+// result type is a struct, the named groups as the struct's fields. A
+// pattern that does not match gives empty text.
+type RegexTemplate struct {
+	Source  string `json:"source,omitempty"`
+	Pattern string `json:"pattern"`
+	Group   int    `json:"group,omitempty"`
 }
 
 type StructDef struct {
@@ -62,3 +102,41 @@ type Deprecation struct {
 	OpName        string `json:"op_name"`
 	ReplacementID string `json:"replacement_id"`
 }
+
+// LoadManifest reads the manifest file at path.
+func LoadManifest(path string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %v", path, err)
+	}
+	if m.Name == "" {
+		return m, fmt.Errorf("%s: manifest has no name", path)
+	}
+	return m, nil
+}
+
+// LoadDir reads every *.json manifest in dir, in file name order.
+func LoadDir(dir string) ([]Manifest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var ms []Manifest
+	for _, path := range paths {
+		m, err := LoadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
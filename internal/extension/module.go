@@ -0,0 +1,364 @@
+package extension
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/ops"
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+var knownKinds = map[runtime.Kind]bool{
+	runtime.KindInt: true, runtime.KindFloat: true, runtime.KindBool: true,
+	runtime.KindText: true, runtime.KindJSON: true, runtime.KindSpan: true,
+	runtime.KindString: true, runtime.KindList: true, runtime.KindOffset: true,
+	runtime.KindStruct: true, runtime.KindRows: true, runtime.KindTimestamp: true,
+	runtime.KindBytes: true, runtime.KindDocs: true,
+}
+
+var placeholder = regexp.MustCompile(`\{\{([A-Z0-9_]+)\}\}`)
+
+// OpModule is an ops.Module whose ops are declared by a manifest and run
+// existing ops of a registry.
+type OpModule struct {
+	manifest Manifest
+	reg      *ops.Registry
+	ops      []ops.Op
+	handlers map[string]ops.OpImplementation
+}
+
+// NewOpModule checks the ops m provides against the ops reg already has and
+// builds their implementations. A step may only use ops whose capabilities
+// the declaring op also lists, so the op's REQUIRES cover everything it runs.
+func NewOpModule(m Manifest, reg *ops.Registry) (*OpModule, error) {
+	mod := &OpModule{manifest: m, reg: reg, handlers: make(map[string]ops.OpImplementation)}
+	structs := make(map[string]StructDef)
+	for _, sd := range m.Provides.Structs {
+		for field, typ := range sd.Fields {
+			if !knownKinds[runtime.Kind(typ)] {
+				return nil, fmt.Errorf("extension %s: struct %s: field %s has unknown type %q", m.Name, sd.Name, field, typ)
+			}
+		}
+		structs[sd.Name] = sd
+	}
+
+	for _, def := range m.Provides.Ops {
+		op, impl, err := mod.build(def, structs)
+		if err != nil {
+			return nil, fmt.Errorf("extension %s: op %s: %v", m.Name, def.Name, err)
+		}
+		mod.ops = append(mod.ops, op)
+		mod.handlers[op.Name] = impl
+	}
+	return mod, nil
+}
+
+func (m *OpModule) ID() string                                { return m.manifest.Name }
+func (m *OpModule) Operations() []ops.Op                      { return m.ops }
+func (m *OpModule) Handlers() map[string]ops.OpImplementation { return m.handlers }
+
+func (m *OpModule) build(def OpDef, structs map[string]StructDef) (ops.Op, ops.OpImplementation, error) {
+	op := ops.Op{Name: def.Name, Capabilities: def.Capabilities, Into: true}
+	if def.Name == "" {
+		return op, nil, fmt.Errorf("op has no name")
+	}
+	if len(op.Capabilities) == 0 {
+		op.Capabilities = []string{"pure"}
+	}
+	for _, p := range def.Signature {
+		op.Signature = append(op.Signature, ops.Param{
+			Kw: p.Kw, Type: runtime.Kind(p.Type), Enum: p.Enum, Optional: p.Optional, Default: p.Default,
+		})
+	}
+	for i, p := range op.Signature {
+		if p.Kw == "" {
+			return op, nil, fmt.Errorf("param %d has no keyword", i)
+		}
+		if _, err := p.DefaultValue(); err != nil {
+			return op, nil, err
+		}
+	}
+
+	var sd *StructDef
+	if s, ok := structs[def.ResultType]; ok {
+		sd = &s
+		op.ResultType = runtime.KindStruct
+	} else if knownKinds[runtime.Kind(def.ResultType)] {
+		op.ResultType = runtime.Kind(def.ResultType)
+	} else {
+		return op, nil, fmt.Errorf("unknown result type %q", def.ResultType)
+	}
+
+	switch {
+	case len(def.Steps) > 0 && def.Regex == nil:
+		impl, err := m.buildSteps(&op, def, sd)
+		return op, impl, err
+	case len(def.Steps) == 0 && def.Regex != nil:
+		impl, err := buildRegex(&op, *def.Regex, sd)
+		return op, impl, err
+	}
+	return op, nil, fmt.Errorf("needs either steps or a regex template")
+}
+
+// lookup finds an op of the registry or one declared earlier in the
+// manifest.
+func (m *OpModule) lookup(name string) (*ops.Op, bool) {
+	for i := range m.ops {
+		if m.ops[i].Name == name {
+			return &m.ops[i], true
+		}
+	}
+	op, ok := m.reg.Table.Ops[name]
+	return op, ok
+}
+
+// buildSteps checks a step sequence and returns the handler running it.
+func (m *OpModule) buildSteps(op *ops.Op, def OpDef, sd *StructDef) (ops.OpImplementation, error) {
+	allowed := make(map[string]bool)
+	for _, c := range op.Capabilities {
+		allowed[c] = true
+	}
+	// The kind of each name a step may refer to; "" when only known at run
+	// time.
+	names := make(map[string]runtime.Kind)
+	for _, p := range op.Signature {
+		names[p.Kw] = p.Type
+	}
+
+	for i, st := range def.Steps {
+		used, ok := m.lookup(st.Op)
+		if !ok {
+			return nil, fmt.Errorf("step %d: unknown op %s", i+1, st.Op)
+		}
+		for _, c := range used.Capabilities {
+			if c != "pure" && !allowed[c] {
+				return nil, fmt.Errorf("step %d: %s needs capability %q, which the op does not declare", i+1, st.Op, c)
+			}
+		}
+		keywords := make([]string, 0, len(st.Args))
+		for kw, v := range st.Args {
+			keywords = append(keywords, kw)
+			if ref, ok := reference(v); ok {
+				if _, known := names[ref]; !known {
+					return nil, fmt.Errorf("step %d: %s refers to undefined $%s", i+1, kw, ref)
+				}
+			} else if _, err := literal(v); err != nil {
+				return nil, fmt.Errorf("step %d: %s: %v", i+1, kw, err)
+			}
+		}
+		if _, err := used.Bind(keywords); err != nil {
+			return nil, fmt.Errorf("step %d: %v", i+1, err)
+		}
+		if st.Into == "" {
+			return nil, fmt.Errorf("step %d: missing into", i+1)
+		}
+		if _, dup := names[st.Into]; dup {
+			return nil, fmt.Errorf("step %d: %s is already defined", i+1, st.Into)
+		}
+		names[st.Into] = used.ResultType
+	}
+
+	result := def.Result
+	if result == "" {
+		result = def.Steps[len(def.Steps)-1].Into
+	}
+	if sd != nil {
+		for field, typ := range sd.Fields {
+			kind, ok := names[field]
+			if !ok {
+				return nil, fmt.Errorf("struct %s: no step into %s", sd.Name, field)
+			}
+			if kind != "" && kind != runtime.Kind(typ) {
+				return nil, fmt.Errorf("struct %s: field %s is %s, step gives %s", sd.Name, field, typ, kind)
+			}
+		}
+	} else if kind, ok := names[result]; !ok {
+		return nil, fmt.Errorf("result %s is not defined", result)
+	} else if kind != "" && kind != op.ResultType {
+		return nil, fmt.Errorf("result %s is %s, expected %s", result, kind, op.ResultType)
+	}
+
+	name := op.Name
+	return func(s *runtime.Session, args ops.Args) (runtime.Value, error) {
+		env := make(map[string]runtime.Value, len(args)+len(def.Steps))
+		for kw, v := range args {
+			env[kw] = v
+		}
+		for i, st := range def.Steps {
+			stepArgs := make(ops.Args, len(st.Args))
+			for kw, v := range st.Args {
+				if ref, ok := reference(v); ok {
+					stepArgs[kw] = env[ref]
+				} else {
+					stepArgs[kw], _ = literal(v)
+				}
+			}
+			res, err := m.reg.Call(s, st.Op, stepArgs)
+			if err != nil {
+				return runtime.Value{}, fmt.Errorf("%s: step %d (%s): %v", name, i+1, st.Op, err)
+			}
+			env[st.Into] = res
+		}
+
+		if sd == nil {
+			return env[result], nil
+		}
+		fields := make(map[string]interface{}, len(sd.Fields))
+		for field, typ := range sd.Fields {
+			v := env[field]
+			if v.Kind != runtime.Kind(typ) {
+				return runtime.Value{}, fmt.Errorf("%s: field %s is %s, got %s", name, field, typ, v.Kind)
+			}
+			fields[field] = v.V
+		}
+		return runtime.Value{Kind: runtime.KindStruct, V: fields}, nil
+	}, nil
+}
+
+// buildRegex checks a regex template and returns the handler applying it.
+func buildRegex(op *ops.Op, rt RegexTemplate, sd *StructDef) (ops.OpImplementation, error) {
+	source := rt.Source
+	if source == "" {
+		source = "SOURCE"
+	}
+	if p, ok := op.Param(source); !ok || p.Type != runtime.KindText {
+		return nil, fmt.Errorf("regex source %s must be a TEXT param", source)
+	}
+	for _, ph := range placeholder.FindAllStringSubmatch(rt.Pattern, -1) {
+		if _, ok := op.Param(ph[1]); !ok {
+			return nil, fmt.Errorf("pattern refers to undefined param %s", ph[1])
+		}
+	}
+	// Check the pattern with every placeholder standing for a literal.
+	re, err := regexp.Compile(placeholder.ReplaceAllString(rt.Pattern, "x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	if sd != nil {
+		for field, typ := range sd.Fields {
+			if re.SubexpIndex(field) < 0 {
+				return nil, fmt.Errorf("struct %s: pattern has no group named %s", sd.Name, field)
+			}
+			if runtime.Kind(typ) != runtime.KindText {
+				return nil, fmt.Errorf("struct %s: field %s must be TEXT to come from a regex", sd.Name, field)
+			}
+		}
+	} else {
+		if op.ResultType != runtime.KindText {
+			return nil, fmt.Errorf("a regex template returns TEXT or a struct, not %s", op.ResultType)
+		}
+		if rt.Group < 0 || rt.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("pattern has no group %d", rt.Group)
+		}
+	}
+
+	name := op.Name
+	return func(s *runtime.Session, args ops.Args) (runtime.Value, error) {
+		pat := placeholder.ReplaceAllStringFunc(rt.Pattern, func(ph string) string {
+			return regexp.QuoteMeta(argString(s, args[ph[2:len(ph)-2]]))
+		})
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("%s: invalid pattern %q: %v", name, pat, err)
+		}
+
+		h := args[source].V.(runtime.TextHandle)
+		text, _ := s.Stores.Text.Get(h)
+		match := re.FindStringSubmatchIndex(text)
+
+		group := func(i int) (runtime.Value, error) {
+			if match == nil || match[2*i] < 0 {
+				return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add("")}, nil
+			}
+			gh, err := s.Stores.Text.Slice(h, match[2*i], match[2*i+1])
+			if err != nil {
+				return runtime.Value{}, err
+			}
+			return runtime.Value{Kind: runtime.KindText, V: gh}, nil
+		}
+
+		if sd == nil {
+			return group(rt.Group)
+		}
+		fields := make(map[string]interface{}, len(sd.Fields))
+		for field := range sd.Fields {
+			v, err := group(re.SubexpIndex(field))
+			if err != nil {
+				return runtime.Value{}, err
+			}
+			fields[field] = v.V
+		}
+		return runtime.Value{Kind: runtime.KindStruct, V: fields}, nil
+	}, nil
+}
+
+// reference returns the name a "$name" step argument refers to.
+func reference(v interface{}) (string, bool) {
+	str, ok := v.(string)
+	if !ok || !strings.HasPrefix(str, "$") || strings.HasPrefix(str, "$$") {
+		return "", false
+	}
+	return str[1:], true
+}
+
+// literal converts a JSON step argument to a value.
+func literal(v interface{}) (runtime.Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return runtime.Value{Kind: runtime.KindNull}, nil
+	case bool:
+		return runtime.Value{Kind: runtime.KindBool, V: x}, nil
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return runtime.Value{Kind: runtime.KindInt, V: int(x)}, nil
+		}
+		return runtime.Value{Kind: runtime.KindFloat, V: x}, nil
+	case string:
+		return runtime.Value{Kind: runtime.KindString, V: strings.TrimPrefix(x, "$")}, nil
+	case []interface{}:
+		items := make([]runtime.Value, len(x))
+		for i, item := range x {
+			iv, err := literal(item)
+			if err != nil {
+				return runtime.Value{}, err
+			}
+			items[i] = iv
+		}
+		return runtime.Value{Kind: runtime.KindList, V: items}, nil
+	}
+	return runtime.Value{}, fmt.Errorf("unsupported literal %v", v)
+}
+
+// argString returns the text of a TEXT or STRING argument, or its printed
+// value.
+func argString(s *runtime.Session, v runtime.Value) string {
+	switch x := v.V.(type) {
+	case runtime.TextHandle:
+		text, _ := s.Stores.Text.Get(x)
+		return text
+	case string:
+		return x
+	}
+	return fmt.Sprint(v.V)
+}
+
+// RegisterDir loads the manifests in dir and registers their ops with reg,
+// in file name order, so a manifest may build on the ops of earlier ones.
+func RegisterDir(reg *ops.Registry, dir string) ([]Manifest, error) {
+	ms, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		mod, err := NewOpModule(m, reg)
+		if err != nil {
+			return nil, err
+		}
+		if err := reg.RegisterModule(mod); err != nil {
+			return nil, err
+		}
+	}
+	return ms, nil
+}
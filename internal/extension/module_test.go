@@ -0,0 +1,171 @@
+package extension
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/ast"
+	"github.com/agenthands/envllm/internal/ops"
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+const invoice = `ACME Corp
+Invoice #: INV-2041
+Date: 2026-03-04
+Total: $1,234
+`
+
+func newRegistry(t *testing.T) *ops.Registry {
+	t.Helper()
+	tbl, err := ops.LoadTable("../../assets/ops.json")
+	if err != nil {
+		t.Fatalf("LoadTable failed: %v", err)
+	}
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	return reg
+}
+
+func TestRegisterDir(t *testing.T) {
+	reg := newRegistry(t)
+	ms, err := RegisterDir(reg, "../../examples/extensions")
+	if err != nil {
+		t.Fatalf("RegisterDir failed: %v", err)
+	}
+	if len(ms) != 1 || ms[0].Name != "invoice_fields" {
+		t.Fatalf("unexpected manifests %+v", ms)
+	}
+	if op, ok := reg.Table.Ops["INVOICE_HEADER"]; !ok || op.ResultType != runtime.KindStruct {
+		t.Fatalf("expected INVOICE_HEADER in the table, got %+v", op)
+	}
+
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	s.Env.Define("doc", runtime.Value{Kind: runtime.KindText, V: ts.Add(invoice)})
+	text := func(v runtime.Value) string {
+		got, _ := ts.Get(v.V.(runtime.TextHandle))
+		return got
+	}
+
+	res, err := reg.Dispatch(s, "INVOICE_FIELD", []ast.KwArg{
+		{Keyword: "SOURCE", Value: &ast.IdentExpr{Name: "doc"}},
+		{Keyword: "LABEL", Value: &ast.StringExpr{Value: "date"}},
+	})
+	if err != nil || text(res) != "2026-03-04" {
+		t.Errorf("INVOICE_FIELD: expected 2026-03-04, got %v %v", res, err)
+	}
+
+	res, err = reg.Dispatch(s, "INVOICE_HEADER", []ast.KwArg{{Keyword: "SOURCE", Value: &ast.IdentExpr{Name: "doc"}}})
+	if err != nil {
+		t.Fatalf("INVOICE_HEADER failed: %v", err)
+	}
+	fields := res.V.(map[string]interface{})
+	if got, _ := ts.Get(fields["number"].(runtime.TextHandle)); got != "INV-2041" {
+		t.Errorf("expected number INV-2041, got %q", got)
+	}
+
+	res, err = reg.Dispatch(s, "INVOICE_TOTAL", []ast.KwArg{{Keyword: "SOURCE", Value: &ast.IdentExpr{Name: "doc"}}})
+	if err != nil || res.Kind != runtime.KindInt || res.V != 1234 {
+		t.Errorf("INVOICE_TOTAL: expected 1234, got %v %v", res, err)
+	}
+
+	// A template that does not match gives empty text.
+	res, err = reg.Dispatch(s, "INVOICE_FIELD", []ast.KwArg{
+		{Keyword: "SOURCE", Value: &ast.IdentExpr{Name: "doc"}},
+		{Keyword: "LABEL", Value: &ast.StringExpr{Value: "Due (days)"}},
+	})
+	if err != nil || text(res) != "" {
+		t.Errorf("expected empty text, got %v %v", res, err)
+	}
+}
+
+func TestNewOpModule_Invalid(t *testing.T) {
+	source := []Param{{Kw: "SOURCE", Type: "TEXT"}}
+	tests := map[string]struct {
+		op      OpDef
+		structs []StructDef
+		want    string
+	}{
+		"no implementation": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source},
+			nil, "needs either steps or a regex template",
+		},
+		"unknown result type": {
+			OpDef{Name: "X", ResultType: "Invoice", Signature: source, Regex: &RegexTemplate{Pattern: "a"}},
+			nil, `unknown result type "Invoice"`,
+		},
+		"unknown step op": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source, Steps: []Step{{Op: "NOPE", Into: "a"}}},
+			nil, "step 1: unknown op NOPE",
+		},
+		"undefined reference": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source, Steps: []Step{
+				{Op: "TRIM_TEXT", Args: map[string]interface{}{"SOURCE": "$TEXT"}, Into: "a"},
+			}},
+			nil, "refers to undefined $TEXT",
+		},
+		"undeclared capability": {
+			OpDef{Name: "X", ResultType: "JSON", Signature: source, Steps: []Step{
+				{Op: "SUBCALL", Args: map[string]interface{}{"SOURCE": "$SOURCE", "TASK": "t", "DEPTH_COST": 1}, Into: "a"},
+			}},
+			nil, `SUBCALL needs capability "llm"`,
+		},
+		"wrong result type": {
+			OpDef{Name: "X", ResultType: "INT", Signature: source, Steps: []Step{
+				{Op: "TRIM_TEXT", Args: map[string]interface{}{"SOURCE": "$SOURCE"}, Into: "a"},
+			}},
+			nil, "result a is TEXT, expected INT",
+		},
+		"bad keyword": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source, Steps: []Step{
+				{Op: "TRIM_TEXT", Args: map[string]interface{}{"TEXT": "$SOURCE"}, Into: "a"},
+			}},
+			nil, "TRIM_TEXT: unknown keyword TEXT",
+		},
+		"bad pattern": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source, Regex: &RegexTemplate{Pattern: "("}},
+			nil, "invalid pattern",
+		},
+		"undefined placeholder": {
+			OpDef{Name: "X", ResultType: "TEXT", Signature: source, Regex: &RegexTemplate{Pattern: "{{LABEL}}"}},
+			nil, "pattern refers to undefined param LABEL",
+		},
+		"missing group": {
+			OpDef{Name: "X", ResultType: "Pair", Signature: source, Regex: &RegexTemplate{Pattern: "(?P<a>x)"}},
+			[]StructDef{{Name: "Pair", Fields: map[string]string{"a": "TEXT", "b": "TEXT"}}},
+			"struct Pair: pattern has no group named b",
+		},
+	}
+	for name, tc := range tests {
+		m := Manifest{Name: "bad", Provides: Provides{Ops: []OpDef{tc.op}, Structs: tc.structs}}
+		_, err := NewOpModule(m, newRegistry(t))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestRegisterDir_Errors(t *testing.T) {
+	if _, err := RegisterDir(newRegistry(t), "does-not-exist"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "shadow.json"), []byte(`{"name": "shadow", "provides": {"ops": [
+		{"name": "STATS", "result_type": "TEXT", "signature": [{"kw": "SOURCE", "type": "TEXT"}], "regex": {"pattern": "x", "group": 0}}
+	]}}`), 0o644)
+	_, err := RegisterDir(newRegistry(t), dir)
+	if err == nil || !strings.Contains(err.Error(), "op STATS is already registered by module core") {
+		t.Errorf("expected a conflict with the core module, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "shadow.json"), []byte(`{"provides": {}}`), 0o644)
+	if _, err := RegisterDir(newRegistry(t), dir); err == nil || !strings.Contains(err.Error(), "manifest has no name") {
+		t.Errorf("expected a missing name error, got %v", err)
+	}
+}
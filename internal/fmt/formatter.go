@@ -5,10 +5,27 @@ import (
 	"strings"
 
 	"github.com/agenthands/envllm/internal/ast"
+	"github.com/agenthands/envllm/internal/ops"
 )
 
-// Format produces a canonical string representation of an AST.
+// Options controls how ops are formatted.
+type Options struct {
+	// Table, if set, is used to write op clauses in signature order.
+	Table *ops.Table
+	// FillDefaults writes left-out params that have a default explicitly.
+	// It needs a Table.
+	FillDefaults bool
+}
+
+// Format produces a canonical string representation of an AST. Op clauses
+// are written as they appear in the source.
 func Format(prog *ast.Program) string {
+	return FormatWith(prog, Options{})
+}
+
+// FormatWith is like Format but reorders op clauses and fills in defaults
+// according to opts.
+func FormatWith(prog *ast.Program, opts Options) string {
 	var sb strings.Builder
 
 	if prog.Version != "" {
@@ -35,13 +52,13 @@ func Format(prog *ast.Program) string {
 		if prog.Version != "" || prog.Dialect != "" || len(prog.Extensions) > 0 {
 			sb.WriteString("\n")
 		}
-		formatTask(&sb, prog.Task)
+		formatTask(&sb, prog.Task, opts)
 	}
 
 	return sb.String()
 }
 
-func formatTask(sb *strings.Builder, t *ast.Task) {
+func formatTask(sb *strings.Builder, t *ast.Task, opts Options) {
 	sb.WriteString("TASK ")
 	sb.WriteString(t.Name)
 	sb.WriteString(":\n")
@@ -54,14 +71,14 @@ func formatTask(sb *strings.Builder, t *ast.Task) {
 		sb.WriteString("\n")
 	}
 
-	formatBody(sb, t.Body, 2)
+	formatBody(sb, t.Body, 2, opts)
 
 	sb.WriteString("  OUTPUT ")
 	sb.WriteString(t.Output)
 	sb.WriteString("\n")
 }
 
-func formatBody(sb *strings.Builder, body []ast.BodyItem, indent int) {
+func formatBody(sb *strings.Builder, body []ast.BodyItem, indent int, opts Options) {
 	indentStr := strings.Repeat(" ", indent)
 	for _, item := range body {
 		switch it := item.(type) {
@@ -78,7 +95,7 @@ func formatBody(sb *strings.Builder, body []ast.BodyItem, indent int) {
 			for _, stmt := range it.Stmts {
 				sb.WriteString(indentStr)
 				sb.WriteString("  ")
-				formatStmt(sb, stmt, indent+2)
+				formatStmt(sb, stmt, indent+2, opts)
 				sb.WriteString("\n")
 			}
 		case *ast.IfStmt:
@@ -86,11 +103,11 @@ func formatBody(sb *strings.Builder, body []ast.BodyItem, indent int) {
 			sb.WriteString("IF ")
 			formatExpr(sb, it.Cond)
 			sb.WriteString(":\n")
-			formatBody(sb, it.ThenBody, indent)
+			formatBody(sb, it.ThenBody, indent, opts)
 			if it.ElseBody != nil {
 				sb.WriteString(indentStr)
 				sb.WriteString("ELSE:\n")
-				formatBody(sb, it.ElseBody, indent)
+				formatBody(sb, it.ElseBody, indent, opts)
 			}
 			sb.WriteString(indentStr)
 			sb.WriteString("END\n")
@@ -98,11 +115,11 @@ func formatBody(sb *strings.Builder, body []ast.BodyItem, indent int) {
 	}
 }
 
-func formatStmt(sb *strings.Builder, stmt ast.Stmt, indent int) {
+func formatStmt(sb *strings.Builder, stmt ast.Stmt, indent int, opts Options) {
 	switch s := stmt.(type) {
 	case *ast.OpStmt:
 		sb.WriteString(s.OpName)
-		for _, arg := range s.Args {
+		for _, arg := range canonicalArgs(s, opts) {
 			sb.WriteString(" ")
 			sb.WriteString(arg.Keyword)
 			sb.WriteString(" ")
@@ -117,6 +134,10 @@ func formatStmt(sb *strings.Builder, stmt ast.Stmt, indent int) {
 	case *ast.SetFinalStmt:
 		sb.WriteString("SET_FINAL SOURCE ")
 		formatExpr(sb, s.Source)
+		if s.Citing != nil {
+			sb.WriteString(" CITING ")
+			formatExpr(sb, s.Citing)
+		}
 	case *ast.AssertStmt:
 		sb.WriteString("ASSERT COND ")
 		formatExpr(sb, s.Cond)
@@ -136,12 +157,44 @@ func formatStmt(sb *strings.Builder, stmt ast.Stmt, indent int) {
 		indentStr := strings.Repeat(" ", indent+2)
 		for _, bs := range s.Body {
 			sb.WriteString(indentStr)
-			formatStmt(sb, bs, indent+2)
+			formatStmt(sb, bs, indent+2, opts)
 			sb.WriteString("\n")
 		}
 	}
 }
 
+// canonicalArgs returns the clauses of s in signature order, with defaults
+// filled in if opts asks for it. Statements that do not bind to their op are
+// left as written.
+func canonicalArgs(s *ast.OpStmt, opts Options) []ast.KwArg {
+	if opts.Table == nil {
+		return s.Args
+	}
+	op, ok := opts.Table.Ops[s.OpName]
+	if !ok {
+		return s.Args
+	}
+	keywords := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		keywords[i] = arg.Keyword
+	}
+	idx, err := op.Bind(keywords)
+	if err != nil {
+		return s.Args
+	}
+
+	var args []ast.KwArg
+	for i, p := range op.Signature {
+		switch {
+		case idx[i] >= 0:
+			args = append(args, s.Args[idx[i]])
+		case opts.FillDefaults && p.Default != "":
+			args = append(args, ast.KwArg{Keyword: p.Kw, Value: p.DefaultExpr()})
+		}
+	}
+	return args
+}
+
 func formatExpr(sb *strings.Builder, expr ast.Expr) {
 	switch e := expr.(type) {
 	case *ast.IdentExpr:
@@ -158,6 +211,15 @@ func formatExpr(sb *strings.Builder, expr ast.Expr) {
 		}
 	case *ast.NullExpr:
 		sb.WriteString("null")
+	case *ast.ListExpr:
+		sb.WriteString("[")
+		for i, item := range e.Items {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			formatExpr(sb, item)
+		}
+		sb.WriteString("]")
 	}
 }
 
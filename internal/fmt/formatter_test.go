@@ -1,9 +1,11 @@
 package fmt
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/agenthands/envllm/internal/lex"
+	"github.com/agenthands/envllm/internal/ops"
 	"github.com/agenthands/envllm/internal/parse"
 )
 
@@ -65,3 +67,73 @@ TASK smoke_test:
 		t.Errorf("Format not idempotent")
 	}
 }
+
+func TestFormatSetFinalCiting(t *testing.T) {
+	input := `RLMDSL 0.2
+
+TASK cite:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_REGEX SOURCE PROMPT PATTERN "[0-9]+" MODE FIRST INTO m: STRUCT
+    GET_FIELD SOURCE m FIELD "span" INTO sp: SPAN
+    CITE SOURCE sp INTO c: STRUCT
+    SET_FINAL SOURCE sp CITING [c]
+  OUTPUT c
+`
+	l := lex.NewLexer("cite.rlm", input)
+	p := parse.NewParser(l, parse.ModeStrict)
+	prog, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	formatted := Format(prog)
+	if !strings.Contains(formatted, "SET_FINAL SOURCE sp CITING [c]") {
+		t.Errorf("CITING clause lost in formatting:\n%s", formatted)
+	}
+	prog2, err := parse.NewParser(lex.NewLexer("formatted.rlm", formatted), parse.ModeStrict).Parse()
+	if err != nil {
+		t.Fatalf("Parse of formatted failed: %v", err)
+	}
+	if Format(prog2) != formatted {
+		t.Errorf("Format not idempotent")
+	}
+}
+
+func TestFormatWithTable(t *testing.T) {
+	input := `RLMDSL 0.2
+
+TASK find:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT NEEDLE "x" SOURCE PROMPT INTO pos: OFFSET
+  OUTPUT pos
+`
+	prog, err := parse.NewParser(lex.NewLexer("find.rlm", input), parse.ModeStrict).Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tbl, _ := ops.LoadTable("../../assets/ops.json")
+
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, `FIND_TEXT NEEDLE "x" SOURCE PROMPT INTO pos: OFFSET`},
+		{Options{Table: tbl}, `FIND_TEXT SOURCE PROMPT NEEDLE "x" INTO pos: OFFSET`},
+		{Options{Table: tbl, FillDefaults: true}, `FIND_TEXT SOURCE PROMPT NEEDLE "x" MODE FIRST IGNORE_CASE false INTO pos: OFFSET`},
+	}
+	for _, tc := range tests {
+		formatted := FormatWith(prog, tc.opts)
+		if !strings.Contains(formatted, tc.want) {
+			t.Errorf("expected %q in:\n%s", tc.want, formatted)
+		}
+		prog2, err := parse.NewParser(lex.NewLexer("formatted.rlm", formatted), parse.ModeStrict).Parse()
+		if err != nil {
+			t.Fatalf("Parse of formatted failed: %v", err)
+		}
+		if FormatWith(prog2, tc.opts) != formatted {
+			t.Errorf("FormatWith(%+v) not idempotent", tc.opts)
+		}
+	}
+}
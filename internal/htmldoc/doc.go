@@ -0,0 +1,179 @@
+// Package htmldoc parses HTML into a lightweight element tree that remembers
+// where every node came from in the source, extracts readable text with an
+// offset map, and evaluates a subset of CSS selectors.
+//
+// The tree is built from golang.org/x/net/html's tokenizer rather than its
+// parser because the parser discards source positions. Tree construction is
+// forgiving in the same spirit as browsers, but simplified: unmatched end tags
+// are ignored, unclosed elements end where their parent ends, and the common
+// implied end tags (p, li, td/th, tr, dt/dd, option) are handled.
+package htmldoc
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Node is an element or text node.
+type Node struct {
+	Tag      string // lower-case tag name; "" for text nodes and the document root
+	Attrs    []html.Attribute
+	Parent   *Node
+	Children []*Node
+
+	// Start and End delimit the node in the source, tags included.
+	// InnerStart and InnerEnd delimit its content.
+	Start, End           int
+	InnerStart, InnerEnd int
+
+	// Raw holds the undecoded source of a text node.
+	Raw string
+}
+
+// IsText reports whether n is a text node.
+func (n *Node) IsText() bool { return n.Tag == "" && n.Parent != nil }
+
+// Attr returns the value of the named attribute.
+func (n *Node) Attr(key string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// closesP lists start tags that implicitly end an open <p>.
+var closesP = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true, "dl": true,
+	"fieldset": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// impliedEnd maps a start tag to the open tags it closes and the tags that
+// bound the search (an <li> closes an open <li> but not past its <ul>).
+var impliedEnd = map[string]struct{ closes, stops []string }{
+	"li":     {[]string{"li"}, []string{"ul", "ol"}},
+	"td":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"tr":     {[]string{"tr"}, []string{"table", "tbody", "thead", "tfoot"}},
+	"dt":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"dd":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"option": {[]string{"option"}, []string{"select", "datalist"}},
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse builds the element tree for src. It never fails; malformed markup is
+// recovered from as described in the package comment.
+func Parse(src string) *Node {
+	root := &Node{End: len(src), InnerEnd: len(src)}
+	stack := []*Node{root}
+
+	// closeTo pops the stack down to (and including) index i; the popped
+	// elements end at pos.
+	closeTo := func(i, pos int) {
+		for j := len(stack) - 1; j >= i; j-- {
+			stack[j].End, stack[j].InnerEnd = pos, pos
+		}
+		stack = stack[:i]
+	}
+	find := func(closes, stops []string) int {
+		for i := len(stack) - 1; i > 0; i-- {
+			if contains(closes, stack[i].Tag) {
+				return i
+			}
+			if contains(stops, stack[i].Tag) {
+				break
+			}
+		}
+		return -1
+	}
+
+	z := html.NewTokenizer(strings.NewReader(src))
+	pos := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken && z.Err() == io.EOF {
+			break
+		}
+		start := pos
+		pos += len(z.Raw())
+		top := stack[len(stack)-1]
+
+		switch tt {
+		case html.TextToken:
+			raw := src[start:pos]
+			top.Children = append(top.Children, &Node{Parent: top, Start: start, End: pos, InnerStart: start, InnerEnd: pos, Raw: raw})
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			var attrs []html.Attribute
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				attrs = append(attrs, html.Attribute{Key: string(k), Val: string(v)})
+			}
+
+			if rule, ok := impliedEnd[tag]; ok {
+				if i := find(rule.closes, rule.stops); i > 0 {
+					closeTo(i, start)
+				}
+			}
+			if closesP[tag] {
+				if i := find([]string{"p"}, []string{"button"}); i > 0 {
+					closeTo(i, start)
+				}
+			}
+
+			top = stack[len(stack)-1]
+			n := &Node{Tag: tag, Attrs: attrs, Parent: top, Start: start, InnerStart: pos, End: pos, InnerEnd: pos}
+			top.Children = append(top.Children, n)
+			if tt == html.StartTagToken && !voidElements[tag] {
+				stack = append(stack, n)
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].Tag == tag {
+					n := stack[i]
+					closeTo(i+1, start)
+					n.InnerEnd, n.End = start, pos
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+	}
+	closeTo(1, len(src))
+	return root
+}
+
+// Walk calls fn for every element below n in document order.
+func Walk(n *Node, fn func(*Node)) {
+	for _, c := range n.Children {
+		if c.IsText() {
+			continue
+		}
+		fn(c)
+		Walk(c, fn)
+	}
+}
@@ -0,0 +1,68 @@
+package htmldoc
+
+import "testing"
+
+const page = "<html><head><title>T</title><style>p{}</style></head><body>" +
+	"<div id=main class='a b'><p>Hello &amp; <b>world</b><p>Second  para<br>line</div>" +
+	"<ul><li><a href='/x'>X</a><li>Y</ul><pre>  a\n  b</pre></body></html>"
+
+func TestText(t *testing.T) {
+	text, segs := Text(Parse(page))
+	want := "Hello & world\nSecond para\nline\nX\nY\n  a\n  b"
+	if text != want {
+		t.Fatalf("Text = %q, want %q", text, want)
+	}
+
+	for _, tc := range []struct {
+		start, end int
+		src        string
+	}{
+		{0, 5, "Hello"},
+		{6, 13, "&amp; <b>world"},
+		{14, 25, "Second  para"},
+		{8, 11, "wor"},
+	} {
+		s, e, ok := SourceSpan(segs, tc.start, tc.end)
+		if !ok || page[s:e] != tc.src {
+			t.Errorf("SourceSpan(%d, %d) = %q, want %q", tc.start, tc.end, page[s:e], tc.src)
+		}
+	}
+	if _, _, ok := SourceSpan(segs, len(text), len(text)+1); ok {
+		t.Error("expected no source span past the end of the text")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	root := Parse(page)
+	for _, tc := range []struct {
+		sel  string
+		want []string
+	}{
+		{"div > p", []string{"<p>Hello &amp; <b>world</b>", "<p>Second  para<br>line"}},
+		{"#main b", []string{"<b>world</b>"}},
+		{"ul a[href^='/']", []string{"<a href='/x'>X</a>"}},
+		{"li", []string{"<li><a href='/x'>X</a>", "<li>Y"}},
+		{"pre, div.a.b", []string{page[59:140], "<pre>  a\n  b</pre>"}},
+		{"body > p", nil},
+	} {
+		sel, err := Compile(tc.sel)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tc.sel, err)
+		}
+		got := Select(root, sel)
+		if len(got) != len(tc.want) {
+			t.Fatalf("Select(%q) matched %d elements, want %d", tc.sel, len(got), len(tc.want))
+		}
+		for i, n := range got {
+			if page[n.Start:n.End] != tc.want[i] {
+				t.Errorf("Select(%q)[%d] = %q, want %q", tc.sel, i, page[n.Start:n.End], tc.want[i])
+			}
+		}
+	}
+
+	for _, bad := range []string{"a:hover", "a + b", "div >", "", "[=x]"} {
+		if _, err := Compile(bad); err == nil {
+			t.Errorf("Compile(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package htmldoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector is a compiled comma-separated group of CSS selectors.
+//
+// Supported: type selectors (div), the universal selector (*), #id, .class,
+// attribute selectors [attr], [attr=v], [attr~=v], [attr^=v], [attr$=v] and
+// [attr*=v] with optional quotes, and the descendant (space) and child (>)
+// combinators. Pseudo-classes and the sibling combinators are rejected.
+type Selector []complexSelector
+
+type complexSelector struct {
+	parts []compound
+	combs []byte // combs[i] joins parts[i] and parts[i+1]: ' ' or '>'
+}
+
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	key, op, val string
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Compile parses a selector group.
+func Compile(sel string) (Selector, error) {
+	var out Selector
+	for _, group := range splitGroups(sel) {
+		cs, err := compileComplex(strings.TrimSpace(group))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", sel, err)
+		}
+		out = append(out, cs)
+	}
+	return out, nil
+}
+
+// splitGroups splits on commas outside brackets and quotes.
+func splitGroups(sel string) []string {
+	var groups []string
+	depth, quote, last := 0, byte(0), 0
+	for i := 0; i < len(sel); i++ {
+		c := sel[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			groups = append(groups, sel[last:i])
+			last = i + 1
+		}
+	}
+	return append(groups, sel[last:])
+}
+
+func compileComplex(sel string) (complexSelector, error) {
+	var cs complexSelector
+	if sel == "" {
+		return cs, fmt.Errorf("empty selector")
+	}
+
+	i := 0
+	comb := byte(0)
+	for i < len(sel) {
+		switch c := sel[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			if comb == 0 && len(cs.parts) > 0 {
+				comb = ' '
+			}
+			i++
+			continue
+		case c == '>':
+			if len(cs.parts) == 0 {
+				return cs, fmt.Errorf("combinator '>' without a left-hand side")
+			}
+			comb = '>'
+			i++
+			continue
+		case c == '+' || c == '~':
+			return cs, fmt.Errorf("sibling combinator %q is not supported", c)
+		}
+
+		cp, n, err := compileCompound(sel[i:])
+		if err != nil {
+			return cs, err
+		}
+		if len(cs.parts) > 0 {
+			cs.combs = append(cs.combs, comb)
+		}
+		cs.parts = append(cs.parts, cp)
+		comb = 0
+		i += n
+	}
+	if comb == '>' {
+		return cs, fmt.Errorf("combinator '>' without a right-hand side")
+	}
+	return cs, nil
+}
+
+// compileCompound reads one compound selector (e.g. a.btn[href]) and returns
+// the number of bytes consumed.
+func compileCompound(s string) (compound, int, error) {
+	var cp compound
+	name := func(i int) (string, int) {
+		j := i
+		for j < len(s) && isNameChar(s[j]) {
+			j++
+		}
+		return s[i:j], j
+	}
+
+	i := 0
+	if i < len(s) && s[i] == '*' {
+		i++
+	} else if i < len(s) && isNameChar(s[i]) {
+		cp.tag, i = name(i)
+		cp.tag = strings.ToLower(cp.tag)
+	}
+
+	for i < len(s) {
+		switch s[i] {
+		case '#':
+			cp.id, i = name(i + 1)
+			if cp.id == "" {
+				return cp, i, fmt.Errorf("empty id after '#'")
+			}
+		case '.':
+			var cls string
+			cls, i = name(i + 1)
+			if cls == "" {
+				return cp, i, fmt.Errorf("empty class after '.'")
+			}
+			cp.classes = append(cp.classes, cls)
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return cp, i, fmt.Errorf("unterminated attribute selector")
+			}
+			a, err := compileAttr(s[i+1 : i+end])
+			if err != nil {
+				return cp, i, err
+			}
+			cp.attrs = append(cp.attrs, a)
+			i += end + 1
+		case ':':
+			return cp, i, fmt.Errorf("pseudo-classes are not supported")
+		default:
+			if i == 0 {
+				return cp, i, fmt.Errorf("unexpected character %q", s[i])
+			}
+			return cp, i, nil
+		}
+	}
+	return cp, i, nil
+}
+
+func compileAttr(body string) (attrSelector, error) {
+	for _, op := range []string{"~=", "^=", "$=", "*=", "="} {
+		if k, v, ok := strings.Cut(body, op); ok {
+			k = strings.TrimSpace(k)
+			v = strings.TrimSpace(v)
+			if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+				v = v[1 : len(v)-1]
+			}
+			if k == "" {
+				return attrSelector{}, fmt.Errorf("attribute selector without a name")
+			}
+			return attrSelector{key: strings.ToLower(k), op: op, val: v}, nil
+		}
+	}
+	k := strings.TrimSpace(body)
+	if k == "" {
+		return attrSelector{}, fmt.Errorf("empty attribute selector")
+	}
+	return attrSelector{key: strings.ToLower(k)}, nil
+}
+
+func (cp compound) match(n *Node) bool {
+	if cp.tag != "" && cp.tag != n.Tag {
+		return false
+	}
+	if cp.id != "" {
+		if id, _ := n.Attr("id"); id != cp.id {
+			return false
+		}
+	}
+	if len(cp.classes) > 0 {
+		cls, _ := n.Attr("class")
+		have := strings.Fields(cls)
+		for _, c := range cp.classes {
+			if !contains(have, c) {
+				return false
+			}
+		}
+	}
+	for _, a := range cp.attrs {
+		v, ok := n.Attr(a.key)
+		if !ok {
+			return false
+		}
+		switch a.op {
+		case "=":
+			ok = v == a.val
+		case "~=":
+			ok = contains(strings.Fields(v), a.val)
+		case "^=":
+			ok = a.val != "" && strings.HasPrefix(v, a.val)
+		case "$=":
+			ok = a.val != "" && strings.HasSuffix(v, a.val)
+		case "*=":
+			ok = a.val != "" && strings.Contains(v, a.val)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAt reports whether n matches parts[:i+1], with n matching parts[i].
+func (cs complexSelector) matchAt(n *Node, i int) bool {
+	if n == nil || n.Tag == "" || !cs.parts[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if cs.combs[i-1] == '>' {
+		return cs.matchAt(n.Parent, i-1)
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if cs.matchAt(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the element n matches any selector in the group.
+func (sel Selector) Match(n *Node) bool {
+	for _, cs := range sel {
+		if cs.matchAt(n, len(cs.parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the elements below root that match sel, in document order.
+func Select(root *Node, sel Selector) []*Node {
+	var out []*Node
+	Walk(root, func(n *Node) {
+		if sel.Match(n) {
+			out = append(out, n)
+		}
+	})
+	return out
+}
@@ -0,0 +1,155 @@
+package htmldoc
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Segment maps a range of extracted text to the source range it came from.
+// When Exact is set the two ranges have the same length and correspond byte
+// for byte; otherwise (entities, collapsed whitespace) only the range
+// boundaries correspond.
+type Segment struct {
+	TextStart, TextEnd     int
+	SourceStart, SourceEnd int
+	Exact                  bool
+}
+
+// skipped elements contribute no readable text.
+var skipped = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+}
+
+// blocks start and end on their own line.
+var blocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "caption": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "summary": true, "table": true, "tbody": true, "tfoot": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+type extractor struct {
+	sb      strings.Builder
+	segs    []Segment
+	pending string // separator owed before the next word
+}
+
+func (e *extractor) sep(s string) {
+	if e.sb.Len() == 0 {
+		return
+	}
+	if s == "\n" || e.pending == "" {
+		e.pending = s
+	}
+}
+
+func (e *extractor) emit(word string, srcStart, srcEnd int) {
+	if e.sb.Len() > 0 {
+		e.sb.WriteString(e.pending)
+	}
+	e.pending = ""
+	seg := Segment{TextStart: e.sb.Len(), SourceStart: srcStart, SourceEnd: srcEnd}
+	e.sb.WriteString(word)
+	seg.TextEnd = e.sb.Len()
+	seg.Exact = seg.TextEnd-seg.TextStart == srcEnd-srcStart
+
+	// Merge with the previous segment when offsets stay in lockstep.
+	if n := len(e.segs); n > 0 {
+		last := &e.segs[n-1]
+		if last.Exact && seg.Exact && seg.TextStart-last.TextEnd == seg.SourceStart-last.SourceEnd {
+			last.TextEnd, last.SourceEnd = seg.TextEnd, seg.SourceEnd
+			return
+		}
+	}
+	e.segs = append(e.segs, seg)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func (e *extractor) text(n *Node, pre bool) {
+	raw := n.Raw
+	if pre {
+		if raw != "" {
+			e.emit(html.UnescapeString(raw), n.Start, n.End)
+		}
+		return
+	}
+	for i := 0; i < len(raw); {
+		if isSpace(raw[i]) {
+			e.sep(" ")
+			i++
+			continue
+		}
+		j := i
+		for j < len(raw) && !isSpace(raw[j]) {
+			j++
+		}
+		e.emit(html.UnescapeString(raw[i:j]), n.Start+i, n.Start+j)
+		i = j
+	}
+}
+
+func (e *extractor) walk(n *Node, pre bool) {
+	for _, c := range n.Children {
+		switch {
+		case c.IsText():
+			e.text(c, pre)
+		case skipped[c.Tag]:
+		case c.Tag == "br":
+			e.sep("\n")
+		case c.Tag == "td" || c.Tag == "th":
+			e.sep(" ")
+			e.walk(c, pre)
+			e.sep(" ")
+		case blocks[c.Tag]:
+			e.sep("\n")
+			e.walk(c, pre || c.Tag == "pre")
+			e.sep("\n")
+		default:
+			e.walk(c, pre)
+		}
+	}
+}
+
+// Text returns the readable text of n: script, style and head content is
+// dropped, runs of whitespace collapse to one space except inside <pre>,
+// block elements and <br> start new lines, and entities are decoded. The
+// segments map every piece of the result back to the source.
+func Text(n *Node) (string, []Segment) {
+	var e extractor
+	e.walk(n, n.Tag == "pre")
+	return e.sb.String(), e.segs
+}
+
+// SourceSpan maps the range [start, end) of text produced by Text back to
+// the source. ok is false when the range covers no extracted text.
+func SourceSpan(segs []Segment, start, end int) (srcStart, srcEnd int, ok bool) {
+	first, last := -1, -1
+	for i, s := range segs {
+		if s.TextEnd > start && first < 0 {
+			first = i
+		}
+		if s.TextStart < end {
+			last = i
+		}
+	}
+	if first < 0 || last < first {
+		return 0, 0, false
+	}
+
+	f, l := segs[first], segs[last]
+	srcStart, srcEnd = f.SourceStart, l.SourceEnd
+	if f.Exact && start > f.TextStart {
+		srcStart += start - f.TextStart
+	}
+	if l.Exact && end < l.TextEnd {
+		srcEnd = l.SourceStart + (end - l.TextStart)
+	}
+	return srcStart, srcEnd, true
+}
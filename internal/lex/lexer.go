@@ -70,6 +70,15 @@ func (l *Lexer) NextToken() Token {
 	case ':':
 		tok.Type = TypeColon
 		tok.Value = ":"
+	case '[':
+		tok.Type = TypeLBracket
+		tok.Value = "["
+	case ']':
+		tok.Type = TypeRBracket
+		tok.Value = "]"
+	case ',':
+		tok.Type = TypeComma
+		tok.Value = ","
 	case '\n':
 		tok.Type = TypeNewline
 		tok.Value = "\n"
@@ -42,6 +42,9 @@ const (
 	TypeColon
 	TypeNewline
 	TypeEq
+	TypeLBracket
+	TypeRBracket
+	TypeComma
 )
 
 // Loc represents a location in the source code.
@@ -8,6 +8,7 @@ import (
 	"github.com/agenthands/envllm/internal/ast"
 	"github.com/agenthands/envllm/internal/ops"
 	"github.com/agenthands/envllm/internal/rewrite"
+	"github.com/agenthands/envllm/internal/runtime"
 	"github.com/agenthands/envllm/internal/trace"
 )
 
@@ -16,6 +17,7 @@ type Linter struct {
 	sink     trace.Sink
 	registry *rewrite.Registry
 	mode     Mode
+	symbols  map[string]string
 }
 
 type Mode int
@@ -44,6 +46,14 @@ func (l *Linter) WithRegistry(registry *rewrite.Registry) *Linter {
 	return l
 }
 
+// WithSymbols declares variables, by name and type, that programs may use
+// without an INPUT, such as those defined by an earlier program in the same
+// session.
+func (l *Linter) WithSymbols(symbols map[string]string) *Linter {
+	l.symbols = symbols
+	return l
+}
+
 func (l *Linter) emitTrace(step trace.TraceStep) {
 	if l.sink != nil {
 		if step.Timestamp.IsZero() {
@@ -59,6 +69,9 @@ func (l *Linter) emitTrace(step trace.TraceStep) {
 func (l *Linter) Lint(prog *ast.Program) []Error {
 	var errs []Error
 	symbols := make(map[string]string) // name -> type
+	for name, typ := range l.symbols {
+		symbols[name] = typ
+	}
 	requiredCaps := make(map[string]bool)
 
 	if prog.Task == nil {
@@ -146,6 +159,10 @@ func (l *Linter) lintStmts(stmts []ast.Stmt, symbols map[string]string, required
 				} else {
 					if outType != "" {
 						symbols[s.Into] = outType
+					} else if s.IntoType != "" {
+						// Ops whose result kind depends on their arguments
+						// take the declared type.
+						symbols[s.Into] = s.IntoType
 					} else {
 						symbols[s.Into] = "UNKNOWN"
 					}
@@ -153,6 +170,9 @@ func (l *Linter) lintStmts(stmts []ast.Stmt, symbols map[string]string, required
 			}
 		case *ast.SetFinalStmt:
 			errs = append(errs, l.lintExpr(s.Source, "", symbols)...)
+			if s.Citing != nil {
+				errs = append(errs, l.lintExpr(s.Citing, "", symbols)...)
+			}
 		case *ast.PrintStmt:
 			errs = append(errs, l.lintExpr(s.Source, "", symbols)...)
 		case *ast.AssertStmt:
@@ -200,17 +220,43 @@ func (l *Linter) lintOpStmt(s *ast.OpStmt, symbols map[string]string, caps map[s
 		}
 	}
 
-	// 1. Enforce clause order and type check arguments
-	if len(s.Args) != len(opDef.Signature) {
+	// Ops run over a DOCS collection produce ROWS instead
+	resultType := opDef.ResultType
+
+	// 1. Bind and type check arguments; clause order is enforced in STRICT mode only
+	keywords := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		keywords[i] = arg.Keyword
+	}
+	if err := opDef.CheckArgCount(len(s.Args)); err != nil {
 		errs = append(errs, Error{
 			Code:    "LINT_ARG_COUNT",
-			Message: fmt.Sprintf("%s: expected %d arguments, got %d", s.OpName, len(opDef.Signature), len(s.Args)),
+			Message: err.Error(),
 			Loc:     s.Loc,
 		})
+	} else if _, err := opDef.Bind(keywords); err != nil {
+		template := l.getCanonicalTemplate(opDef)
+		errs = append(errs, Error{
+			Code:             "LINT_BAD_KEYWORD",
+			Message:          err.Error(),
+			Loc:              s.Loc,
+			Hint:             fmt.Sprintf("Use the keywords of the canonical form: %s", template),
+			ExpectedTemplate: template,
+		})
 	} else {
-		for i, arg := range s.Args {
-			param := opDef.Signature[i]
-			// ... (existing order check)
+		if l.mode == ModeStrict && !opDef.InOrder(keywords) {
+			template := l.getCanonicalTemplate(opDef)
+			errs = append(errs, Error{
+				Code:             "LINT_CLAUSE_ORDER",
+				Message:          fmt.Sprintf("%s: clauses %s are not in canonical order", s.OpName, strings.Join(keywords, ", ")),
+				Loc:              s.Loc,
+				Hint:             fmt.Sprintf("Reorder clauses to match canonical form: %s", template),
+				ExpectedTemplate: template,
+			})
+		}
+
+		for _, arg := range s.Args {
+			param, _ := opDef.Param(arg.Keyword)
 
 			// JSON Usage Check
 			if s.OpName == "JSON_GET" && param.Kw == "SOURCE" {
@@ -226,17 +272,6 @@ func (l *Linter) lintOpStmt(s *ast.OpStmt, symbols map[string]string, caps map[s
 				}
 			}
 
-			if arg.Keyword != param.Kw {
-				template := l.getCanonicalTemplate(opDef)
-				errs = append(errs, Error{
-					Code:             "LINT_CLAUSE_ORDER",
-					Message:          fmt.Sprintf("%s: clause %d must be %s, got %s", s.OpName, i+1, param.Kw, arg.Keyword),
-					Loc:              s.Loc,
-					Hint:             fmt.Sprintf("Reorder clauses to match canonical form: %s", template),
-					ExpectedTemplate: template,
-				})
-			}
-			
 			// Enum check
 			isEnumVal := false
 			if len(param.Enum) > 0 {
@@ -251,7 +286,12 @@ func (l *Linter) lintOpStmt(s *ast.OpStmt, symbols map[string]string, caps map[s
 			}
 
 			if !isEnumVal {
-				errs = append(errs, l.lintExpr(arg.Value, string(param.Type), symbols)...)
+				expected := string(param.Type)
+				if id, ok := arg.Value.(*ast.IdentExpr); ok && symbols[id.Name] == "DOCS" && opDef.TakesDocs(param.Kw) {
+					expected = "DOCS"
+					resultType = opDef.ResultTypeFor(runtime.KindDocs)
+				}
+				errs = append(errs, l.lintExpr(arg.Value, expected, symbols)...)
 			}
 
 			// EPIC: Forbid literal offset arithmetic in STRICT mode
@@ -278,15 +318,15 @@ func (l *Linter) lintOpStmt(s *ast.OpStmt, symbols map[string]string, caps map[s
 	}
 
 	// 3. Check INTO type annotation
-	if s.IntoType != "" && opDef.ResultType != "" && s.IntoType != string(opDef.ResultType) {
+	if s.IntoType != "" && resultType != "" && s.IntoType != string(resultType) {
 		errs = append(errs, Error{
 			Code:    "LINT_TYPE_MISMATCH",
-			Message: fmt.Sprintf("%s: INTO type annotation mismatch: expected %s, got %s", s.OpName, opDef.ResultType, s.IntoType),
+			Message: fmt.Sprintf("%s: INTO type annotation mismatch: expected %s, got %s", s.OpName, resultType, s.IntoType),
 			Loc:     s.Loc,
 		})
 	}
 
-	return errs, string(opDef.ResultType)
+	return errs, string(resultType)
 }
 
 func (l *Linter) lintExpr(expr ast.Expr, expectedType string, symbols map[string]string) []Error {
@@ -362,12 +402,20 @@ func (l *Linter) lintExpr(expr ast.Expr, expectedType string, symbols map[string
 		actualType = "BOOL"
 	case *ast.NullExpr:
 		actualType = "NULL"
+	case *ast.ListExpr:
+		for _, item := range e.Items {
+			errs = append(errs, l.lintExpr(item, "", symbols)...)
+		}
+		actualType = "LIST"
 	}
 
-	if expectedType != "" && actualType != "" && expectedType != actualType {
+	// Ops with a dynamic result type (e.g. JSON_GET) bind UNKNOWN; their kind is checked at runtime.
+	if expectedType != "" && actualType != "" && actualType != "UNKNOWN" && expectedType != actualType {
 		if actualType != "NULL" {
 			hint := ""
-			if expectedType == "TEXT" {
+			if expectedType == "TEXT" && actualType == "DOCS" {
+				hint = fmt.Sprintf("This op reads one document. FIND_TEXT, FIND_REGEX and GREP_DOCS search every document of %s; otherwise use FOR_EACH over %s and GET_FIELD FIELD \"text\".", l.getExprName(expr), l.getExprName(expr))
+			} else if expectedType == "TEXT" {
 				hint = fmt.Sprintf("Use TO_TEXT VALUE=%s to convert.", l.getExprName(expr))
 			} else if expectedType == "OFFSET" && actualType == "INT" {
 				hint = fmt.Sprintf("Use OFFSET VALUE=%s to create a position.", l.getExprName(expr))
@@ -399,7 +447,14 @@ func (l *Linter) getExprName(e ast.Expr) string {
 func (l *Linter) getCanonicalTemplate(op *ops.Op) string {
 	res := op.Name
 	for _, p := range op.Signature {
-		res += " " + p.Kw + " <expr>"
+		switch {
+		case p.Default != "":
+			res += " [" + p.Kw + " " + p.Default + "]"
+		case p.Omittable():
+			res += " [" + p.Kw + " <expr>]"
+		default:
+			res += " " + p.Kw + " <expr>"
+		}
 	}
 	if op.Into {
 		res += " INTO <ident>"
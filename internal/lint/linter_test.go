@@ -1,6 +1,7 @@
 package lint
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/agenthands/envllm/internal/lex"
@@ -25,6 +26,16 @@ func TestLinter(t *testing.T) {
 		{
 			"Wrong order",
 			"CELL test:\n  FIND_TEXT NEEDLE \"x\" SOURCE PROMPT MODE FIRST IGNORE_CASE true INTO out\n",
+			false,
+		},
+		{
+			"Defaults left out",
+			"CELL test:\n  FIND_TEXT SOURCE PROMPT NEEDLE \"x\" INTO out\n",
+			false,
+		},
+		{
+			"Unknown keyword",
+			"CELL test:\n  FIND_TEXT SOURCE PROMPT NEEDLE \"x\" COUNT 1 INTO out\n",
 			true,
 		},
 		{
@@ -63,3 +74,56 @@ func TestLinter(t *testing.T) {
 		})
 	}
 }
+
+func TestLinter_DocsInTextOp(t *testing.T) {
+	tbl, _ := ops.LoadTable("../../assets/ops.json")
+	lnt := NewLinter(tbl)
+
+	lintSrc := func(cell string) []Error {
+		src := "RLMDSL 0.2\nTASK t:\n  INPUT corpus: DOCS\n  CELL search:\n    " + cell + "\n  OUTPUT out\n"
+		prog, err := parse.NewParser(lex.NewLexer("test.rlm", src), parse.ModeCompat).Parse()
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		return lnt.Lint(prog)
+	}
+
+	// FIND_TEXT and FIND_REGEX search every document and produce ROWS.
+	if errs := lintSrc(`FIND_TEXT SOURCE corpus NEEDLE "x" MODE FIRST IGNORE_CASE false INTO out: ROWS`); len(errs) > 0 {
+		t.Errorf("expected FIND_TEXT over DOCS to lint, got %+v", errs)
+	}
+	if errs := lintSrc(`FIND_REGEX SOURCE corpus PATTERN "x" MODE FIRST INTO out: ROWS`); len(errs) > 0 {
+		t.Errorf("expected FIND_REGEX over DOCS to lint, got %+v", errs)
+	}
+	errs := lintSrc(`FIND_TEXT SOURCE corpus NEEDLE "x" MODE FIRST IGNORE_CASE false INTO out: OFFSET`)
+	if len(errs) != 1 || errs[0].Code != "LINT_TYPE_MISMATCH" || !strings.Contains(errs[0].Message, "expected ROWS") {
+		t.Errorf("expected FIND_TEXT over DOCS to produce ROWS, got %+v", errs)
+	}
+
+	// Other text ops read one document.
+	errs = lintSrc(`STATS SOURCE corpus INTO out: STRUCT`)
+	if len(errs) != 1 || errs[0].Code != "LINT_TYPE_MISMATCH" || !strings.Contains(errs[0].Hint, "FIND_TEXT, FIND_REGEX and GREP_DOCS") {
+		t.Errorf("expected a type mismatch pointing at the DOCS search ops, got %+v", errs)
+	}
+}
+
+func TestLinter_ClauseOrder(t *testing.T) {
+	tbl, _ := ops.LoadTable("../../assets/ops.json")
+
+	src := "CELL test:\n  FIND_TEXT NEEDLE \"x\" SOURCE PROMPT MODE FIRST IGNORE_CASE true INTO out\n"
+	prog, err := parse.NewParser(lex.NewLexer("test.rlm", src), parse.ModeCompat).Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, mode := range []Mode{ModeCompat, ModeStrict} {
+		found := false
+		for _, e := range NewLinter(tbl).WithMode(mode).Lint(prog) {
+			if e.Code == "LINT_CLAUSE_ORDER" {
+				found = true
+			}
+		}
+		if found != (mode == ModeStrict) {
+			t.Errorf("mode %v: LINT_CLAUSE_ORDER reported = %v", mode, found)
+		}
+	}
+}
@@ -33,7 +33,7 @@ func Migrate(src string, table *ops.Table) (string, *Report, error) {
 	}
 
 	report := &Report{Changes: applied}
-	return dfmt.Format(patched), report, nil
+	return dfmt.FormatWith(patched, dfmt.Options{Table: table, FillDefaults: true}), report, nil
 }
 
 // Migrator handles version-to-version transformations.
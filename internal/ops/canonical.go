@@ -0,0 +1,151 @@
+package ops
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TableVersion is the version of the ops table built from the modules.
+const TableVersion = "0.2"
+
+// DefaultModules returns the modules every registry is built from, in the
+// order their ops appear in the canonical table.
+func DefaultModules() []Module {
+	return []Module{&CoreModule{}, &FSModule{}, &WebModule{}}
+}
+
+// BuildTable builds the ops table defined by mods. An op defined by more
+// than one module is an error.
+func BuildTable(mods ...Module) (*Table, error) {
+	t := &Table{Version: TableVersion, Ops: make(map[string]*Op)}
+	owners := make(map[string]string)
+	for _, m := range mods {
+		for _, op := range m.Operations() {
+			if owner, ok := owners[op.Name]; ok {
+				return nil, fmt.Errorf("module %s: op %s is already defined by module %s", m.ID(), op.Name, owner)
+			}
+			o := op
+			t.Ops[op.Name] = &o
+			owners[op.Name] = m.ID()
+		}
+	}
+	return t, nil
+}
+
+// DumpTable writes the canonical ops table defined by mods as indented
+// JSON, in module order. It is the content assets/ops.json must hold.
+func DumpTable(w io.Writer, mods ...Module) error {
+	if _, err := BuildTable(mods...); err != nil {
+		return err
+	}
+	out := struct {
+		Version string `json:"version"`
+		Ops     []Op   `json:"ops"`
+	}{Version: TableVersion, Ops: []Op{}}
+	for _, m := range mods {
+		for _, op := range m.Operations() {
+			if op.Capabilities == nil {
+				op.Capabilities = []string{}
+			}
+			if op.Signature == nil {
+				op.Signature = []Param{}
+			}
+			out.Ops = append(out.Ops, op)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Diff reports every way got differs from want, one line per op, sorted by
+// op name. An empty result means the tables agree.
+func Diff(want, got *Table) []string {
+	var out []string
+	if want.Version != got.Version {
+		out = append(out, fmt.Sprintf("version: expected %q, got %q", want.Version, got.Version))
+	}
+	for name, w := range want.Ops {
+		g, ok := got.Ops[name]
+		if !ok {
+			out = append(out, fmt.Sprintf("%s: missing", name))
+			continue
+		}
+		if d := diffOp(w, g); d != "" {
+			out = append(out, fmt.Sprintf("%s: %s", name, d))
+		}
+	}
+	for name := range got.Ops {
+		if _, ok := want.Ops[name]; !ok {
+			out = append(out, fmt.Sprintf("%s: not defined by any module", name))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// diffOp describes how got differs from want, or returns "" if the two
+// definitions agree.
+func diffOp(want, got *Op) string {
+	var diffs []string
+	if !sameStrings(want.Capabilities, got.Capabilities) {
+		diffs = append(diffs, fmt.Sprintf("capabilities %v, expected %v", got.Capabilities, want.Capabilities))
+	}
+	if want.ResultType != got.ResultType {
+		diffs = append(diffs, fmt.Sprintf("result type %q, expected %q", got.ResultType, want.ResultType))
+	}
+	if want.Into != got.Into {
+		diffs = append(diffs, fmt.Sprintf("into %v, expected %v", got.Into, want.Into))
+	}
+	if want.Docs != got.Docs {
+		diffs = append(diffs, fmt.Sprintf("docs %v, expected %v", got.Docs, want.Docs))
+	}
+	if !sameSignature(want.Signature, got.Signature) {
+		diffs = append(diffs, fmt.Sprintf("signature %s, expected %s", formatSignature(got.Signature), formatSignature(want.Signature)))
+	}
+	return strings.Join(diffs, "; ")
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSignature(a, b []Param) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Kw != b[i].Kw || a[i].Type != b[i].Type || a[i].Default != b[i].Default || a[i].Optional != b[i].Optional || !sameStrings(a[i].Enum, b[i].Enum) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatSignature(sig []Param) string {
+	parts := make([]string, len(sig))
+	for i, p := range sig {
+		parts[i] = fmt.Sprintf("%s %s", p.Kw, p.Type)
+		if len(p.Enum) > 0 {
+			parts[i] += "(" + strings.Join(p.Enum, "|") + ")"
+		}
+		if p.Default != "" {
+			parts[i] += "=" + p.Default
+		} else if p.Optional {
+			parts[i] += "?"
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
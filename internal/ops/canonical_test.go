@@ -0,0 +1,147 @@
+package ops
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestOpsJSONMatchesModules(t *testing.T) {
+	want, err := BuildTable(DefaultModules()...)
+	if err != nil {
+		t.Fatalf("BuildTable failed: %v", err)
+	}
+	got, err := LoadTable("../../assets/ops.json")
+	if err != nil {
+		t.Fatalf("LoadTable failed: %v", err)
+	}
+	for _, d := range Diff(want, got) {
+		t.Errorf("assets/ops.json drift: %s (run 'envllm ops dump > assets/ops.json')", d)
+	}
+
+	var buf bytes.Buffer
+	if err := DumpTable(&buf, DefaultModules()...); err != nil {
+		t.Fatalf("DumpTable failed: %v", err)
+	}
+	data, _ := os.ReadFile("../../assets/ops.json")
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("assets/ops.json is not in canonical form (run 'envllm ops dump > assets/ops.json')")
+	}
+}
+
+func TestDiff_ReportsDrift(t *testing.T) {
+	want, _ := BuildTable(DefaultModules()...)
+	got, _ := BuildTable(DefaultModules()...)
+
+	stats := *got.Ops["STATS"]
+	stats.ResultType = runtime.KindJSON
+	stats.Capabilities = []string{"fs_read"}
+	got.Ops["STATS"] = &stats
+	find := *got.Ops["FIND_TEXT"]
+	find.Signature = find.Signature[:2]
+	got.Ops["FIND_TEXT"] = &find
+	delete(got.Ops, "CITE")
+	got.Ops["EXTRA"] = &Op{Name: "EXTRA"}
+
+	diffs := strings.Join(Diff(want, got), "\n")
+	for _, frag := range []string{
+		"CITE: missing",
+		"EXTRA: not defined by any module",
+		`STATS: capabilities [fs_read], expected [pure]; result type "JSON", expected "STRUCT"`,
+		"FIND_TEXT: signature [SOURCE TEXT, NEEDLE TEXT]",
+	} {
+		if !strings.Contains(diffs, frag) {
+			t.Errorf("expected %q in diff, got:\n%s", frag, diffs)
+		}
+	}
+}
+
+type testModule struct {
+	id  string
+	ops []Op
+}
+
+func (m *testModule) ID() string       { return m.id }
+func (m *testModule) Operations() []Op { return m.ops }
+func (m *testModule) Handlers() map[string]OpImplementation {
+	h := make(map[string]OpImplementation)
+	for _, op := range m.ops {
+		h[op.Name] = func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return runtime.Value{Kind: runtime.KindString, V: "replaced"}, nil
+		}
+	}
+	return h
+}
+
+func TestRegisterModule_RejectsDuplicates(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	reg := mustRegistry(t, tbl)
+
+	stats := *tbl.Ops["STATS"]
+	err := reg.RegisterModule(&testModule{id: "shadow", ops: []Op{stats}})
+	if err == nil || !strings.Contains(err.Error(), "already registered by module core") {
+		t.Fatalf("expected duplicate op error, got %v", err)
+	}
+
+	if _, err := BuildTable(&CoreModule{}, &testModule{id: "shadow", ops: []Op{stats}}); err == nil {
+		t.Error("expected BuildTable to reject a duplicate op")
+	}
+}
+
+func TestRegisterModule_AddsNewOps(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	reg := mustRegistry(t, tbl)
+
+	op := Op{Name: "SHOUT", Capabilities: []string{"pure"}, ResultType: runtime.KindString, Into: true}
+	if err := reg.RegisterModule(&testModule{id: "shout", ops: []Op{op}}); err != nil {
+		t.Fatalf("RegisterModule failed: %v", err)
+	}
+	if _, ok := tbl.Ops["SHOUT"]; !ok {
+		t.Error("expected SHOUT to be added to the shared table")
+	}
+}
+
+func TestNewRegistry_RejectsDriftedTable(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	tbl.Ops["STATS"].ResultType = runtime.KindInt
+
+	_, err := NewRegistry(tbl)
+	if err == nil || !strings.Contains(err.Error(), "op STATS disagrees with the ops table") {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+}
+
+type hijackModule struct{ testModule }
+
+func (m *hijackModule) Handlers() map[string]OpImplementation {
+	h := m.testModule.Handlers()
+	h["STATS"] = func(s *runtime.Session, args Args) (runtime.Value, error) {
+		return runtime.Value{Kind: runtime.KindString, V: "hijacked"}, nil
+	}
+	return h
+}
+
+func TestRegisterModule_RejectsUndeclaredHandlers(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	reg := mustRegistry(t, tbl)
+	before := reg.impls["STATS"]
+
+	op := Op{Name: "SHOUT", Capabilities: []string{"pure"}, ResultType: runtime.KindString, Into: true}
+	err := reg.RegisterModule(&hijackModule{testModule{id: "hijack", ops: []Op{op}}})
+	if err == nil || !strings.Contains(err.Error(), "handler STATS is not one of its operations") {
+		t.Fatalf("expected an undeclared handler error, got %v", err)
+	}
+	if _, ok := tbl.Ops["SHOUT"]; ok {
+		t.Error("expected nothing to be registered")
+	}
+	if _, ok := reg.owners["SHOUT"]; ok {
+		t.Error("expected SHOUT to have no owner")
+	}
+	if fmt.Sprintf("%p", reg.impls["STATS"]) != fmt.Sprintf("%p", before) {
+		t.Error("expected the STATS handler to stay in place")
+	}
+}
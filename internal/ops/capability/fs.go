@@ -20,6 +20,15 @@ func ReadFile(s *runtime.Session, path runtime.Value) (runtime.Value, error) {
 		return runtime.Value{}, err
 	}
 
+	// Stores that can stream a file in avoid holding it in memory.
+	if fl, ok := s.Stores.Text.(runtime.TextFileLoader); ok {
+		h, err := fl.AddFile(p)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("READ_FILE failed: %v", err)
+		}
+		return runtime.Value{Kind: runtime.KindText, V: h}, nil
+	}
+
 	data, err := os.ReadFile(p)
 	if err != nil {
 		return runtime.Value{}, fmt.Errorf("READ_FILE failed: %v", err)
@@ -29,6 +38,27 @@ func ReadFile(s *runtime.Session, path runtime.Value) (runtime.Value, error) {
 	return runtime.Value{Kind: runtime.KindText, V: h}, nil
 }
 
+// ReadFileBinary implements READ_FILE MODE BINARY: the file's raw content as
+// BYTES, with no assumption about its encoding.
+func ReadFileBinary(s *runtime.Session, path runtime.Value) (runtime.Value, error) {
+	p := ""
+	if path.Kind == runtime.KindText {
+		p, _ = s.Stores.Text.Get(path.V.(runtime.TextHandle))
+	} else {
+		p = path.V.(string)
+	}
+
+	if err := s.ValidatePath(p, false); err != nil {
+		return runtime.Value{}, err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("READ_FILE failed: %v", err)
+	}
+	return runtime.Value{Kind: runtime.KindBytes, V: data}, nil
+}
+
 // WriteFile implements the WRITE_FILE operation.
 func WriteFile(s *runtime.Session, path runtime.Value, source runtime.Value) (runtime.Value, error) {
 	p := ""
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
 )
 
 type mockTextStore struct {
@@ -117,3 +118,44 @@ func TestFS_Ops(t *testing.T) {
 		t.Errorf("expected OS error for WriteFile to directory")
 	}
 }
+
+func TestReadFile_StreamsIntoDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "corpus.txt")
+	os.WriteFile(path, []byte("streamed content"), 0644)
+
+	ds, err := store.NewDiskStore(filepath.Join(dir, "segments"))
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	defer ds.Close()
+	s := runtime.NewSession(runtime.Policy{AllowedReadPaths: []string{dir}}, ds)
+
+	res, err := ReadFile(s, runtime.Value{Kind: runtime.KindString, V: path})
+	if err != nil {
+		t.Fatalf("READ_FILE failed: %v", err)
+	}
+	if got, _ := ds.Get(res.V.(runtime.TextHandle)); got != "streamed content" {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestReadFileBinary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "attachment.bin")
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	os.WriteFile(path, data, 0644)
+
+	s := runtime.NewSession(runtime.Policy{AllowedReadPaths: []string{dir}}, &mockTextStore{content: make(map[string]string)})
+	res, err := ReadFileBinary(s, runtime.Value{Kind: runtime.KindString, V: path})
+	if err != nil {
+		t.Fatalf("READ_FILE BINARY failed: %v", err)
+	}
+	if res.Kind != runtime.KindBytes || string(res.V.([]byte)) != string(data) {
+		t.Errorf("expected raw bytes, got %v %v", res.Kind, res.V)
+	}
+
+	if _, err := ReadFileBinary(s, runtime.Value{Kind: runtime.KindString, V: "/etc/passwd"}); err == nil {
+		t.Error("expected security error for non-whitelisted path")
+	}
+}
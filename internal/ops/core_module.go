@@ -16,13 +16,13 @@ func (m *CoreModule) ID() string { return "core" }
 func (m *CoreModule) Operations() []Op {
 	return []Op{
 		{Name: "STATS", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
-		{Name: "GET_FIELD", Capabilities: []string{"pure"}, ResultType: runtime.KindJSON, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindStruct}, {Kw: "FIELD", Type: runtime.KindText}}, Into: true},
+		{Name: "GET_FIELD", Capabilities: []string{"pure"}, ResultType: "", Signature: []Param{{Kw: "SOURCE", Type: runtime.KindStruct}, {Kw: "FIELD", Type: runtime.KindText}}, Into: true},
 		{Name: "FIND_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "NEEDLE", Type: runtime.KindText},
-			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}},
-			{Kw: "IGNORE_CASE", Type: runtime.KindBool},
-		}, Into: true},
+			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}, Default: "FIRST"},
+			{Kw: "IGNORE_CASE", Type: runtime.KindBool, Default: "false"},
+		}, Into: true, Docs: true},
 		{Name: "WINDOW_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "CENTER", Type: runtime.KindOffset},
@@ -36,18 +36,18 @@ func (m *CoreModule) Operations() []Op {
 		{Name: "FIND_REGEX", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "PATTERN", Type: runtime.KindText},
-			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}},
-		}, Into: true},
+			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}, Default: "FIRST"},
+		}, Into: true, Docs: true},
 		{Name: "AFTER_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "NEEDLE", Type: runtime.KindText},
-			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}},
-			{Kw: "IGNORE_CASE", Type: runtime.KindBool},
+			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}, Default: "FIRST"},
+			{Kw: "IGNORE_CASE", Type: runtime.KindBool, Default: "false"},
 		}, Into: true},
 		{Name: "AFTER_REGEX", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "PATTERN", Type: runtime.KindText},
-			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}},
+			{Kw: "MODE", Enum: []string{"FIRST", "LAST"}, Default: "FIRST"},
 		}, Into: true},
 		{Name: "MATCH_GROUP", Capabilities: []string{"pure"}, ResultType: runtime.KindSpan, Signature: []Param{
 			{Kw: "MATCH", Type: runtime.KindStruct},
@@ -66,16 +66,45 @@ func (m *CoreModule) Operations() []Op {
 		{Name: "EXTRACT_JSON", Capabilities: []string{"pure"}, ResultType: runtime.KindJSON, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 		}, Into: true},
+		{Name: "FIND_JSON", Capabilities: []string{"pure"}, ResultType: "", Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "MODE", Enum: []string{"FIRST", "LAST", "ALL"}, Default: "FIRST"},
+			{Kw: "TOLERANT", Type: runtime.KindBool, Default: "false"},
+		}, Into: true},
 		{Name: "EXTRACT_VALUE", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindText},
 			{Kw: "KEY", Type: runtime.KindText},
 			{Kw: "UNTIL", Type: runtime.KindText},
 		}, Into: true},
 		{Name: "JSON_PARSE", Capabilities: []string{"pure"}, ResultType: runtime.KindJSON, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
-		{Name: "JSON_GET", Capabilities: []string{"pure"}, ResultType: runtime.KindJSON, Signature: []Param{
-			{Kw: "SOURCE", Type: runtime.KindJSON},
+		{Name: "JSON_GET", Capabilities: []string{"pure"}, ResultType: "", Signature: []Param{
+			{Kw: "SOURCE", Type: ""},
 			{Kw: "PATH", Type: runtime.KindText},
 		}, Into: true},
+		{Name: "JSON_OBJECT", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
+			{Kw: "KEYS", Type: runtime.KindList},
+			{Kw: "VALUES", Type: runtime.KindList},
+		}, Into: true},
+		{Name: "JSON_ARRAY", Capabilities: []string{"pure"}, ResultType: runtime.KindJSON, Signature: []Param{
+			{Kw: "ITEMS", Type: ""},
+		}, Into: true},
+		{Name: "JSON_SET", Capabilities: []string{"pure"}, ResultType: "", Signature: []Param{
+			{Kw: "SOURCE", Type: ""},
+			{Kw: "PATH", Type: runtime.KindText},
+			{Kw: "VALUE", Type: ""},
+		}, Into: true},
+		{Name: "JSON_MERGE", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
+			{Kw: "A", Type: ""},
+			{Kw: "B", Type: ""},
+		}, Into: true},
+		{Name: "VALIDATE_JSON", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
+			{Kw: "SOURCE", Type: ""},
+			{Kw: "SCHEMA", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "ASSERT_SCHEMA", Capabilities: []string{"pure"}, ResultType: runtime.KindBool, Signature: []Param{
+			{Kw: "SOURCE", Type: ""},
+			{Kw: "SCHEMA", Type: runtime.KindText},
+		}, Into: true},
 		{Name: "SELECT_FIELDS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
 			{Kw: "SOURCE", Type: runtime.KindRows},
 			{Kw: "FIELDS", Type: runtime.KindList},
@@ -91,12 +120,128 @@ func (m *CoreModule) Operations() []Op {
 			{Kw: "GROUP_BY", Type: runtime.KindText},
 			{Kw: "COMPUTE", Enum: []string{"COUNT", "SUM", "AVG"}},
 		}, Into: true},
+		{Name: "SORT_ROWS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindRows},
+			{Kw: "KEY", Type: runtime.KindText},
+			{Kw: "ORDER", Enum: []string{"ASC", "DESC"}, Default: "ASC"},
+		}, Into: true},
+		{Name: "PARSE_DATE", Capabilities: []string{"pure"}, ResultType: runtime.KindTimestamp, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "LAYOUT", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "FORMAT_DATE", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindTimestamp},
+			{Kw: "LAYOUT", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "DATE_DIFF", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{
+			{Kw: "FROM", Type: runtime.KindTimestamp},
+			{Kw: "TO", Type: runtime.KindTimestamp},
+			{Kw: "UNIT", Enum: []string{"SECONDS", "MINUTES", "HOURS", "DAYS", "WEEKS"}},
+		}, Into: true},
+		{Name: "DATE_ADD", Capabilities: []string{"pure"}, ResultType: runtime.KindTimestamp, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindTimestamp},
+			{Kw: "AMOUNT", Type: runtime.KindInt},
+			{Kw: "UNIT", Enum: []string{"SECONDS", "MINUTES", "HOURS", "DAYS", "WEEKS", "MONTHS", "YEARS"}},
+		}, Into: true},
+		{Name: "DATE_COMPARE", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{
+			{Kw: "A", Type: runtime.KindTimestamp},
+			{Kw: "B", Type: runtime.KindTimestamp},
+		}, Into: true},
+		{Name: "NOW", Capabilities: []string{"pure"}, ResultType: runtime.KindTimestamp, Signature: []Param{}, Into: true},
+		{Name: "MD_SECTIONS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "MD_CODE_BLOCKS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "MD_TABLE", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "INDEX", Type: runtime.KindInt},
+		}, Into: true},
+		{Name: "MD_LINKS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "HTML_TO_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "HTML_SOURCE_SPAN", Capabilities: []string{"pure"}, ResultType: runtime.KindSpan, Signature: []Param{
+			{Kw: "MAP", Type: runtime.KindStruct},
+			{Kw: "SPAN", Type: runtime.KindSpan},
+		}, Into: true},
+		{Name: "HTML_SELECT", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "SELECTOR", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "GREP_LINES", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "PATTERN", Type: runtime.KindText},
+			{Kw: "CONTEXT", Type: runtime.KindInt, Default: "0"},
+		}, Into: true},
+		{Name: "LINE_AT", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "OFFSET", Type: runtime.KindOffset},
+		}, Into: true},
+		{Name: "LINE_RANGE", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "FROM", Type: runtime.KindInt},
+			{Kw: "TO", Type: runtime.KindInt},
+		}, Into: true},
+		{Name: "DIFF_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "A", Type: runtime.KindText},
+			{Kw: "B", Type: runtime.KindText},
+			{Kw: "GRANULARITY", Enum: []string{"LINE", "WORD"}},
+		}, Into: true},
+		{Name: "GREP_DOCS", Capabilities: []string{"pure"}, ResultType: runtime.KindRows, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindDocs},
+			{Kw: "PATTERN", Type: runtime.KindText},
+			{Kw: "CONTEXT", Type: runtime.KindInt, Default: "0"},
+		}, Into: true},
+		{Name: "GET_DOC", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindDocs},
+			{Kw: "ID", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "BYTES_LEN", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindBytes}}, Into: true},
+		{Name: "BYTES_SLICE", Capabilities: []string{"pure"}, ResultType: runtime.KindBytes, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindBytes},
+			{Kw: "START", Type: runtime.KindInt},
+			{Kw: "END", Type: runtime.KindInt},
+		}, Into: true},
+		{Name: "DECODE_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindBytes},
+			{Kw: "ENCODING", Enum: []string{"UTF8", "UTF16LE", "UTF16BE", "LATIN1", "ASCII"}},
+		}, Into: true},
+		{Name: "ENCODE_BASE64", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: ""}}, Into: true},
+		{Name: "DECODE_BASE64", Capabilities: []string{"pure"}, ResultType: runtime.KindBytes, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "RESOLVE_SPAN", Capabilities: []string{"pure"}, ResultType: runtime.KindSpan, Signature: []Param{{Kw: "SPAN", Type: runtime.KindSpan}}, Into: true},
+		{Name: "CITE", Capabilities: []string{"pure"}, ResultType: runtime.KindStruct, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindSpan}}, Into: true},
 		{Name: "GET_SPAN_START", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindSpan}}, Into: true},
 		{Name: "GET_SPAN_END", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindSpan}}, Into: true},
 		{Name: "CONCAT_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "A", Type: runtime.KindText}, {Kw: "B", Type: runtime.KindText}}, Into: true},
+		{Name: "TRIM_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "LOWER_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "UPPER_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "NORMALIZE_WHITESPACE", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "STRIP_QUOTES", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
+		{Name: "REPLACE_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "OLD", Type: runtime.KindText},
+			{Kw: "NEW", Type: runtime.KindText},
+		}, Into: true},
+		{Name: "REPLACE_REGEX", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "PATTERN", Type: runtime.KindText},
+			{Kw: "REPLACEMENT", Type: runtime.KindText},
+		}, Into: true},
 		{Name: "TO_TEXT", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "VALUE", Type: ""}}, Into: true},
 		{Name: "OFFSET", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{{Kw: "VALUE", Type: runtime.KindInt}}, Into: true},
 		{Name: "OFFSET_ADD", Capabilities: []string{"pure"}, ResultType: runtime.KindOffset, Signature: []Param{{Kw: "OFFSET", Type: runtime.KindOffset}, {Kw: "AMOUNT", Type: runtime.KindInt}}, Into: true},
+		{Name: "PARSE_INT", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "LOCALE", Enum: []string{"EN", "DE", "FR", "CH"}},
+		}, Into: true},
+		{Name: "PARSE_NUMBER", Capabilities: []string{"pure"}, ResultType: runtime.KindFloat, Signature: []Param{
+			{Kw: "SOURCE", Type: runtime.KindText},
+			{Kw: "LOCALE", Enum: []string{"EN", "DE", "FR", "CH"}},
+		}, Into: true},
+		{Name: "ADD", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "SUB", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "MUL", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "DIV", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "MOD", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "MIN", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
+		{Name: "MAX", Capabilities: []string{"pure"}, ResultType: runtime.KindInt, Signature: []Param{{Kw: "A", Type: runtime.KindInt}, {Kw: "B", Type: runtime.KindInt}}, Into: true},
 		{Name: "SPAN", Capabilities: []string{"pure"}, ResultType: runtime.KindSpan, Signature: []Param{{Kw: "START", Type: runtime.KindOffset}, {Kw: "END", Type: runtime.KindOffset}}, Into: true},
 		{Name: "AS_SPAN", Capabilities: []string{"pure"}, ResultType: runtime.KindSpan, Signature: []Param{{Kw: "OFFSET", Type: runtime.KindOffset}, {Kw: "LEN", Type: runtime.KindInt}}, Into: true},
 		{Name: "GET_COST", Capabilities: []string{"pure"}, ResultType: runtime.KindCost, Signature: []Param{{Kw: "RESULT", Type: runtime.KindJSON}}, Into: true},
@@ -110,121 +255,279 @@ func (m *CoreModule) Operations() []Op {
 
 func (m *CoreModule) Handlers() map[string]OpImplementation {
 	return map[string]OpImplementation{
-		"STATS": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.Stats(s, args[0])
+		"STATS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.Stats(s, args["SOURCE"])
 		},
-		"GET_FIELD": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"GET_FIELD": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			// Extract field name from TEXT handle
-			h := args[1].V.(runtime.TextHandle)
+			h := args["FIELD"].V.(runtime.TextHandle)
 			field, _ := s.Stores.Text.Get(h)
-			return pure.GetField(s, args[0], field)
+			return pure.GetField(s, args["SOURCE"], field)
 		},
-		"FIND_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"FIND_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			mode := "FIRST"
-			if m, ok := args[2].V.(string); ok { mode = m }
+			if m, ok := args["MODE"].V.(string); ok { mode = m }
 			ignoreCase := false
-			if ic, ok := args[3].V.(bool); ok { ignoreCase = ic }
-			return pure.FindText(s, args[0], args[1], mode, ignoreCase)
+			if ic, ok := args["IGNORE_CASE"].V.(bool); ok { ignoreCase = ic }
+			return pure.FindText(s, args["SOURCE"], args["NEEDLE"], mode, ignoreCase)
 		},
-		"WINDOW_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.WindowText(s, args[0], args[1].V.(int), args[2].V.(int))
+		"WINDOW_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.WindowText(s, args["SOURCE"], args["CENTER"].V.(int), args["RADIUS"].V.(int))
 		},
-		"SLICE_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.SliceText(s, args[0], args[1].V.(int), args[2].V.(int))
+		"SLICE_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.SliceText(s, args["SOURCE"], args["START"].V.(int), args["END"].V.(int))
 		},
-		"FIND_REGEX": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"FIND_REGEX": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			mode := "FIRST"
-			if m, ok := args[2].V.(string); ok { mode = m }
-			return pure.FindRegex(s, args[0], args[1], mode)
+			if m, ok := args["MODE"].V.(string); ok { mode = m }
+			return pure.FindRegex(s, args["SOURCE"], args["PATTERN"], mode)
 		},
-		"AFTER_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"AFTER_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			mode := "FIRST"
-			if m, ok := args[2].V.(string); ok { mode = m }
+			if m, ok := args["MODE"].V.(string); ok { mode = m }
 			ignoreCase := false
-			if ic, ok := args[3].V.(bool); ok { ignoreCase = ic }
-			return pure.AfterText(s, args[0], args[1], mode, ignoreCase)
+			if ic, ok := args["IGNORE_CASE"].V.(bool); ok { ignoreCase = ic }
+			return pure.AfterText(s, args["SOURCE"], args["NEEDLE"], mode, ignoreCase)
 		},
-		"AFTER_REGEX": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"AFTER_REGEX": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			mode := "FIRST"
-			if m, ok := args[2].V.(string); ok { mode = m }
-			return pure.AfterRegex(s, args[0], args[1], mode)
+			if m, ok := args["MODE"].V.(string); ok { mode = m }
+			return pure.AfterRegex(s, args["SOURCE"], args["PATTERN"], mode)
 		},
-		"MATCH_GROUP": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.MatchGroup(s, args[0], args[1].V.(int))
+		"MATCH_GROUP": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.MatchGroup(s, args["MATCH"], args["INDEX"].V.(int))
 		},
-		"CAPTURE_REGEX_GROUP": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.CaptureRegexGroup(s, args[0], args[1], args[2].V.(int))
+		"CAPTURE_REGEX_GROUP": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.CaptureRegexGroup(s, args["SOURCE"], args["PATTERN"], args["INDEX"].V.(int))
 		},
-		"VALUE_AFTER_DELIM": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.ValueAfterDelim(s, args[0], args[1], args[2])
+		"VALUE_AFTER_DELIM": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ValueAfterDelim(s, args["SOURCE"], args["DELIM"], args["UNTIL"])
 		},
-		"EXTRACT_JSON": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.ExtractJSON(s, args[0])
+		"EXTRACT_JSON": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ExtractJSON(s, args["SOURCE"])
 		},
-		"EXTRACT_VALUE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.ExtractValue(s, args[0], args[1], args[2])
+		"FIND_JSON": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			mode := "FIRST"
+			if m, ok := args["MODE"].V.(string); ok { mode = m }
+			tolerant := false
+			if t, ok := args["TOLERANT"].V.(bool); ok { tolerant = t }
+			return pure.FindJSON(s, args["SOURCE"], mode, tolerant)
+		},
+		"EXTRACT_VALUE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ExtractValue(s, args["SOURCE"], args["KEY"], args["UNTIL"])
 		},
-		"JSON_PARSE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.JSONParse(s, args[0])
+		"JSON_PARSE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.JSONParse(s, args["SOURCE"])
 		},
-		"JSON_GET": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"JSON_GET": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			path := ""
-			if p, ok := args[1].V.(string); ok { path = p } else if h, ok := args[1].V.(runtime.TextHandle); ok { path, _ = s.Stores.Text.Get(h) }
-			return pure.JSONGet(s, args[0], path)
+			if p, ok := args["PATH"].V.(string); ok { path = p } else if h, ok := args["PATH"].V.(runtime.TextHandle); ok { path, _ = s.Stores.Text.Get(h) }
+			return pure.JSONGet(s, args["SOURCE"], path)
+		},
+		"JSON_OBJECT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.JSONObject(s, args["KEYS"], args["VALUES"])
 		},
-		"SELECT_FIELDS": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.SelectFields(s, args[0], args[1])
+		"JSON_ARRAY": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.JSONArray(s, args["ITEMS"])
 		},
-		"FILTER_ROWS": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			h := args[1].V.(runtime.TextHandle)
+		"JSON_SET": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			path, _ := s.Stores.Text.Get(args["PATH"].V.(runtime.TextHandle))
+			return pure.JSONSet(s, args["SOURCE"], path, args["VALUE"])
+		},
+		"JSON_MERGE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.JSONMerge(s, args["A"], args["B"])
+		},
+		"VALIDATE_JSON": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ValidateJSON(s, args["SOURCE"], args["SCHEMA"])
+		},
+		"ASSERT_SCHEMA": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.AssertSchema(s, args["SOURCE"], args["SCHEMA"])
+		},
+		"SELECT_FIELDS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.SelectFields(s, args["SOURCE"], args["FIELDS"])
+		},
+		"FILTER_ROWS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			h := args["KEY"].V.(runtime.TextHandle)
 			key, _ := s.Stores.Text.Get(h)
-			op := args[2].V.(string)
-			return pure.FilterRows(s, args[0], key, op, args[3])
+			op := args["OP"].V.(string)
+			return pure.FilterRows(s, args["SOURCE"], key, op, args["VALUE"])
 		},
-		"AGGREGATE_ROWS": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			h := args[1].V.(runtime.TextHandle)
+		"AGGREGATE_ROWS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			h := args["GROUP_BY"].V.(runtime.TextHandle)
 			groupBy, _ := s.Stores.Text.Get(h)
-			compute := args[2].V.(string)
-			return pure.AggregateRows(s, args[0], groupBy, compute)
+			compute := args["COMPUTE"].V.(string)
+			return pure.AggregateRows(s, args["SOURCE"], groupBy, compute)
+		},
+		"SORT_ROWS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			key, _ := s.Stores.Text.Get(args["KEY"].V.(runtime.TextHandle))
+			return pure.SortRows(s, args["SOURCE"], key, args["ORDER"].V.(string))
+		},
+		"PARSE_DATE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			layout, _ := s.Stores.Text.Get(args["LAYOUT"].V.(runtime.TextHandle))
+			return pure.ParseDate(s, args["SOURCE"], layout)
+		},
+		"FORMAT_DATE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			layout, _ := s.Stores.Text.Get(args["LAYOUT"].V.(runtime.TextHandle))
+			return pure.FormatDate(s, args["SOURCE"], layout)
+		},
+		"DATE_DIFF": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DateDiff(s, args["FROM"], args["TO"], args["UNIT"].V.(string))
+		},
+		"DATE_ADD": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DateAdd(s, args["SOURCE"], args["AMOUNT"].V.(int), args["UNIT"].V.(string))
+		},
+		"DATE_COMPARE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DateCompare(s, args["A"], args["B"])
+		},
+		"NOW": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.Now(s)
+		},
+		"MD_SECTIONS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.MdSections(s, args["SOURCE"])
+		},
+		"MD_CODE_BLOCKS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.MdCodeBlocks(s, args["SOURCE"])
+		},
+		"MD_TABLE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.MdTable(s, args["SOURCE"], args["INDEX"].V.(int))
+		},
+		"MD_LINKS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.MdLinks(s, args["SOURCE"])
+		},
+		"HTML_TO_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.HtmlToText(s, args["SOURCE"])
+		},
+		"HTML_SOURCE_SPAN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.HtmlSourceSpan(s, args["MAP"], args["SPAN"])
+		},
+		"HTML_SELECT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.HtmlSelect(s, args["SOURCE"], args["SELECTOR"])
+		},
+		"GREP_LINES": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GrepLines(s, args["SOURCE"], args["PATTERN"], args["CONTEXT"].V.(int))
+		},
+		"LINE_AT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.LineAt(s, args["SOURCE"], args["OFFSET"].V.(int))
+		},
+		"LINE_RANGE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.LineRange(s, args["SOURCE"], args["FROM"].V.(int), args["TO"].V.(int))
+		},
+		"DIFF_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DiffText(s, args["A"], args["B"], args["GRANULARITY"].V.(string))
+		},
+		"GREP_DOCS": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GrepDocs(s, args["SOURCE"], args["PATTERN"], args["CONTEXT"].V.(int))
+		},
+		"GET_DOC": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GetDoc(s, args["SOURCE"], args["ID"])
+		},
+		"BYTES_LEN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.BytesLen(s, args["SOURCE"])
 		},
-		"GET_SPAN_START": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.GetSpanStart(s, args[0])
+		"BYTES_SLICE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.BytesSlice(s, args["SOURCE"], args["START"].V.(int), args["END"].V.(int))
 		},
-		"GET_SPAN_END": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.GetSpanEnd(s, args[0])
+		"DECODE_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DecodeText(s, args["SOURCE"], args["ENCODING"].V.(string))
 		},
-		"CONCAT_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.ConcatText(s, args[0], args[1])
+		"ENCODE_BASE64": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.EncodeBase64(s, args["SOURCE"])
 		},
-		"TO_TEXT": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.ToText(s, args[0])
+		"DECODE_BASE64": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.DecodeBase64(s, args["SOURCE"])
 		},
-		"OFFSET": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.Offset(s, args[0].V.(int))
+		"RESOLVE_SPAN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ResolveSpan(s, args["SPAN"])
 		},
-		"OFFSET_ADD": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.OffsetAdd(s, args[0], args[1].V.(int))
+		"CITE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.Cite(s, args["SOURCE"])
 		},
-		"SPAN": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.Span(s, args[0].V.(int), args[1].V.(int))
+		"GET_SPAN_START": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GetSpanStart(s, args["SOURCE"])
 		},
-		"AS_SPAN": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.AsSpan(s, args[0].V.(int), args[1].V.(int))
+		"GET_SPAN_END": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GetSpanEnd(s, args["SOURCE"])
 		},
-		"GET_COST": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
-			return pure.GetCost(s, args[0])
+		"CONCAT_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ConcatText(s, args["A"], args["B"])
 		},
-		"SUBCALL": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"TRIM_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.TrimText(s, args["SOURCE"])
+		},
+		"LOWER_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.LowerText(s, args["SOURCE"])
+		},
+		"UPPER_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.UpperText(s, args["SOURCE"])
+		},
+		"NORMALIZE_WHITESPACE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.NormalizeWhitespace(s, args["SOURCE"])
+		},
+		"STRIP_QUOTES": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.StripQuotes(s, args["SOURCE"])
+		},
+		"REPLACE_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ReplaceText(s, args["SOURCE"], args["OLD"], args["NEW"])
+		},
+		"REPLACE_REGEX": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ReplaceRegex(s, args["SOURCE"], args["PATTERN"], args["REPLACEMENT"])
+		},
+		"TO_TEXT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ToText(s, args["VALUE"])
+		},
+		"OFFSET": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.Offset(s, args["VALUE"].V.(int))
+		},
+		"OFFSET_ADD": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.OffsetAdd(s, args["OFFSET"], args["AMOUNT"].V.(int))
+		},
+		"PARSE_INT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ParseInt(s, args["SOURCE"], args["LOCALE"].V.(string))
+		},
+		"PARSE_NUMBER": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.ParseNumber(s, args["SOURCE"], args["LOCALE"].V.(string))
+		},
+		"ADD": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "ADD", args["A"], args["B"])
+		},
+		"SUB": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "SUB", args["A"], args["B"])
+		},
+		"MUL": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "MUL", args["A"], args["B"])
+		},
+		"DIV": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "DIV", args["A"], args["B"])
+		},
+		"MOD": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "MOD", args["A"], args["B"])
+		},
+		"MIN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "MIN", args["A"], args["B"])
+		},
+		"MAX": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.IntArith(s, "MAX", args["A"], args["B"])
+		},
+		"SPAN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.Span(s, args["START"], args["END"])
+		},
+		"AS_SPAN": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.AsSpan(s, args["OFFSET"], args["LEN"].V.(int))
+		},
+		"GET_COST": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			return pure.GetCost(s, args["RESULT"])
+		},
+		"SUBCALL": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			if s.Host == nil { return runtime.Value{}, fmt.Errorf("SUBCALL failed: no host configured") }
-			source := args[0].V.(runtime.TextHandle)
+			source := args["SOURCE"].V.(runtime.TextHandle)
 			var task string
-			if args[1].Kind == runtime.KindString { task = args[1].V.(string) } else if args[1].Kind == runtime.KindText {
-				taskHandle := args[1].V.(runtime.TextHandle)
+			if args["TASK"].Kind == runtime.KindString { task = args["TASK"].V.(string) } else if args["TASK"].Kind == runtime.KindText {
+				taskHandle := args["TASK"].V.(runtime.TextHandle)
 				var ok bool
 				task, ok = s.Stores.Text.Get(taskHandle)
 				if !ok { return runtime.Value{}, fmt.Errorf("SUBCALL failed: task text not found") }
-			} else { return runtime.Value{}, fmt.Errorf("SUBCALL failed: TASK must be TEXT or STRING, got %s", args[1].Kind) }
-			depthCost := args[2].V.(int)
+			} else { return runtime.Value{}, fmt.Errorf("SUBCALL failed: TASK must be TEXT or STRING, got %s", args["TASK"].Kind) }
+			depthCost := args["DEPTH_COST"].V.(int)
 			if s.Policy.MaxSubcalls > 0 && s.SubcallCount >= s.Policy.MaxSubcalls { return runtime.Value{}, &runtime.BudgetExceededError{Message: "max subcalls reached"} }
 			if s.Policy.MaxRecursionDepth > 0 && s.RecursionDepth+depthCost > s.Policy.MaxRecursionDepth { return runtime.Value{}, &runtime.BudgetExceededError{Message: fmt.Sprintf("recursion depth limit reached (cost %d)", depthCost)} }
 			req := runtime.SubcallRequest{Source: source, Task: task, DepthCost: depthCost, Budgets: make(map[string]int)}
@@ -242,7 +545,10 @@ func (m *FSModule) ID() string { return "fs" }
 
 func (m *FSModule) Operations() []Op {
 	return []Op{
-		{Name: "READ_FILE", Capabilities: []string{"fs_read"}, ResultType: runtime.KindText, Signature: []Param{{Kw: "PATH", Type: runtime.KindText}}, Into: true},
+		{Name: "READ_FILE", Capabilities: []string{"fs_read"}, ResultType: "", Signature: []Param{
+			{Kw: "PATH", Type: runtime.KindText},
+			{Kw: "MODE", Enum: []string{"TEXT", "BINARY"}, Default: "TEXT"},
+		}, Into: true},
 		{Name: "WRITE_FILE", Capabilities: []string{"fs_write"}, ResultType: runtime.KindBool, Signature: []Param{{Kw: "PATH", Type: runtime.KindText}, {Kw: "SOURCE", Type: runtime.KindText}}, Into: true},
 		{Name: "LIST_DIR", Capabilities: []string{"fs_read"}, ResultType: runtime.KindJSON, Signature: []Param{{Kw: "PATH", Type: runtime.KindText}}, Into: true},
 	}
@@ -250,8 +556,13 @@ func (m *FSModule) Operations() []Op {
 
 func (m *FSModule) Handlers() map[string]OpImplementation {
 	return map[string]OpImplementation{
-		"READ_FILE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) { return capability.ReadFile(s, args[0]) },
-		"WRITE_FILE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) { return capability.WriteFile(s, args[0], args[1]) },
-		"LIST_DIR": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) { return capability.ListDir(s, args[0]) },
+		"READ_FILE": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			if args["MODE"].V.(string) == "BINARY" {
+				return capability.ReadFileBinary(s, args["PATH"])
+			}
+			return capability.ReadFile(s, args["PATH"])
+		},
+		"WRITE_FILE": func(s *runtime.Session, args Args) (runtime.Value, error) { return capability.WriteFile(s, args["PATH"], args["SOURCE"]) },
+		"LIST_DIR": func(s *runtime.Session, args Args) (runtime.Value, error) { return capability.ListDir(s, args["PATH"]) },
 	}
 }
@@ -0,0 +1,70 @@
+package ops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// eachDoc runs op on the TEXT of every document of a DOCS SOURCE and
+// returns ROWS, one per document with a hit, in document order. Each row
+// holds doc_id and doc_index (0-based); a STRUCT result adds its fields,
+// any other result is stored under its kind's name (offset, span). Results
+// are anchored to the document's TEXT.
+func eachDoc(s *runtime.Session, op *Op, impl OpImplementation, args Args) (runtime.Value, error) {
+	docs, ok := args["SOURCE"].V.([]map[string]interface{})
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("%s: SOURCE must be DOCS, got %s", op.Name, args["SOURCE"].Kind)
+	}
+	rows := []map[string]interface{}{}
+	for i, doc := range docs {
+		h, ok := runtime.DocText(doc)
+		if !ok {
+			return runtime.Value{}, fmt.Errorf("%s: document %d has no TEXT", op.Name, i)
+		}
+		docArgs := make(Args, len(args))
+		for kw, v := range args {
+			docArgs[kw] = v
+		}
+		docArgs["SOURCE"] = runtime.Value{Kind: runtime.KindText, V: h}
+		res, err := callAnchored(s, op, impl, docArgs)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("%s: document %v: %v", op.Name, doc["id"], err)
+		}
+		row, hit := docRow(res)
+		if !hit {
+			continue
+		}
+		row["doc_id"] = doc["id"]
+		row["doc_index"] = i
+		rows = append(rows, row)
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
+
+// docRow turns the result of an op on one document into a row, reporting
+// false for a miss: a negative OFFSET or SPAN, or a STRUCT whose success
+// field is false.
+func docRow(res runtime.Value) (map[string]interface{}, bool) {
+	switch x := res.V.(type) {
+	case map[string]interface{}:
+		if x["success"] == false {
+			return nil, false
+		}
+		row := make(map[string]interface{}, len(x)+2)
+		for k, v := range x {
+			row[k] = v
+		}
+		return row, true
+	case int:
+		if res.Kind == runtime.KindOffset && x < 0 {
+			return nil, false
+		}
+	case runtime.Span:
+		if x.Start < 0 {
+			return nil, false
+		}
+	}
+	return map[string]interface{}{strings.ToLower(string(res.Kind)): res}, true
+}
@@ -1,5 +1,7 @@
 package ops
 
+import "fmt"
+
 // Module defines an extension package for EnvLLM.
 type Module interface {
 	ID() string
@@ -10,12 +12,40 @@ type Module interface {
 // OpDef is an alias for Op to match the plan's naming if preferred, 
 // but we already have Op struct in table.go.
 
+// RegisterModule adds the ops and handlers of m to the registry. Ops the
+// table does not define yet are added to it, so the linter sees them too.
+// It fails, registering nothing, if another module already registered one
+// of the ops, if the table defines one differently, or if m has a handler
+// for an op it does not declare.
 func (r *Registry) RegisterModule(m Module) error {
-	for _, op := range m.Operations() {
-		o := op
-		r.Table.Ops[op.Name] = &o
+	ops := m.Operations()
+	declared := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		declared[op.Name] = true
+		if owner, ok := r.owners[op.Name]; ok {
+			return fmt.Errorf("module %s: op %s is already registered by module %s", m.ID(), op.Name, owner)
+		}
+		if existing, ok := r.Table.Ops[op.Name]; ok {
+			o := op
+			if d := diffOp(&o, existing); d != "" {
+				return fmt.Errorf("module %s: op %s disagrees with the ops table: %s", m.ID(), op.Name, d)
+			}
+		}
+	}
+	handlers := m.Handlers()
+	for name := range handlers {
+		if !declared[name] {
+			return fmt.Errorf("module %s: handler %s is not one of its operations", m.ID(), name)
+		}
+	}
+	for _, op := range ops {
+		if _, ok := r.Table.Ops[op.Name]; !ok {
+			o := op
+			r.Table.Ops[op.Name] = &o
+		}
+		r.owners[op.Name] = m.ID()
 	}
-	for name, impl := range m.Handlers() {
+	for name, impl := range handlers {
 		r.impls[name] = impl
 	}
 	return nil
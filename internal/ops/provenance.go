@@ -0,0 +1,157 @@
+package ops
+
+import (
+	"fmt"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// primaryText returns the TEXT an op's OFFSET and SPAN arguments and
+// results refer to: its SOURCE argument, when SOURCE is a TEXT param. Other
+// TEXT params (a FIELD, PATH, KEY or PATTERN) are never anchors; ops
+// without a TEXT SOURCE have no primary text.
+func primaryText(op *Op, args Args) (runtime.TextHandle, bool) {
+	p, ok := op.Param("SOURCE")
+	if !ok || p.Type != runtime.KindText {
+		return runtime.TextHandle{}, false
+	}
+	h, ok := args["SOURCE"].V.(runtime.TextHandle)
+	return h, ok
+}
+
+// anchorArgs translates OFFSET and SPAN arguments that came from another
+// text into coordinates of the op's primary text. Values from a text that
+// shares no root with it are rejected.
+func anchorArgs(s *runtime.Session, op *Op, args Args) error {
+	target, ok := primaryText(op, args)
+	if !ok {
+		return nil
+	}
+	for kw, a := range args {
+		switch a.Kind {
+		case runtime.KindOffset:
+			pos, isInt := a.V.(int)
+			if !isInt || a.Source == "" {
+				continue
+			}
+			n, err := s.Rebase(a.Source, pos, target)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %v", op.Name, kw, err)
+			}
+			args[kw] = runtime.Value{Kind: runtime.KindOffset, V: n, Source: target.ID}
+		case runtime.KindSpan:
+			sp, isSpan := a.V.(runtime.Span)
+			if !isSpan || sp.Source == "" {
+				continue
+			}
+			start, err := s.Rebase(sp.Source, sp.Start, target)
+			if err == nil {
+				sp.End, err = s.Rebase(sp.Source, sp.End, target)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %s: %v", op.Name, kw, err)
+			}
+			sp.Start, sp.Source = start, target.ID
+			args[kw] = runtime.Value{Kind: runtime.KindSpan, V: sp}
+		}
+	}
+	return nil
+}
+
+// callAnchored runs impl after translating offsets and spans taken from
+// related texts, and anchors its result to the op's primary text.
+func callAnchored(s *runtime.Session, op *Op, impl OpImplementation, args Args) (runtime.Value, error) {
+	if err := anchorArgs(s, op, args); err != nil {
+		return runtime.Value{}, err
+	}
+	res, err := impl(s, args)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	return anchorResult(op, args, res), nil
+}
+
+// anchorResult records the op's primary text as the source of OFFSET and
+// SPAN values in res that do not name one yet.
+func anchorResult(op *Op, args Args, res runtime.Value) runtime.Value {
+	target, ok := primaryText(op, args)
+	if !ok {
+		return res
+	}
+	if res.Kind == runtime.KindOffset && res.Source == "" {
+		res.Source = target.ID
+	}
+	res.V, _ = anchorSpans(res.V, target.ID)
+	return res
+}
+
+// anchorSpans returns v with the source of nested spans set to id, and
+// whether any span changed. Maps and slices are never written in place: ops
+// may return values their inputs still hold, so the ones holding a changed
+// span are copied.
+func anchorSpans(v interface{}, id string) (interface{}, bool) {
+	switch x := v.(type) {
+	case runtime.Span:
+		if x.Source != "" {
+			return v, false
+		}
+		x.Source = id
+		return x, true
+	case runtime.Value:
+		e, changed := anchorSpans(x.V, id)
+		x.V = e
+		return x, changed
+	case map[string]interface{}:
+		var out map[string]interface{}
+		for k, e := range x {
+			a, changed := anchorSpans(e, id)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(x))
+				for k2, e2 := range x {
+					out[k2] = e2
+				}
+			}
+			out[k] = a
+		}
+		if out != nil {
+			return out, true
+		}
+	case []runtime.Span:
+		if out, ok := anchorSlice(x, id); ok {
+			return out, true
+		}
+	case []map[string]interface{}:
+		if out, ok := anchorSlice(x, id); ok {
+			return out, true
+		}
+	case []runtime.Value:
+		if out, ok := anchorSlice(x, id); ok {
+			return out, true
+		}
+	case []interface{}:
+		if out, ok := anchorSlice(x, id); ok {
+			return out, true
+		}
+	}
+	return v, false
+}
+
+// anchorSlice applies anchorSpans to the elements of x, copying x only if
+// one of them changed.
+func anchorSlice[T any](x []T, id string) ([]T, bool) {
+	var out []T
+	for i, e := range x {
+		a, changed := anchorSpans(e, id)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]T(nil), x...)
+		}
+		out[i] = a.(T)
+	}
+	return out, out != nil
+}
@@ -0,0 +1,123 @@
+package pure
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// BytesLen implements the BYTES_LEN operation.
+func BytesLen(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return runtime.Value{Kind: runtime.KindInt, V: len(source.V.([]byte))}, nil
+}
+
+// BytesSlice implements the BYTES_SLICE operation.
+// START and END are clamped to the data like SLICE_TEXT; an inverted range
+// yields empty BYTES. The result shares no memory with SOURCE.
+func BytesSlice(s *runtime.Session, source runtime.Value, start, end int) (runtime.Value, error) {
+	b := source.V.([]byte)
+	start = max(start, 0)
+	end = min(end, len(b))
+	if start > end {
+		return runtime.Value{Kind: runtime.KindBytes, V: []byte{}}, nil
+	}
+	return runtime.Value{Kind: runtime.KindBytes, V: append([]byte{}, b[start:end]...)}, nil
+}
+
+// DecodeText implements the DECODE_TEXT operation.
+// ENCODING is UTF8, UTF16LE, UTF16BE, LATIN1 or ASCII. UTF8 and ASCII
+// reject data that is not valid in the encoding, reporting the byte offset;
+// UTF16 input must have an even length and unpaired surrogates become
+// U+FFFD. LATIN1 accepts any data.
+func DecodeText(s *runtime.Session, source runtime.Value, encoding string) (runtime.Value, error) {
+	b := source.V.([]byte)
+	var text string
+	switch encoding {
+	case "UTF8":
+		for i := 0; i < len(b); {
+			r, n := utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && n == 1 {
+				return runtime.Value{}, fmt.Errorf("DECODE_TEXT: invalid UTF-8 at byte %d", i)
+			}
+			i += n
+		}
+		text = string(b)
+	case "ASCII":
+		for i, c := range b {
+			if c > unicode.MaxASCII {
+				return runtime.Value{}, fmt.Errorf("DECODE_TEXT: non-ASCII byte 0x%02x at byte %d", c, i)
+			}
+		}
+		text = string(b)
+	case "LATIN1":
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		text = string(runes)
+	case "UTF16LE", "UTF16BE":
+		if len(b)%2 != 0 {
+			return runtime.Value{}, fmt.Errorf("DECODE_TEXT: %s data has odd length %d", encoding, len(b))
+		}
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			if encoding == "UTF16LE" {
+				units[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+			} else {
+				units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+			}
+		}
+		text = string(utf16.Decode(units))
+	default:
+		return runtime.Value{}, fmt.Errorf("DECODE_TEXT: unsupported ENCODING %q", encoding)
+	}
+	return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(text)}, nil
+}
+
+// EncodeBase64 implements the ENCODE_BASE64 operation.
+// SOURCE may be BYTES or TEXT; the result is standard padded base64.
+func EncodeBase64(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	var b []byte
+	switch v := source.V.(type) {
+	case []byte:
+		b = v
+	case runtime.TextHandle:
+		text, _ := s.Stores.Text.Get(v)
+		b = []byte(text)
+	case string:
+		b = []byte(v)
+	default:
+		return runtime.Value{}, fmt.Errorf("ENCODE_BASE64: SOURCE must be BYTES or TEXT, got %s", source.Kind)
+	}
+	return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(base64.StdEncoding.EncodeToString(b))}, nil
+}
+
+// DecodeBase64 implements the DECODE_BASE64 operation.
+// Standard and URL-safe alphabets are accepted, with or without padding, and
+// whitespace such as MIME line breaks is ignored.
+func DecodeBase64(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	var firstErr error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		b, err := enc.DecodeString(text)
+		if err == nil {
+			return runtime.Value{Kind: runtime.KindBytes, V: b}, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return runtime.Value{}, fmt.Errorf("DECODE_BASE64 failed: %v", firstErr)
+}
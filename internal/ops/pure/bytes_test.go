@@ -0,0 +1,75 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestBytesOps(t *testing.T) {
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	bytesVal := func(b []byte) runtime.Value { return runtime.Value{Kind: runtime.KindBytes, V: b} }
+	textOf := func(v runtime.Value) string {
+		text, _ := ts.Get(v.V.(runtime.TextHandle))
+		return text
+	}
+
+	data := []byte("caf\xe9 \xff")
+	if res, _ := BytesLen(s, bytesVal(data)); res.V != 6 {
+		t.Errorf("BYTES_LEN: expected 6, got %v", res.V)
+	}
+	res, _ := BytesSlice(s, bytesVal(data), 0, 4)
+	if string(res.V.([]byte)) != "caf\xe9" {
+		t.Errorf("BYTES_SLICE: got %q", res.V)
+	}
+	if res, _ := BytesSlice(s, bytesVal(data), 5, 100); string(res.V.([]byte)) != "\xff" {
+		t.Errorf("BYTES_SLICE: expected END to be clamped, got %q", res.V)
+	}
+	if res, _ := BytesSlice(s, bytesVal(data), 4, 2); len(res.V.([]byte)) != 0 {
+		t.Errorf("BYTES_SLICE: expected empty result for inverted range, got %q", res.V)
+	}
+
+	// Latin-1 data is not valid UTF-8.
+	if _, err := DecodeText(s, bytesVal(data), "UTF8"); err == nil || err.Error() != "DECODE_TEXT: invalid UTF-8 at byte 3" {
+		t.Errorf("DECODE_TEXT UTF8: expected invalid UTF-8 error, got %v", err)
+	}
+	if res, err := DecodeText(s, bytesVal(data), "LATIN1"); err != nil || textOf(res) != "café ÿ" {
+		t.Errorf("DECODE_TEXT LATIN1: got %v %v", err, res)
+	}
+	if _, err := DecodeText(s, bytesVal(data), "ASCII"); err == nil {
+		t.Error("DECODE_TEXT ASCII: expected error for byte 0xe9")
+	}
+	utf16le := []byte{'h', 0, 'i', 0, 0x3d, 0xd8, 0x00, 0xde}
+	if res, err := DecodeText(s, bytesVal(utf16le), "UTF16LE"); err != nil || textOf(res) != "hi😀" {
+		t.Errorf("DECODE_TEXT UTF16LE: got %v %q", err, textOf(res))
+	}
+	if res, err := DecodeText(s, bytesVal([]byte{0, 'o', 0, 'k'}), "UTF16BE"); err != nil || textOf(res) != "ok" {
+		t.Errorf("DECODE_TEXT UTF16BE: got %v", err)
+	}
+	if _, err := DecodeText(s, bytesVal([]byte{0, 'o', 0}), "UTF16BE"); err == nil {
+		t.Error("DECODE_TEXT UTF16BE: expected error for odd length")
+	}
+
+	enc, err := EncodeBase64(s, bytesVal(data))
+	if err != nil || textOf(enc) != "Y2Fm6SD/" {
+		t.Fatalf("ENCODE_BASE64: got %v %q", err, textOf(enc))
+	}
+	if res, _ := EncodeBase64(s, runtime.Value{Kind: runtime.KindString, V: "hi"}); textOf(res) != "aGk=" {
+		t.Errorf("ENCODE_BASE64 of a string: got %q", textOf(res))
+	}
+	dec, err := DecodeBase64(s, enc)
+	if err != nil || string(dec.V.([]byte)) != string(data) {
+		t.Errorf("DECODE_BASE64 round trip: got %v %q", err, dec.V)
+	}
+	// MIME-wrapped, URL-safe and unpadded input is accepted.
+	for _, in := range []string{"Y2Fm\r\n6SD/", "Y2Fm6SD_", "aGk"} {
+		if _, err := DecodeBase64(s, runtime.Value{Kind: runtime.KindText, V: ts.Add(in)}); err != nil {
+			t.Errorf("DECODE_BASE64 %q: %v", in, err)
+		}
+	}
+	if _, err := DecodeBase64(s, runtime.Value{Kind: runtime.KindText, V: ts.Add("@@@")}); err == nil {
+		t.Error("DECODE_BASE64: expected error for invalid input")
+	}
+}
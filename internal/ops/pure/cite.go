@@ -0,0 +1,55 @@
+package pure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// Cite implements the CITE operation.
+// SOURCE is a span found in any text derived from an input. The result
+// locates it in the root text: quote (the cited text), source (the root
+// TEXT ID), span, start and end (byte offsets), start_line, start_column,
+// end_line and end_column (1-based; columns count characters and the end is
+// exclusive), and hash ("sha256:" and the hex digest of the quote), which
+// lets a reviewer verify the quote against the document.
+func Cite(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	sp := source.V.(runtime.Span)
+	if sp.Source == "" {
+		return runtime.Value{}, fmt.Errorf("CITE: SPAN has no source text; use a span returned by a search op")
+	}
+	root := s.ResolveSpan(sp)
+	text, ok := s.Stores.Text.Get(runtime.TextHandle{ID: root.Source})
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("CITE: source text %s is no longer available", root.Source)
+	}
+	if root.Start < 0 || root.Start > root.End || root.End > len(text) {
+		return runtime.Value{}, fmt.Errorf("CITE: span %d..%d out of range (text has %d bytes)", root.Start, root.End, len(text))
+	}
+
+	li := newLineIndex(text)
+	position := func(offset int) (int, int) {
+		n := li.lineOf(offset)
+		return n, utf8.RuneCountInString(text[li.starts[n-1]:offset]) + 1
+	}
+	startLine, startCol := position(root.Start)
+	endLine, endCol := position(root.End)
+
+	quote := text[root.Start:root.End]
+	sum := sha256.Sum256([]byte(quote))
+	return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{
+		"quote":        quote,
+		"source":       root.Source,
+		"span":         root,
+		"start":        root.Start,
+		"end":          root.End,
+		"start_line":   startLine,
+		"start_column": startCol,
+		"end_line":     endLine,
+		"end_column":   endCol,
+		"hash":         "sha256:" + hex.EncodeToString(sum[:]),
+	}}, nil
+}
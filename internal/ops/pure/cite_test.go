@@ -0,0 +1,66 @@
+package pure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestCite(t *testing.T) {
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	doc := "title\nprice: 42 €\ntotal: 99 €\n"
+	h := ts.Add(doc)
+
+	start := strings.Index(doc, "99 €")
+	sp := runtime.Span{Start: start, End: start + len("99 €"), Source: h.ID}
+	res, err := Cite(s, runtime.Value{Kind: runtime.KindSpan, V: sp})
+	if err != nil {
+		t.Fatalf("CITE failed: %v", err)
+	}
+	c := res.V.(map[string]interface{})
+
+	sum := sha256.Sum256([]byte("99 €"))
+	want := map[string]interface{}{
+		"quote":        "99 €",
+		"source":       h.ID,
+		"start":        start,
+		"end":          sp.End,
+		"start_line":   3,
+		"start_column": 8,
+		"end_line":     3,
+		"end_column":   12,
+		"hash":         "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	for k, v := range want {
+		if c[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, c[k])
+		}
+	}
+	if c["span"] != sp {
+		t.Errorf("span: expected %+v, got %+v", sp, c["span"])
+	}
+
+	// A span across a line break ends on the next line.
+	start = strings.Index(doc, "€\ntotal")
+	res, err = Cite(s, runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: start, End: start + len("€\nto"), Source: h.ID}})
+	if err != nil {
+		t.Fatalf("CITE failed: %v", err)
+	}
+	c = res.V.(map[string]interface{})
+	if c["start_line"] != 2 || c["start_column"] != 11 || c["end_line"] != 3 || c["end_column"] != 3 {
+		t.Errorf("unexpected range %v:%v-%v:%v", c["start_line"], c["start_column"], c["end_line"], c["end_column"])
+	}
+
+	if _, err := Cite(s, runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: 0, End: 3}}); err == nil {
+		t.Error("expected error for a span without a source")
+	}
+	if _, err := Cite(s, runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: 0, End: 999, Source: h.ID}}); err == nil {
+		t.Error("expected error for an out-of-range span")
+	}
+}
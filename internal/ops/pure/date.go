@@ -0,0 +1,377 @@
+package pure
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// autoLayouts are tried in order by PARSE_DATE LAYOUT "AUTO". Numeric
+// slash dates (03/04/2025) are handled separately because their field order
+// depends on the writer.
+var autoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	time.RFC1123,
+	time.RFC1123Z,
+	time.ANSIC,
+	"Monday, January 2, 2006",
+	"Mon, Jan 2, 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"January 2 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"02.01.2006 15:04",
+	"02.01.2006",
+	"2.1.2006",
+}
+
+var slashDateRe = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{4})$`)
+
+// layoutTokens maps the readable layout tokens accepted by PARSE_DATE and
+// FORMAT_DATE to Go reference-time fragments, longest first.
+var layoutTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"YY", "06"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+	{"D", "2"},
+	{"Z", "Z07:00"},
+	{"T", ""},
+}
+
+// layoutPart is one piece of a readable layout: a token, given as its Go
+// reference-time fragment, or literal text.
+type layoutPart struct {
+	layout  string
+	literal string
+}
+
+// splitLayout reads a PARSE_DATE/FORMAT_DATE layout. "ISO" and "DATE" name
+// RFC 3339 and YYYY-MM-DD. Anything else is tokens (YYYY, MM, DD, HH, mm, ss,
+// ...) around literal text: each run of letters is split into tokens longest
+// first, and a run with letters outside any token, like "Date" or "UTC", is
+// literal as a whole. The ISO separator T between tokens is literal too. Text
+// in single quotes is always literal, with two quotes standing for one. A
+// layout already written with Go's reference time (containing "2006", or
+// without any token or quote, like "02 Jan 06") is one Go fragment.
+func splitLayout(layout string) []layoutPart {
+	switch layout {
+	case "ISO":
+		return []layoutPart{{layout: time.RFC3339}}
+	case "DATE":
+		return []layoutPart{{layout: "2006-01-02"}}
+	}
+	if strings.Contains(layout, "2006") {
+		return []layoutPart{{layout: layout}}
+	}
+
+	var parts []layoutPart
+	quoted := false
+	literal := func(text string) {
+		if n := len(parts); n > 0 && parts[n-1].layout == "" {
+			parts[n-1].literal += text
+		} else {
+			parts = append(parts, layoutPart{literal: text})
+		}
+	}
+	for i := 0; i < len(layout); {
+		c := layout[i]
+		switch {
+		case c == '\'':
+			// '' is a quote; otherwise read to the closing quote, where ''
+			// also stands for a quote.
+			var sb strings.Builder
+			j := i + 1
+			if j < len(layout) && layout[j] == '\'' {
+				sb.WriteByte('\'')
+				j++
+			} else {
+				for ; j < len(layout); j++ {
+					if layout[j] != '\'' {
+						sb.WriteByte(layout[j])
+					} else if j+1 < len(layout) && layout[j+1] == '\'' {
+						sb.WriteByte('\'')
+						j++
+					} else {
+						j++
+						break
+					}
+				}
+			}
+			literal(sb.String())
+			quoted = true
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(layout) && isLetter(layout[j]) {
+				j++
+			}
+			if tokens, ok := splitTokens(layout[i:j]); ok {
+				for _, t := range tokens {
+					if t.layout == "" {
+						literal(t.token)
+					} else {
+						parts = append(parts, layoutPart{layout: t.layout})
+					}
+				}
+			} else {
+				literal(layout[i:j])
+			}
+			i = j
+		default:
+			literal(string(c))
+			i++
+		}
+	}
+	if len(parts) == 1 && parts[0].layout == "" && !quoted {
+		return []layoutPart{{layout: layout}}
+	}
+	return parts
+}
+
+// splitTokens splits a run of letters into layout tokens, longest first.
+func splitTokens(word string) ([]struct{ token, layout string }, bool) {
+	var out []struct{ token, layout string }
+	for i := 0; i < len(word); {
+		matched := false
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(word[i:], t.token) {
+				out = append(out, t)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// formatLayout formats t with a layout from splitLayout. Literal text is
+// copied as is, so it never reads as part of Go's reference time.
+func formatLayout(t time.Time, parts []layoutPart) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if p.layout == "" {
+			sb.WriteString(p.literal)
+		} else {
+			sb.WriteString(t.Format(p.layout))
+		}
+	}
+	return sb.String()
+}
+
+// parseLayout parses text with a layout from splitLayout. Literal text must
+// match exactly and is dropped; each token's field is cut out and the fields
+// are handed to time.Parse on their own, so literal text never reads as part
+// of Go's reference time.
+func parseLayout(text string, parts []layoutPart) (time.Time, error) {
+	if len(parts) == 1 && parts[0].layout != "" {
+		return time.Parse(parts[0].layout, text)
+	}
+
+	var layout, value []string
+	rest := text
+	for _, p := range parts {
+		if p.layout == "" {
+			if !strings.HasPrefix(rest, p.literal) {
+				return time.Time{}, fmt.Errorf("parsing %q: expected %q at %q", text, p.literal, rest)
+			}
+			rest = rest[len(p.literal):]
+			continue
+		}
+		n := fieldLen(rest, p.layout)
+		if n == 0 {
+			return time.Time{}, fmt.Errorf("parsing %q: missing %s at %q", text, p.layout, rest)
+		}
+		layout = append(layout, p.layout)
+		value = append(value, rest[:n])
+		rest = rest[n:]
+	}
+	if rest != "" {
+		return time.Time{}, fmt.Errorf("parsing %q: extra text %q", text, rest)
+	}
+	return time.Parse(strings.Join(layout, "|"), strings.Join(value, "|"))
+}
+
+// fieldLen returns the length of the field for the Go fragment layout at the
+// start of s: letters for month names, a zone offset for Z07:00, digits for
+// the rest.
+func fieldLen(s, layout string) int {
+	n := 0
+	switch layout {
+	case "Jan", "January":
+		for n < len(s) && isLetter(s[n]) {
+			n++
+		}
+	case "Z07:00":
+		if strings.HasPrefix(s, "Z") {
+			return 1
+		}
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			n = 1
+			for n < len(s) && n < 6 && (s[n] >= '0' && s[n] <= '9' || s[n] == ':') {
+				n++
+			}
+		}
+	default:
+		width := max(len(layout), 2)
+		for n < len(s) && n < width && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// parseDateAuto recognises the common date spellings in autoLayouts plus the
+// relative words now, today, yesterday and tomorrow, which are resolved
+// against the session clock. Values without a zone are taken as UTC.
+func parseDateAuto(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(text) {
+	case "now":
+		return now, nil
+	case "today":
+		return day, nil
+	case "yesterday":
+		return day.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return day.AddDate(0, 0, 1), nil
+	}
+
+	for _, l := range autoLayouts {
+		if t, err := time.Parse(l, text); err == nil {
+			return t, nil
+		}
+	}
+
+	if m := slashDateRe.FindStringSubmatch(text); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		switch {
+		case a > 12 || a == b:
+			return time.Parse("2/1/2006", text)
+		case b > 12:
+			return time.Parse("1/2/2006", text)
+		default:
+			return time.Time{}, fmt.Errorf("%q is ambiguous (day/month or month/day); pass LAYOUT \"DD/MM/YYYY\" or \"MM/DD/YYYY\"", text)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date %q; pass an explicit LAYOUT such as \"DD.MM.YYYY\"", text)
+}
+
+// ParseDate implements the PARSE_DATE operation.
+func ParseDate(s *runtime.Session, source runtime.Value, layout string) (runtime.Value, error) {
+	text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+
+	var t time.Time
+	var err error
+	if layout == "" || layout == "AUTO" {
+		t, err = parseDateAuto(text, s.Now())
+	} else {
+		t, err = parseLayout(strings.TrimSpace(text), splitLayout(layout))
+	}
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("PARSE_DATE failed: %v", err)
+	}
+	return runtime.Value{Kind: runtime.KindTimestamp, V: t}, nil
+}
+
+// FormatDate implements the FORMAT_DATE operation.
+func FormatDate(s *runtime.Session, source runtime.Value, layout string) (runtime.Value, error) {
+	if layout == "" || layout == "AUTO" {
+		return runtime.Value{}, fmt.Errorf("FORMAT_DATE: LAYOUT must name an output format (e.g. \"ISO\" or \"YYYY-MM-DD\")")
+	}
+	t := source.V.(time.Time)
+	return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(formatLayout(t, splitLayout(layout)))}, nil
+}
+
+var dateUnits = map[string]time.Duration{
+	"SECONDS": time.Second,
+	"MINUTES": time.Minute,
+	"HOURS":   time.Hour,
+	"DAYS":    24 * time.Hour,
+	"WEEKS":   7 * 24 * time.Hour,
+}
+
+// DateDiff implements the DATE_DIFF operation: whole UNITs from FROM to TO,
+// negative when TO is earlier and truncated toward zero.
+func DateDiff(s *runtime.Session, from, to runtime.Value, unit string) (runtime.Value, error) {
+	d, ok := dateUnits[unit]
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("DATE_DIFF: unknown unit %q", unit)
+	}
+	diff := to.V.(time.Time).Sub(from.V.(time.Time))
+	return runtime.Value{Kind: runtime.KindInt, V: int(diff / d)}, nil
+}
+
+// DateAdd implements the DATE_ADD operation. MONTHS and YEARS follow the
+// calendar (Jan 31 + 1 month normalises to early March, as in time.AddDate).
+func DateAdd(s *runtime.Session, source runtime.Value, amount int, unit string) (runtime.Value, error) {
+	t := source.V.(time.Time)
+	switch unit {
+	case "MONTHS":
+		t = t.AddDate(0, amount, 0)
+	case "YEARS":
+		t = t.AddDate(amount, 0, 0)
+	default:
+		d, ok := dateUnits[unit]
+		if !ok {
+			return runtime.Value{}, fmt.Errorf("DATE_ADD: unknown unit %q", unit)
+		}
+		t = t.Add(time.Duration(amount) * d)
+	}
+	return runtime.Value{Kind: runtime.KindTimestamp, V: t}, nil
+}
+
+// DateCompare implements the DATE_COMPARE operation, returning -1, 0 or 1.
+func DateCompare(s *runtime.Session, a, b runtime.Value) (runtime.Value, error) {
+	return runtime.Value{Kind: runtime.KindInt, V: a.V.(time.Time).Compare(b.V.(time.Time))}, nil
+}
+
+// Now implements the NOW operation using the session clock.
+func Now(s *runtime.Session) (runtime.Value, error) {
+	return runtime.Value{Kind: runtime.KindTimestamp, V: s.Now()}, nil
+}
+
+// asTime interprets a row cell as a timestamp: either a time.Time or a string
+// in one of the auto-detected formats.
+func asTime(v interface{}, now time.Time) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case runtime.Value:
+		return asTime(t.V, now)
+	case string:
+		parsed, err := parseDateAuto(t, now)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
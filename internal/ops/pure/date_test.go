@@ -0,0 +1,174 @@
+package pure
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestParseDate(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	s.Clock = func() time.Time { return time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC) }
+	text := func(str string) runtime.Value {
+		return runtime.Value{Kind: runtime.KindText, V: ts.Add(str)}
+	}
+
+	tests := []struct {
+		in, layout string
+		want       string
+	}{
+		{"2025-03-04", "AUTO", "2025-03-04T00:00:00Z"},
+		{"2025-03-04T10:20:30+02:00", "AUTO", "2025-03-04T10:20:30+02:00"},
+		{"March 4, 2025", "AUTO", "2025-03-04T00:00:00Z"},
+		{"4 Mar 2025", "AUTO", "2025-03-04T00:00:00Z"},
+		{"04.03.2025", "AUTO", "2025-03-04T00:00:00Z"},
+		{"25/03/2025", "AUTO", "2025-03-25T00:00:00Z"},
+		{"03/25/2025", "AUTO", "2025-03-25T00:00:00Z"},
+		{"yesterday", "AUTO", "2025-03-09T00:00:00Z"},
+		{"now", "", "2025-03-10T15:30:00Z"},
+		{"03/04/2025", "DD/MM/YYYY", "2025-04-03T00:00:00Z"},
+		{"2025-03-04 10:20", "YYYY-MM-DD HH:mm", "2025-03-04T10:20:00Z"},
+		{"04 Mar 25", "02 Jan 06", "2025-03-04T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		res, err := ParseDate(s, text(tt.in), tt.layout)
+		if err != nil {
+			t.Errorf("%q (%s): unexpected error: %v", tt.in, tt.layout, err)
+			continue
+		}
+		if got := res.V.(time.Time).Format(time.RFC3339); res.Kind != runtime.KindTimestamp || got != tt.want {
+			t.Errorf("%q (%s): expected %s, got %s %s", tt.in, tt.layout, tt.want, res.Kind, got)
+		}
+	}
+
+	if _, err := ParseDate(s, text("03/04/2025"), "AUTO"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguity error, got %v", err)
+	}
+	if _, err := ParseDate(s, text("sometime"), "AUTO"); err == nil {
+		t.Error("expected error for unrecognised date")
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	at := func(y int, m time.Month, d int) runtime.Value {
+		return runtime.Value{Kind: runtime.KindTimestamp, V: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+	}
+
+	if res, _ := DateDiff(s, at(2025, 1, 31), at(2025, 3, 1), "DAYS"); res.V != 29 {
+		t.Errorf("DATE_DIFF DAYS: expected 29, got %v", res.V)
+	}
+	if res, _ := DateDiff(s, at(2025, 3, 1), at(2025, 1, 31), "WEEKS"); res.V != -4 {
+		t.Errorf("DATE_DIFF WEEKS: expected -4, got %v", res.V)
+	}
+	if res, _ := DateAdd(s, at(2024, 2, 29), 1, "YEARS"); !res.V.(time.Time).Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DATE_ADD YEARS: got %v", res.V)
+	}
+	if res, _ := DateAdd(s, at(2025, 1, 1), -36, "HOURS"); !res.V.(time.Time).Equal(time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("DATE_ADD HOURS: got %v", res.V)
+	}
+	if res, _ := DateCompare(s, at(2025, 1, 1), at(2024, 1, 1)); res.V != 1 {
+		t.Errorf("DATE_COMPARE: expected 1, got %v", res.V)
+	}
+	res, _ := FormatDate(s, at(2025, 3, 4), "DD.MM.YYYY")
+	if got, _ := ts.Get(res.V.(runtime.TextHandle)); got != "04.03.2025" {
+		t.Errorf("FORMAT_DATE: expected 04.03.2025, got %q", got)
+	}
+}
+
+func TestRows_Dates(t *testing.T) {
+	s := runtime.NewSession(runtime.Policy{}, &mockTextStore{content: make(map[string]string)})
+	rows := runtime.Value{Kind: runtime.KindRows, V: []map[string]interface{}{
+		{"id": 1, "due": "March 5, 2025"},
+		{"id": 2, "due": "2025-01-15"},
+		{"id": 3},
+		{"id": 4, "due": "04.02.2025"},
+	}}
+	cutoff := runtime.Value{Kind: runtime.KindTimestamp, V: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}
+
+	res, err := FilterRows(s, rows, "due", ">", cutoff)
+	if err != nil {
+		t.Fatalf("FILTER_ROWS failed: %v", err)
+	}
+	if got := ids(res); got != "1,4" {
+		t.Errorf("FILTER_ROWS >: expected 1,4, got %s", got)
+	}
+
+	res, _ = SortRows(s, rows, "due", "ASC")
+	if got := ids(res); got != "2,4,1,3" {
+		t.Errorf("SORT_ROWS ASC: expected 2,4,1,3, got %s", got)
+	}
+	res, _ = SortRows(s, rows, "id", "DESC")
+	if got := ids(res); got != "4,3,2,1" {
+		t.Errorf("SORT_ROWS DESC: expected 4,3,2,1, got %s", got)
+	}
+}
+
+func ids(v runtime.Value) string {
+	var parts []string
+	for _, row := range v.V.([]map[string]interface{}) {
+		parts = append(parts, fmt.Sprint(row["id"]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestDateLayouts(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	when := time.Date(2025, 3, 4, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct{ layout, text string }{
+		{"YYYY-MM-DDTHH:mm:ssZ", "2025-03-04T09:05:00Z"},
+		{"Date: D MMMM YYYY", "Date: 4 March 2025"},
+		{"DD.MM.YYYY HH:mm UTC", "04.03.2025 09:05 UTC"},
+		{"'Day' D 'of' MMM, YYYY", "Day 4 of Mar, 2025"},
+		{"'Q1' YYYY-MM-DD", "Q1 2025-03-04"},
+		{"D MMM ''YY", "4 Mar '25"},
+		{"'It''s' YYYY", "It's 2025"},
+	}
+	for _, tt := range tests {
+		res, err := FormatDate(s, runtime.Value{Kind: runtime.KindTimestamp, V: when}, tt.layout)
+		if err != nil {
+			t.Errorf("FORMAT_DATE %q: %v", tt.layout, err)
+			continue
+		}
+		if got, _ := ts.Get(res.V.(runtime.TextHandle)); got != tt.text {
+			t.Errorf("FORMAT_DATE %q: expected %q, got %q", tt.layout, tt.text, got)
+		}
+	}
+
+	for _, tt := range tests {
+		res, err := ParseDate(s, runtime.Value{Kind: runtime.KindText, V: ts.Add(tt.text)}, tt.layout)
+		if err != nil {
+			t.Errorf("PARSE_DATE %q: %v", tt.layout, err)
+			continue
+		}
+		back, _ := FormatDate(s, res, tt.layout)
+		if got, _ := ts.Get(back.V.(runtime.TextHandle)); got != tt.text {
+			t.Errorf("PARSE_DATE %q: %q read back as %q", tt.layout, tt.text, got)
+		}
+	}
+	if _, err := ParseDate(s, runtime.Value{Kind: runtime.KindText, V: ts.Add("Day 4 on Mar, 2025")}, "'Day' D 'of' MMM, YYYY"); err == nil {
+		t.Error("PARSE_DATE: expected error for mismatched literal")
+	}
+}
+
+func TestSortRows_Text(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	// Handles are created in the reverse of alphabetical order.
+	rows := runtime.Value{Kind: runtime.KindRows, V: []map[string]interface{}{
+		{"id": 1, "name": ts.Add("cherry")},
+		{"id": 2, "name": runtime.Value{Kind: runtime.KindText, V: ts.Add("banana")}},
+		{"id": 3, "name": ts.Add("apple")},
+	}}
+	res, _ := SortRows(s, rows, "name", "ASC")
+	if got := ids(res); got != "3,2,1" {
+		t.Errorf("SORT_ROWS TEXT: expected 3,2,1, got %s", got)
+	}
+}
@@ -0,0 +1,232 @@
+package pure
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+const (
+	// diffMaxTokens bounds the number of lines or words per input.
+	diffMaxTokens = 200000
+	// diffMaxEdits bounds the edit distance the diff searches for. Beyond
+	// it the differing middle is reported as one coarse replace hunk.
+	diffMaxEdits = 2000
+)
+
+// diffToken is a line or word of a diff input, by byte range.
+type diffToken struct{ start, end int }
+
+// tokenizeLines splits text into lines, each including its terminator.
+func tokenizeLines(text string) []diffToken {
+	var toks []diffToken
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			toks = append(toks, diffToken{start, i + 1})
+			start = i + 1
+		}
+	}
+	if start < len(text) {
+		toks = append(toks, diffToken{start, len(text)})
+	}
+	return toks
+}
+
+// tokenizeWords splits text into alternating runs of whitespace and
+// non-whitespace, so the tokens cover the text exactly.
+func tokenizeWords(text string) []diffToken {
+	var toks []diffToken
+	start, space := 0, false
+	for i, r := range text {
+		sp := unicode.IsSpace(r)
+		if i > start && sp != space {
+			toks = append(toks, diffToken{start, i})
+			start = i
+		}
+		space = sp
+	}
+	if start < len(text) {
+		toks = append(toks, diffToken{start, len(text)})
+	}
+	return toks
+}
+
+// internTokens maps equal token texts in both inputs to equal ids.
+func internTokens(a, b string, ta, tb []diffToken) ([]int, []int) {
+	ids := map[string]int{}
+	conv := func(text string, toks []diffToken) []int {
+		out := make([]int, len(toks))
+		for i, t := range toks {
+			w := text[t.start:t.end]
+			id, ok := ids[w]
+			if !ok {
+				id = len(ids)
+				ids[w] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	return conv(a, ta), conv(b, tb)
+}
+
+// myersMatches returns, for the shortest edit script between a and b, the
+// index pairs of tokens that are kept, in order. ok is false when the edit
+// distance exceeds maxD.
+func myersMatches(a, b []int, maxD int) (matches [][2]int, ok bool) {
+	n, m := len(a), len(b)
+	limit := min(maxD, n+m)
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int // trace[d] holds v[-d..d] before step d
+
+	for d := 0; d <= limit; d++ {
+		snap := make([]int, 2*d+1)
+		copy(snap, v[off-d:off+d+1])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(trace [][]int, x, y int) [][2]int {
+	var rev [][2]int
+	for d := len(trace) - 1; d > 0; d-- {
+		at := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, [2]int{x, y})
+	}
+
+	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
+		rev[i], rev[j] = rev[j], rev[i]
+	}
+	return rev
+}
+
+// DiffText implements the DIFF_TEXT operation.
+// GRANULARITY is LINE or WORD. The result has one row per hunk: kind
+// ("insert", "delete" or "replace"), a_span and b_span (byte spans in A and
+// B; empty at the edit position for pure inserts and deletes), a_text and
+// b_text (TEXT slices), a_line and b_line (1-based line where the hunk
+// starts), and coarse. The diff is a Myers shortest edit script, so the
+// output is deterministic. If the inputs differ by more than the edit budget,
+// everything between their common prefix and suffix is reported as a single
+// replace hunk with coarse set to true.
+func DiffText(s *runtime.Session, a, b runtime.Value, granularity string) (runtime.Value, error) {
+	ha, hb := a.V.(runtime.TextHandle), b.V.(runtime.TextHandle)
+	ta, _ := s.Stores.Text.Get(ha)
+	tb, _ := s.Stores.Text.Get(hb)
+
+	tokenize := tokenizeLines
+	switch granularity {
+	case "LINE":
+	case "WORD":
+		tokenize = tokenizeWords
+	default:
+		return runtime.Value{}, fmt.Errorf("DIFF_TEXT: unsupported GRANULARITY %q (want LINE or WORD)", granularity)
+	}
+	toksA, toksB := tokenize(ta), tokenize(tb)
+	if len(toksA) > diffMaxTokens || len(toksB) > diffMaxTokens {
+		return runtime.Value{}, fmt.Errorf("DIFF_TEXT: inputs too large (%d and %d tokens, limit %d)", len(toksA), len(toksB), diffMaxTokens)
+	}
+	idsA, idsB := internTokens(ta, tb, toksA, toksB)
+
+	// Trim the common prefix and suffix before searching.
+	pre := 0
+	for pre < len(idsA) && pre < len(idsB) && idsA[pre] == idsB[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(idsA)-pre && suf < len(idsB)-pre && idsA[len(idsA)-1-suf] == idsB[len(idsB)-1-suf] {
+		suf++
+	}
+	midA, midB := idsA[pre:len(idsA)-suf], idsB[pre:len(idsB)-suf]
+
+	matches, ok := myersMatches(midA, midB, diffMaxEdits)
+	for i := range matches {
+		matches[i][0] += pre
+		matches[i][1] += pre
+	}
+	// A sentinel match at the end of both inputs closes the last hunk.
+	matches = append(matches, [2]int{len(idsA) - suf, len(idsB) - suf})
+
+	// pos returns the byte offset of token i (or the end of the text).
+	pos := func(toks []diffToken, text string, i int) int {
+		if i < len(toks) {
+			return toks[i].start
+		}
+		return len(text)
+	}
+	linesA, linesB := newLineIndex(ta), newLineIndex(tb)
+
+	rows := []map[string]interface{}{}
+	i, j := pre, pre
+	for _, mt := range matches {
+		if mt[0] > i || mt[1] > j {
+			kind := "replace"
+			switch {
+			case mt[0] == i:
+				kind = "insert"
+			case mt[1] == j:
+				kind = "delete"
+			}
+			spA := runtime.Span{Start: pos(toksA, ta, i), End: pos(toksA, ta, mt[0]), Source: ha.ID}
+			spB := runtime.Span{Start: pos(toksB, tb, j), End: pos(toksB, tb, mt[1]), Source: hb.ID}
+			textA, err := sliceValue(s, ha, spA.Start, spA.End)
+			if err != nil {
+				return runtime.Value{}, fmt.Errorf("DIFF_TEXT failed: %v", err)
+			}
+			textB, err := sliceValue(s, hb, spB.Start, spB.End)
+			if err != nil {
+				return runtime.Value{}, fmt.Errorf("DIFF_TEXT failed: %v", err)
+			}
+			rows = append(rows, map[string]interface{}{
+				"kind":   kind,
+				"a_span": spA,
+				"b_span": spB,
+				"a_text": textA,
+				"b_text": textB,
+				"a_line": linesA.lineOf(spA.Start),
+				"b_line": linesB.lineOf(spB.Start),
+				"coarse": !ok,
+			})
+		}
+		i, j = mt[0]+1, mt[1]+1
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
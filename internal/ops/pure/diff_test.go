@@ -0,0 +1,57 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestDiffText(t *testing.T) {
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	text := func(v interface{}) string {
+		got, _ := ts.Get(v.(runtime.Value).V.(runtime.TextHandle))
+		return got
+	}
+	diff := func(a, b, gran string) []map[string]interface{} {
+		t.Helper()
+		res, err := DiffText(s, runtime.Value{Kind: runtime.KindText, V: ts.Add(a)}, runtime.Value{Kind: runtime.KindText, V: ts.Add(b)}, gran)
+		if err != nil {
+			t.Fatalf("DIFF_TEXT failed: %v", err)
+		}
+		return res.V.([]map[string]interface{})
+	}
+
+	a := "name: app\nport: 80\nmode: dev\ndebug: true\n"
+	b := "name: app\nport: 8080\nmode: dev\nlog: info\n"
+	hunks := diff(a, b, "LINE")
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %v", hunks)
+	}
+	if hunks[0]["kind"] != "replace" || text(hunks[0]["a_text"]) != "port: 80\n" || text(hunks[0]["b_text"]) != "port: 8080\n" || hunks[0]["a_line"] != 2 {
+		t.Errorf("unexpected first hunk: %v", hunks[0])
+	}
+	if sp := hunks[1]["b_span"].(runtime.Span); b[sp.Start:sp.End] != "log: info\n" || hunks[1]["coarse"] != false {
+		t.Errorf("unexpected second hunk: %v", hunks[1])
+	}
+
+	hunks = diff("the quick brown fox", "the brown fox jumps", "WORD")
+	if len(hunks) != 2 || hunks[0]["kind"] != "delete" || text(hunks[0]["a_text"]) != "quick " || hunks[1]["kind"] != "insert" || text(hunks[1]["b_text"]) != " jumps" {
+		t.Errorf("unexpected word hunks: %v", hunks)
+	}
+	if sp := hunks[1]["a_span"].(runtime.Span); sp.Start != sp.End || sp.Start != len("the quick brown fox") {
+		t.Errorf("insert should have an empty A span at the end, got %v", sp)
+	}
+
+	if hunks := diff(a, a, "LINE"); len(hunks) != 0 {
+		t.Errorf("identical inputs should have no hunks, got %v", hunks)
+	}
+
+	if _, ok := myersMatches([]int{1, 2, 3, 4}, []int{5, 6, 7, 8}, 3); ok {
+		t.Error("expected the edit budget to be exceeded")
+	}
+	if m, ok := myersMatches([]int{1, 2, 3, 4}, []int{1, 9, 3, 4}, 10); !ok || len(m) != 3 {
+		t.Errorf("unexpected matches: %v", m)
+	}
+}
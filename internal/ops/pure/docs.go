@@ -0,0 +1,78 @@
+package pure
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// docsItems returns the items of a DOCS value.
+func docsItems(op string, source runtime.Value) ([]map[string]interface{}, error) {
+	docs, ok := source.V.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: SOURCE must be DOCS, got %s", op, source.Kind)
+	}
+	return docs, nil
+}
+
+// GrepDocs implements the GREP_DOCS operation.
+// It runs GREP_LINES over the text of every document in SOURCE and returns
+// the rows in document order, each extended with doc_id and doc_index (0-based)
+// naming the document the line came from. Spans refer to that document's
+// TEXT.
+func GrepDocs(s *runtime.Session, source runtime.Value, pattern runtime.Value, context int) (runtime.Value, error) {
+	docs, err := docsItems("GREP_DOCS", source)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	pat, _ := s.Stores.Text.Get(pattern.V.(runtime.TextHandle))
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("GREP_DOCS invalid pattern %q: %v", pat, err)
+	}
+	if context < 0 {
+		return runtime.Value{}, fmt.Errorf("GREP_DOCS: CONTEXT must be >= 0, got %d", context)
+	}
+
+	rows := []map[string]interface{}{}
+	for i, doc := range docs {
+		h, ok := runtime.DocText(doc)
+		if !ok {
+			return runtime.Value{}, fmt.Errorf("GREP_DOCS: document %d has no TEXT", i)
+		}
+		text, _ := s.Stores.Text.Get(h)
+		found, err := grepRows(s, h, text, re, context)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("GREP_DOCS failed: %v", err)
+		}
+		for _, r := range found {
+			for _, k := range []string{"span", "match_span", "context_span"} {
+				sp := r[k].(runtime.Span)
+				sp.Source = h.ID
+				r[k] = sp
+			}
+			r["doc_id"] = doc["id"]
+			r["doc_index"] = i
+			rows = append(rows, r)
+		}
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
+
+// GetDoc implements the GET_DOC operation.
+// It returns the document of SOURCE with the given ID as a STRUCT with id,
+// metadata and text.
+func GetDoc(s *runtime.Session, source runtime.Value, id runtime.Value) (runtime.Value, error) {
+	docs, err := docsItems("GET_DOC", source)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	want, _ := s.Stores.Text.Get(id.V.(runtime.TextHandle))
+	for _, doc := range docs {
+		if doc["id"] == want {
+			return runtime.Value{Kind: runtime.KindStruct, V: doc}, nil
+		}
+	}
+	return runtime.Value{}, fmt.Errorf("GET_DOC: no document with id %q", want)
+}
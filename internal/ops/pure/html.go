@@ -0,0 +1,118 @@
+package pure
+
+import (
+	"fmt"
+
+	"github.com/agenthands/envllm/internal/htmldoc"
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// HtmlToText implements the HTML_TO_TEXT operation.
+// It returns a STRUCT with text (a new TEXT handle holding the readable text)
+// and segments, ROWS mapping ranges of that text back to the HTML source:
+// text_start, text_end, source_start, source_end and exact (true when the
+// two ranges correspond byte for byte), plus source, the ID of the HTML TEXT.
+func HtmlToText(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	src, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	text, segs := htmldoc.Text(htmldoc.Parse(src))
+
+	rows := make([]map[string]interface{}, 0, len(segs))
+	for _, sg := range segs {
+		rows = append(rows, map[string]interface{}{
+			"text_start":   sg.TextStart,
+			"text_end":     sg.TextEnd,
+			"source_start": sg.SourceStart,
+			"source_end":   sg.SourceEnd,
+			"exact":        sg.Exact,
+		})
+	}
+	return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{
+		"text":     runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(text)},
+		"segments": runtime.Value{Kind: runtime.KindRows, V: rows},
+		"source":   source.V.(runtime.TextHandle).ID,
+	}}, nil
+}
+
+// HtmlSourceSpan implements the HTML_SOURCE_SPAN operation.
+// MAP is a result of HTML_TO_TEXT; SPAN is a span in its text. The result is
+// the span of the HTML source the text came from.
+func HtmlSourceSpan(s *runtime.Session, m runtime.Value, span runtime.Value) (runtime.Value, error) {
+	obj, ok := m.V.(map[string]interface{})
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("HTML_SOURCE_SPAN: MAP must be a result of HTML_TO_TEXT")
+	}
+	var rows []map[string]interface{}
+	switch v := obj["segments"].(type) {
+	case runtime.Value:
+		rows, _ = v.V.([]map[string]interface{})
+	case []map[string]interface{}:
+		rows = v
+	}
+	if rows == nil {
+		return runtime.Value{}, fmt.Errorf("HTML_SOURCE_SPAN: MAP has no segments")
+	}
+
+	segs := make([]htmldoc.Segment, 0, len(rows))
+	for _, r := range rows {
+		var sg htmldoc.Segment
+		for key, dst := range map[string]*int{
+			"text_start": &sg.TextStart, "text_end": &sg.TextEnd,
+			"source_start": &sg.SourceStart, "source_end": &sg.SourceEnd,
+		} {
+			f, ok := toFloat(r[key])
+			if !ok {
+				return runtime.Value{}, fmt.Errorf("HTML_SOURCE_SPAN: segment field %q is not a number", key)
+			}
+			*dst = int(f)
+		}
+		sg.Exact, _ = r["exact"].(bool)
+		segs = append(segs, sg)
+	}
+
+	sp := span.V.(runtime.Span)
+	if tv, ok := obj["text"].(runtime.Value); ok && sp.Source != "" {
+		if h, ok := tv.V.(runtime.TextHandle); ok && h.ID != sp.Source {
+			return runtime.Value{}, fmt.Errorf("HTML_SOURCE_SPAN: SPAN is from TEXT %s, not the extracted text %s", sp.Source, h.ID)
+		}
+	}
+	start, end, ok := htmldoc.SourceSpan(segs, sp.Start, sp.End)
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("HTML_SOURCE_SPAN: span %d..%d covers no extracted text", sp.Start, sp.End)
+	}
+	src, _ := obj["source"].(string)
+	return runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: start, End: end, Source: src}}, nil
+}
+
+// HtmlSelect implements the HTML_SELECT operation.
+// Each element matching the CSS selector becomes a row with tag, text (its
+// readable text), attrs (a map of attribute values), span (the element
+// including its tags) and inner_span, in document order.
+func HtmlSelect(s *runtime.Session, source runtime.Value, selector runtime.Value) (runtime.Value, error) {
+	src, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	selText, _ := s.Stores.Text.Get(selector.V.(runtime.TextHandle))
+
+	sel, err := htmldoc.Compile(selText)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("HTML_SELECT: %v", err)
+	}
+
+	nodes := htmldoc.Select(htmldoc.Parse(src), sel)
+	rows := make([]map[string]interface{}, 0, len(nodes))
+	for _, n := range nodes {
+		attrs := make(map[string]interface{}, len(n.Attrs))
+		for _, a := range n.Attrs {
+			if _, dup := attrs[a.Key]; !dup {
+				attrs[a.Key] = a.Val
+			}
+		}
+		text, _ := htmldoc.Text(n)
+		rows = append(rows, map[string]interface{}{
+			"tag":        n.Tag,
+			"text":       text,
+			"attrs":      attrs,
+			"span":       runtime.Span{Start: n.Start, End: n.End},
+			"inner_span": runtime.Span{Start: n.InnerStart, End: n.InnerEnd},
+		})
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
@@ -0,0 +1,54 @@
+package pure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestHtmlOps(t *testing.T) {
+	const page = `<body><h1>Prices</h1><p class="note">Total: <b>&euro;42</b></p><a href="/buy">Buy</a></body>`
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	src := runtime.Value{Kind: runtime.KindText, V: ts.Add(page)}
+	sel := func(q string) runtime.Value { return runtime.Value{Kind: runtime.KindText, V: ts.Add(q)} }
+
+	res, err := HtmlToText(s, src)
+	if err != nil {
+		t.Fatalf("HTML_TO_TEXT failed: %v", err)
+	}
+	obj := res.V.(map[string]interface{})
+	text, _ := ts.Get(obj["text"].(runtime.Value).V.(runtime.TextHandle))
+	if text != "Prices\nTotal: €42\nBuy" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+
+	// Map "€42" back to the markup it came from.
+	start := strings.Index(text, "€")
+	span := runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: start, End: start + len("€42")}}
+	res, err = HtmlSourceSpan(s, runtime.Value{Kind: runtime.KindStruct, V: obj}, span)
+	if err != nil {
+		t.Fatalf("HTML_SOURCE_SPAN failed: %v", err)
+	}
+	if sp := res.V.(runtime.Span); page[sp.Start:sp.End] != "&euro;42" {
+		t.Errorf("unexpected source span: %q", page[sp.Start:sp.End])
+	}
+
+	res, err = HtmlSelect(s, src, sel("p.note, a[href]"))
+	if err != nil {
+		t.Fatalf("HTML_SELECT failed: %v", err)
+	}
+	rows := res.V.([]map[string]interface{})
+	if len(rows) != 2 || rows[0]["tag"] != "p" || rows[0]["text"] != "Total: €42" || rows[1]["attrs"].(map[string]interface{})["href"] != "/buy" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	if sp := rows[1]["inner_span"].(runtime.Span); page[sp.Start:sp.End] != "Buy" {
+		t.Errorf("unexpected inner span: %q", page[sp.Start:sp.End])
+	}
+
+	if _, err := HtmlSelect(s, src, sel("a:first-child")); err == nil || !strings.HasPrefix(err.Error(), "HTML_SELECT") {
+		t.Errorf("expected HTML_SELECT error for pseudo-class, got %v", err)
+	}
+}
@@ -3,7 +3,8 @@ package pure
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
+	"math"
+	"time"
 
 	"github.com/agenthands/envllm/internal/runtime"
 )
@@ -21,58 +22,201 @@ func JSONParse(s *runtime.Session, source runtime.Value) (runtime.Value, error)
 	return runtime.Value{Kind: runtime.KindJSON, V: v}, nil
 }
 
-// ExtractJSON isolates and parses the first JSON object found in text.
+// ExtractJSON isolates and parses the first JSON object found in text, or the
+// first array when there is no object, so a citation like "[1]" ahead of the
+// payload is passed over. Values that only parse after repair (single quotes,
+// trailing commas) are used when no strictly valid one is present. FIND_JSON
+// exposes the mode, the tolerance and the source span.
 func ExtractJSON(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
 	h := source.V.(runtime.TextHandle)
 	text, _ := s.Stores.Text.Get(h)
 
-	start := strings.Index(text, "{")
-	end := strings.LastIndex(text, "}")
-
-	if start == -1 || end == -1 || end <= start {
-		return runtime.Value{}, fmt.Errorf("EXTRACT_JSON: no JSON object found in text")
+	found, err := scanJSON(text, false)
+	if firstObject(found) < 0 {
+		if loose, lerr := scanJSON(text, true); len(found) == 0 || firstObject(loose) >= 0 {
+			found, err = loose, lerr
+		}
 	}
-
-	jsonStr := text[start : end+1]
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+	if err != nil {
 		return runtime.Value{}, fmt.Errorf("EXTRACT_JSON failed to parse: %v", err)
 	}
+	if len(found) == 0 {
+		return runtime.Value{}, fmt.Errorf("EXTRACT_JSON: no JSON object or array found in text")
+	}
+
+	return runtime.Value{Kind: runtime.KindJSON, V: found[max(firstObject(found), 0)].value}, nil
+}
 
-	return runtime.Value{Kind: runtime.KindJSON, V: data}, nil
+// firstObject returns the index of the first candidate that is a JSON object,
+// or -1.
+func firstObject(found []jsonCandidate) int {
+	for i, c := range found {
+		if _, ok := c.value.(map[string]interface{}); ok {
+			return i
+		}
+	}
+	return -1
 }
 
 // JSONGet implements the JSON_GET operation.
-// Simplistic implementation for now: only works for maps.
+// The path language is described in jsonpath.go. Definite paths return the
+// selected node converted to its natural kind (see FromJSON); paths containing
+// wildcards, filters or recursive descent return a LIST of all matches.
 func JSONGet(s *runtime.Session, source runtime.Value, path string) (runtime.Value, error) {
-	data := source.V
-	
-	// Strip optional $. prefix (common LLM hallucination)
-	path = strings.TrimPrefix(path, "$.")
-	
-	parts := strings.Split(path, ".")
-	curr := data
-	
-	for _, part := range parts {
-		if part == "" {
-			continue
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_GET invalid path %q: %v", path, err)
+	}
+
+	nodes, err := evalJSONPath(source.V, segs)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+
+	if !definite(segs) {
+		list := make([]runtime.Value, 0, len(nodes))
+		for _, n := range nodes {
+			list = append(list, FromJSON(s, n))
 		}
-		m, ok := curr.(map[string]interface{})
+		return runtime.Value{Kind: runtime.KindList, V: list}, nil
+	}
+	return FromJSON(s, nodes[0]), nil
+}
+
+// FromJSON converts a decoded JSON node into a typed runtime Value:
+// objects become STRUCT, arrays of objects ROWS, other arrays LIST, strings
+// TEXT, integral numbers INT, other numbers FLOAT, booleans BOOL and null NULL.
+func FromJSON(s *runtime.Session, v interface{}) runtime.Value {
+	switch n := v.(type) {
+	case nil:
+		return runtime.Value{Kind: runtime.KindNull, V: nil}
+	case runtime.Value:
+		return n
+	case bool:
+		return runtime.Value{Kind: runtime.KindBool, V: n}
+	case string:
+		return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(n)}
+	case int:
+		return runtime.Value{Kind: runtime.KindInt, V: n}
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) <= 1<<53 {
+			return runtime.Value{Kind: runtime.KindInt, V: int(n)}
+		}
+		return runtime.Value{Kind: runtime.KindFloat, V: n}
+	case map[string]interface{}:
+		return runtime.Value{Kind: runtime.KindStruct, V: n}
+	case []map[string]interface{}:
+		return runtime.Value{Kind: runtime.KindRows, V: n}
+	case []runtime.Value:
+		return runtime.Value{Kind: runtime.KindList, V: n}
+	case []interface{}:
+		if rows, ok := asRows(n); ok {
+			return runtime.Value{Kind: runtime.KindRows, V: rows}
+		}
+		list := make([]runtime.Value, 0, len(n))
+		for _, e := range n {
+			list = append(list, FromJSON(s, e))
+		}
+		return runtime.Value{Kind: runtime.KindList, V: list}
+	default:
+		return runtime.Value{Kind: runtime.KindJSON, V: v}
+	}
+}
+
+// asRows reports whether arr is a non-empty array made up only of objects.
+func asRows(arr []interface{}) ([]map[string]interface{}, bool) {
+	if len(arr) == 0 {
+		return nil, false
+	}
+	rows := make([]map[string]interface{}, 0, len(arr))
+	for _, e := range arr {
+		m, ok := e.(map[string]interface{})
 		if !ok {
-			return runtime.Value{}, fmt.Errorf("JSON_GET failed: current value is not a map")
+			return nil, false
+		}
+		rows = append(rows, m)
+	}
+	return rows, true
+}
+
+// ToJSON converts a runtime Value into plain JSON data (maps, slices, strings,
+// numbers, booleans and nil), resolving TEXT handles through the store.
+func ToJSON(s *runtime.Session, v runtime.Value) (interface{}, error) {
+	return plainJSON(s, v)
+}
+
+func plainJSON(s *runtime.Session, v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case runtime.Value:
+		if n.Kind == runtime.KindNull {
+			return nil, nil
 		}
-		val, ok := m[part]
+		return plainJSON(s, n.V)
+	case runtime.TextHandle:
+		text, ok := s.Stores.Text.Get(n)
 		if !ok {
-			// Provide available keys hint
-			keys := make([]string, 0, len(m))
-			for k := range m {
-				keys = append(keys, k)
+			return nil, fmt.Errorf("text handle %s not found", n.ID)
+		}
+		return text, nil
+	case runtime.Span:
+		return map[string]interface{}{"start": n.Start, "end": n.End}, nil
+	case []runtime.Span:
+		out := make([]interface{}, len(n))
+		for i, sp := range n {
+			out[i] = map[string]interface{}{"start": sp.Start, "end": sp.End}
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(n))
+		for k, e := range n {
+			pv, err := plainJSON(s, e)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = pv
+		}
+		return out, nil
+	case []map[string]interface{}:
+		out := make([]interface{}, len(n))
+		for i, e := range n {
+			pv, err := plainJSON(s, e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = pv
+		}
+		return out, nil
+	case []runtime.Value:
+		out := make([]interface{}, len(n))
+		for i, e := range n {
+			pv, err := plainJSON(s, e)
+			if err != nil {
+				return nil, err
 			}
-			return runtime.Value{}, fmt.Errorf("JSON_GET failed: key %q not found. Available keys: %v", part, keys)
+			out[i] = pv
 		}
-		curr = val
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(n))
+		for i, e := range n {
+			pv, err := plainJSON(s, e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = pv
+		}
+		return out, nil
+	case []string:
+		out := make([]interface{}, len(n))
+		for i, e := range n {
+			out[i] = e
+		}
+		return out, nil
+	case nil, bool, string, int, float64:
+		return n, nil
+	case time.Time:
+		return n.Format(time.RFC3339Nano), nil
+	default:
+		return nil, fmt.Errorf("value of type %T cannot be represented as JSON", v)
 	}
-
-	// Wrap result in Value
-	return runtime.Value{Kind: runtime.KindJSON, V: curr}, nil
 }
@@ -0,0 +1,182 @@
+package pure
+
+import (
+	"fmt"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// Values passed to the JSON construction ops are converted with ToJSON:
+// TEXT and STRING become strings, INT/OFFSET/COST numbers, BOOL booleans,
+// NULL null, SPAN {"start", "end"}, ROWS an array of objects, LIST an array
+// and STRUCT/JSON are copied as-is.
+
+// JSONObject implements the JSON_OBJECT operation.
+func JSONObject(s *runtime.Session, keys runtime.Value, values runtime.Value) (runtime.Value, error) {
+	ks, ok := keys.V.([]runtime.Value)
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("JSON_OBJECT: KEYS must be a LIST, got %s", keys.Kind)
+	}
+	vs, ok := values.V.([]runtime.Value)
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("JSON_OBJECT: VALUES must be a LIST, got %s", values.Kind)
+	}
+	if len(ks) != len(vs) {
+		return runtime.Value{}, fmt.Errorf("JSON_OBJECT: got %d keys but %d values", len(ks), len(vs))
+	}
+
+	obj := make(map[string]interface{}, len(ks))
+	for i, k := range ks {
+		name, err := keyName(s, k)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("JSON_OBJECT: key %d: %v", i, err)
+		}
+		if _, dup := obj[name]; dup {
+			return runtime.Value{}, fmt.Errorf("JSON_OBJECT: duplicate key %q", name)
+		}
+		v, err := ToJSON(s, vs[i])
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("JSON_OBJECT: value for %q: %v", name, err)
+		}
+		obj[name] = v
+	}
+	return runtime.Value{Kind: runtime.KindStruct, V: obj}, nil
+}
+
+// JSONArray implements the JSON_ARRAY operation.
+func JSONArray(s *runtime.Session, items runtime.Value) (runtime.Value, error) {
+	arr, err := ToJSON(s, items)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_ARRAY: %v", err)
+	}
+	if _, ok := arr.([]interface{}); !ok {
+		return runtime.Value{}, fmt.Errorf("JSON_ARRAY: ITEMS must be a LIST or ROWS, got %s", items.Kind)
+	}
+	return runtime.Value{Kind: runtime.KindJSON, V: arr}, nil
+}
+
+// JSONSet implements the JSON_SET operation. It returns a copy of source with
+// the value at path replaced. Missing object keys along the path are created;
+// an index equal to the array length appends.
+func JSONSet(s *runtime.Session, source runtime.Value, path string, value runtime.Value) (runtime.Value, error) {
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_SET invalid path %q: %v", path, err)
+	}
+	if !definite(segs) {
+		return runtime.Value{}, fmt.Errorf("JSON_SET: path %q must address a single location (no wildcards, filters or '..')", path)
+	}
+
+	root, err := ToJSON(s, source)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_SET: %v", err)
+	}
+	v, err := ToJSON(s, value)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_SET: %v", err)
+	}
+
+	root, err = setPath(root, segs, v, "$")
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	if _, ok := root.(map[string]interface{}); ok {
+		return runtime.Value{Kind: runtime.KindStruct, V: root}, nil
+	}
+	return runtime.Value{Kind: runtime.KindJSON, V: root}, nil
+}
+
+// JSONMerge implements the JSON_MERGE operation: a deep merge of two objects
+// where B's values win, nested objects are merged and arrays are replaced.
+func JSONMerge(s *runtime.Session, a runtime.Value, b runtime.Value) (runtime.Value, error) {
+	av, err := ToJSON(s, a)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_MERGE: %v", err)
+	}
+	bv, err := ToJSON(s, b)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("JSON_MERGE: %v", err)
+	}
+	am, ok := av.(map[string]interface{})
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("JSON_MERGE: A must be an object, got %s", describeJSON(av))
+	}
+	bm, ok := bv.(map[string]interface{})
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("JSON_MERGE: B must be an object, got %s", describeJSON(bv))
+	}
+	return runtime.Value{Kind: runtime.KindStruct, V: mergeObjects(am, bm)}, nil
+}
+
+func mergeObjects(a, b map[string]interface{}) map[string]interface{} {
+	for k, bv := range b {
+		if am, ok := a[k].(map[string]interface{}); ok {
+			if bm, ok := bv.(map[string]interface{}); ok {
+				a[k] = mergeObjects(am, bm)
+				continue
+			}
+		}
+		a[k] = bv
+	}
+	return a
+}
+
+func keyName(s *runtime.Session, k runtime.Value) (string, error) {
+	switch v := k.V.(type) {
+	case string:
+		return v, nil
+	case runtime.TextHandle:
+		text, ok := s.Stores.Text.Get(v)
+		if !ok {
+			return "", fmt.Errorf("text handle %s not found", v.ID)
+		}
+		return text, nil
+	}
+	return "", fmt.Errorf("keys must be TEXT, got %s", k.Kind)
+}
+
+// setPath writes v at segs inside node (already a private copy) and returns
+// the updated node.
+func setPath(node interface{}, segs []pathSegment, v interface{}, loc string) (interface{}, error) {
+	if len(segs) == 0 {
+		return v, nil
+	}
+	seg, rest := segs[0], segs[1:]
+	next := loc + segmentString(seg)
+
+	if arr, ok := node.([]interface{}); ok && (seg.kind == segIndex || seg.numeric) {
+		i := seg.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i > len(arr) {
+			return nil, fmt.Errorf("JSON_SET failed: index %d out of range at %s (array length %d)", seg.index, loc, len(arr))
+		}
+		if i == len(arr) {
+			arr = append(arr, nil)
+		}
+		child, err := setPath(arr[i], rest, v, next)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = child
+		return arr, nil
+	}
+
+	if seg.kind != segKey {
+		return nil, fmt.Errorf("JSON_SET failed: cannot index %s with [%d]: value is %s, not an array", loc, seg.index, describeJSON(node))
+	}
+	if node == nil {
+		node = map[string]interface{}{}
+	}
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JSON_SET failed: cannot set key %q at %s: value is %s, not an object", seg.keys[0], loc, describeJSON(node))
+	}
+	child, err := setPath(obj[seg.keys[0]], rest, v, next)
+	if err != nil {
+		return nil, err
+	}
+	obj[seg.keys[0]] = child
+	return obj, nil
+}
@@ -0,0 +1,91 @@
+package pure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestJSONBuild(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	name := runtime.Value{Kind: runtime.KindText, V: ts.Add("ACME")}
+	keys := runtime.Value{Kind: runtime.KindList, V: []runtime.Value{
+		{Kind: runtime.KindString, V: "vendor"},
+		{Kind: runtime.KindText, V: ts.Add("total")},
+		{Kind: runtime.KindString, V: "where"},
+		{Kind: runtime.KindString, V: "lines"},
+	}}
+	values := runtime.Value{Kind: runtime.KindList, V: []runtime.Value{
+		name,
+		{Kind: runtime.KindInt, V: 42},
+		{Kind: runtime.KindSpan, V: runtime.Span{Start: 3, End: 7}},
+		{Kind: runtime.KindRows, V: []map[string]interface{}{{"sku": "A"}}},
+	}}
+
+	obj, err := JSONObject(s, keys, values)
+	if err != nil {
+		t.Fatalf("JSONObject failed: %v", err)
+	}
+	assertJSON(t, obj, `{"lines":[{"sku":"A"}],"total":42,"vendor":"ACME","where":{"end":7,"start":3}}`)
+
+	path := "lines[1].sku"
+	set, err := JSONSet(s, obj, path, runtime.Value{Kind: runtime.KindString, V: "B"})
+	if err != nil {
+		t.Fatalf("JSONSet failed: %v", err)
+	}
+	assertJSON(t, set, `{"lines":[{"sku":"A"},{"sku":"B"}],"total":42,"vendor":"ACME","where":{"end":7,"start":3}}`)
+	// The source must not be modified.
+	assertJSON(t, obj, `{"lines":[{"sku":"A"}],"total":42,"vendor":"ACME","where":{"end":7,"start":3}}`)
+
+	patch := runtime.Value{Kind: runtime.KindJSON, V: map[string]interface{}{
+		"where": map[string]interface{}{"end": 9.0},
+		"paid":  true,
+	}}
+	merged, err := JSONMerge(s, set, patch)
+	if err != nil {
+		t.Fatalf("JSONMerge failed: %v", err)
+	}
+	assertJSON(t, merged, `{"lines":[{"sku":"A"},{"sku":"B"}],"paid":true,"total":42,"vendor":"ACME","where":{"end":9,"start":3}}`)
+
+	arr, err := JSONArray(s, values)
+	if err != nil {
+		t.Fatalf("JSONArray failed: %v", err)
+	}
+	assertJSON(t, arr, `["ACME",42,{"end":7,"start":3},[{"sku":"A"}]]`)
+}
+
+func TestJSONBuild_Errors(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	one := runtime.Value{Kind: runtime.KindList, V: []runtime.Value{{Kind: runtime.KindString, V: "a"}}}
+	none := runtime.Value{Kind: runtime.KindList, V: []runtime.Value{}}
+	if _, err := JSONObject(s, one, none); err == nil {
+		t.Error("expected error for key/value count mismatch")
+	}
+
+	obj := runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{"a": 1}}
+	if _, err := JSONSet(s, obj, "a[*]", one); err == nil {
+		t.Error("expected error for wildcard JSON_SET path")
+	}
+	if _, err := JSONSet(s, obj, "a.b", one); err == nil {
+		t.Error("expected error when setting a key on a number")
+	}
+	if _, err := JSONMerge(s, obj, one); err == nil {
+		t.Error("expected error when merging a non-object")
+	}
+}
+
+func assertJSON(t *testing.T, v runtime.Value, want string) {
+	t.Helper()
+	data, err := json.Marshal(v.V)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
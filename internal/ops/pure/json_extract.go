@@ -0,0 +1,230 @@
+package pure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// jsonCandidate is a JSON value found embedded in free text.
+type jsonCandidate struct {
+	span     runtime.Span
+	value    interface{}
+	repaired bool
+}
+
+// scanJSON finds every top-level JSON object or array embedded in text.
+//
+// Each '{' or '[' starts a candidate that runs to its balanced closing bracket;
+// brackets inside string literals (with backslash escapes) do not count. A
+// candidate that parses is recorded and scanning resumes after it, so nested
+// values are not reported twice. A candidate that does not parse is skipped one
+// byte at a time, which lets prose like "the set {a, b}: {"a": 1}" still yield
+// the real object. Bracket ends found while matching one candidate are kept for
+// the ones nested in it, so unbalanced input is not rescanned from every
+// bracket.
+//
+// In tolerant mode single-quoted strings also delimit literals, and candidates
+// that fail strict parsing are retried after repairJSON. Code fences need no
+// special handling: the scanner looks inside them like any other text.
+func scanJSON(text string, tolerant bool) ([]jsonCandidate, error) {
+	var found []jsonCandidate
+	var firstErr error
+	ends := make(map[int]int)
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' && text[i] != '[' {
+			continue
+		}
+		end, ok := ends[i]
+		if !ok {
+			matchBrackets(text, i, tolerant, ends)
+			end = ends[i]
+		}
+		if end < 0 {
+			continue
+		}
+		raw := text[i:end]
+
+		var v interface{}
+		err := json.Unmarshal([]byte(raw), &v)
+		repaired := false
+		if err != nil && tolerant {
+			if fixed := repairJSON(raw); fixed != raw {
+				if json.Unmarshal([]byte(fixed), &v) == nil {
+					err = nil
+					repaired = true
+				}
+			}
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("candidate at byte %d: %v", i, err)
+			}
+			continue
+		}
+
+		found = append(found, jsonCandidate{span: runtime.Span{Start: i, End: end}, value: v, repaired: repaired})
+		i = end - 1
+	}
+
+	if len(found) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return found, nil
+}
+
+// matchBrackets scans from the bracket at text[start] to the one closing it
+// and records in ends the offset just past the closing bracket of every
+// bracket opened on the way, or -1 for those left open by a mismatched
+// bracket or the end of text. A scan starting at one of those brackets would
+// see the same string literals and reach the same end, so the entries hold for
+// them too.
+func matchBrackets(text string, start int, tolerant bool, ends map[int]int) {
+	var open []int
+	var quote byte
+	unclosed := func() {
+		for _, o := range open {
+			ends[o] = -1
+		}
+	}
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			quote = c
+		case '\'':
+			if tolerant {
+				quote = c
+			}
+		case '{', '[':
+			open = append(open, i)
+		case '}', ']':
+			top := open[len(open)-1]
+			if (text[top] == '{') != (c == '}') {
+				unclosed()
+				return
+			}
+			ends[top] = i + 1
+			open = open[:len(open)-1]
+			if len(open) == 0 {
+				return
+			}
+		}
+	}
+	unclosed()
+}
+
+// repairJSON rewrites the common ways LLM output deviates from JSON:
+// single-quoted strings become double-quoted and trailing commas before a
+// closing bracket are dropped. Text inside strings is left alone.
+func repairJSON(raw string) string {
+	var sb strings.Builder
+	sb.Grow(len(raw))
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch c {
+		case '"':
+			end := stringEnd(raw, i, '"')
+			sb.WriteString(raw[i:end])
+			i = end - 1
+		case '\'':
+			end := stringEnd(raw, i, '\'')
+			sb.WriteByte('"')
+			body := raw[i+1 : max(i+1, end-1)]
+			for j := 0; j < len(body); j++ {
+				switch {
+				case body[j] == '\\' && j+1 < len(body) && body[j+1] == '\'':
+					sb.WriteByte('\'')
+					j++
+				case body[j] == '\\' && j+1 < len(body):
+					sb.WriteString(body[j : j+2])
+					j++
+				case body[j] == '"':
+					sb.WriteString(`\"`)
+				default:
+					sb.WriteByte(body[j])
+				}
+			}
+			sb.WriteByte('"')
+			i = end - 1
+		case ',':
+			j := i + 1
+			for j < len(raw) && strings.IndexByte(" \t\r\n", raw[j]) >= 0 {
+				j++
+			}
+			if j < len(raw) && (raw[j] == '}' || raw[j] == ']') {
+				continue
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// stringEnd returns the offset just past the string literal opened by the
+// quote at raw[start].
+func stringEnd(raw string, start int, quote byte) int {
+	for i := start + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// FindJSON implements the FIND_JSON operation.
+// MODE FIRST and LAST return a STRUCT {success, value, span, repaired} in the
+// shape of FIND_REGEX; MODE ALL returns ROWS with one {value, span, repaired}
+// row per embedded value. Spans are byte ranges in the source handle.
+func FindJSON(s *runtime.Session, source runtime.Value, mode string, tolerant bool) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+
+	found, _ := scanJSON(text, tolerant)
+
+	row := func(c jsonCandidate) map[string]interface{} {
+		return map[string]interface{}{"value": c.value, "span": c.span, "repaired": c.repaired}
+	}
+
+	switch mode {
+	case "ALL":
+		rows := make([]map[string]interface{}, 0, len(found))
+		for _, c := range found {
+			rows = append(rows, row(c))
+		}
+		return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+	case "FIRST", "LAST":
+		if len(found) == 0 {
+			return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{"success": false}}, nil
+		}
+		c := found[0]
+		if mode == "LAST" {
+			c = found[len(found)-1]
+		}
+		res := row(c)
+		res["success"] = true
+		return runtime.Value{Kind: runtime.KindStruct, V: res}, nil
+	default:
+		return runtime.Value{}, fmt.Errorf("FIND_JSON: unknown mode %q", mode)
+	}
+}
@@ -0,0 +1,96 @@
+package pure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestExtractJSON(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	tests := []struct {
+		name string
+		text string
+		want interface{}
+	}{
+		{"prose braces", `Use {curly} braces like {this}. Result: {"a": 1} done {x}`, map[string]interface{}{"a": 1.0}},
+		{"braces in strings", `{"msg": "close } early", "n": [1, {"m": "]"}]}`, map[string]interface{}{"msg": "close } early", "n": []interface{}{1.0, map[string]interface{}{"m": "]"}}}},
+		{"escaped quote", `out: {"q": "say \"}\" now"}`, map[string]interface{}{"q": `say "}" now`}},
+		{"top-level array", "Items:\n[1, 2, 3]\n", []interface{}{1.0, 2.0, 3.0}},
+		{"two objects", `{"a": 1} and then {"b": 2}`, map[string]interface{}{"a": 1.0}},
+		{"citation before object", `As noted in [1], the answer is {"name": "Ada", "refs": [1, 2]}`, map[string]interface{}{"name": "Ada", "refs": []interface{}{1.0, 2.0}}},
+		{"citation before tolerant object", `See [2]: {'name': 'Ada',}`, map[string]interface{}{"name": "Ada"}},
+		{"unbalanced prefix", `{ [ { [ {"a": 1}`, map[string]interface{}{"a": 1.0}},
+		{"fenced tolerant", "Here you go:\n```json\n{'name': 'O\\'Hara', \"tags\": [\"x\",],}\n```", map[string]interface{}{"name": "O'Hara", "tags": []interface{}{"x"}}},
+	}
+
+	for _, tt := range tests {
+		res, err := ExtractJSON(s, runtime.Value{Kind: runtime.KindText, V: ts.Add(tt.text)})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(res.V, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, res.V)
+		}
+	}
+
+	if _, err := ExtractJSON(s, runtime.Value{Kind: runtime.KindText, V: ts.Add("no json {here")}); err == nil {
+		t.Error("expected error for text without JSON")
+	}
+}
+
+func TestMatchBrackets(t *testing.T) {
+	text := `{ [ "}" {"a": [1]} ]`
+	ends := make(map[int]int)
+	matchBrackets(text, 0, false, ends)
+	want := map[int]int{0: -1, 2: 20, 8: 18, 14: 17}
+	if !reflect.DeepEqual(ends, want) {
+		t.Errorf("expected %v, got %v", want, ends)
+	}
+
+	// One scan settles every bracket of unbalanced input, so scanJSON does
+	// not rescan to the end of text from each of them.
+	long := strings.Repeat("{", 10000)
+	ends = make(map[int]int)
+	matchBrackets(long, 0, false, ends)
+	if len(ends) != len(long) || ends[len(long)-1] != -1 {
+		t.Errorf("expected every brace marked unclosed, got %d entries", len(ends))
+	}
+	if found, _ := scanJSON(long, false); len(found) != 0 {
+		t.Errorf("expected no JSON in open braces, got %v", found)
+	}
+}
+
+func TestFindJSON_Modes(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	src := runtime.Value{Kind: runtime.KindText, V: ts.Add(`first {"a": 1}, then [2], last {'b': 3,}`)}
+
+	res, err := FindJSON(s, src, "ALL", false)
+	if err != nil {
+		t.Fatalf("FIND_JSON ALL failed: %v", err)
+	}
+	rows := res.V.([]map[string]interface{})
+	if len(rows) != 2 || rows[1]["span"] != (runtime.Span{Start: 21, End: 24}) {
+		t.Errorf("strict ALL: unexpected rows %v", rows)
+	}
+
+	res, _ = FindJSON(s, src, "LAST", true)
+	m := res.V.(map[string]interface{})
+	if m["success"] != true || m["repaired"] != true || m["span"] != (runtime.Span{Start: 31, End: 40}) {
+		t.Errorf("tolerant LAST: unexpected result %v", m)
+	}
+	if !reflect.DeepEqual(m["value"], map[string]interface{}{"b": 3.0}) {
+		t.Errorf("tolerant LAST: unexpected value %v", m["value"])
+	}
+
+	res, _ = FindJSON(s, runtime.Value{Kind: runtime.KindText, V: ts.Add("nothing here")}, "FIRST", true)
+	if res.V.(map[string]interface{})["success"] != false {
+		t.Errorf("expected success=false, got %v", res.V)
+	}
+}
@@ -0,0 +1,651 @@
+package pure
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// JSON path grammar accepted by JSON_GET (a JSONPath subset):
+//
+//	path      := [ "$" ] { segment }
+//	segment   := "." key | "." index | "." "*" | ".." selector | "[" bracket "]"
+//	bracket   := index | "*" | quoted-key { "," quoted-key } | "?(" filter ")"
+//	filter    := "@" { "." key } [ cmp literal ]
+//	cmp       := "==" | "!=" | "<" | "<=" | ">" | ">="
+//	literal   := quoted-string | number | "true" | "false" | "null"
+//
+// A leading bare key is allowed ("items[0].name"). Negative indexes count from
+// the end of the array. A numeric dot segment ("items.0") indexes arrays and
+// falls back to a key lookup on objects.
+
+type segKind int
+
+const (
+	segKey segKind = iota
+	segIndex
+	segWildcard
+	segFilter
+)
+
+type pathSegment struct {
+	kind      segKind
+	keys      []string
+	index     int
+	numeric   bool // dot segment that looked like an index
+	recursive bool // preceded by ".."
+	filter    *pathFilter
+}
+
+type pathFilter struct {
+	field []string
+	op    string
+	value interface{}
+}
+
+// definite reports whether the path can only ever select a single node.
+func definite(segs []pathSegment) bool {
+	for _, seg := range segs {
+		if seg.recursive || seg.kind == segWildcard || seg.kind == segFilter || len(seg.keys) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func parseJSONPath(path string) ([]pathSegment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+
+	var segs []pathSegment
+	i := 0
+	// Leading bare key ("items[0]") is treated as ".items".
+	if i < len(p) && p[i] != '.' && p[i] != '[' {
+		p = "." + p
+	}
+
+	for i < len(p) {
+		switch p[i] {
+		case '.':
+			recursive := false
+			i++
+			if i < len(p) && p[i] == '.' {
+				recursive = true
+				i++
+			}
+			if i < len(p) && p[i] == '[' {
+				seg, next, err := parseBracket(p, i)
+				if err != nil {
+					return nil, err
+				}
+				seg.recursive = recursive
+				segs = append(segs, seg)
+				i = next
+				continue
+			}
+			start := i
+			for i < len(p) && p[i] != '.' && p[i] != '[' {
+				i++
+			}
+			name := p[start:i]
+			if name == "" {
+				if start == len(p) && !recursive {
+					// Trailing dot ("a.b.") is tolerated.
+					continue
+				}
+				return nil, fmt.Errorf("empty path segment at position %d", start)
+			}
+			seg := pathSegment{kind: segKey, keys: []string{name}, recursive: recursive}
+			if name == "*" {
+				seg = pathSegment{kind: segWildcard, recursive: recursive}
+			} else if n, err := strconv.Atoi(name); err == nil {
+				seg.index = n
+				seg.numeric = true
+			}
+			segs = append(segs, seg)
+		case '[':
+			seg, next, err := parseBracket(p, i)
+			if err != nil {
+				return nil, err
+			}
+			segs = append(segs, seg)
+			i = next
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", p[i], i)
+		}
+	}
+	return segs, nil
+}
+
+// parseBracket parses a "[...]" selector starting at p[i] == '['.
+func parseBracket(p string, i int) (pathSegment, int, error) {
+	end := closingBracket(p, i)
+	if end == -1 {
+		return pathSegment{}, 0, fmt.Errorf("unterminated '[' at position %d", i)
+	}
+	inner := strings.TrimSpace(p[i+1 : end])
+	next := end + 1
+
+	switch {
+	case inner == "*":
+		return pathSegment{kind: segWildcard}, next, nil
+	case strings.HasPrefix(inner, "?"):
+		f, err := parseFilter(inner[1:])
+		if err != nil {
+			return pathSegment{}, 0, err
+		}
+		return pathSegment{kind: segFilter, filter: f}, next, nil
+	case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, "\""):
+		var keys []string
+		for _, part := range splitOutsideQuotes(inner, ',') {
+			k, err := unquote(strings.TrimSpace(part))
+			if err != nil {
+				return pathSegment{}, 0, err
+			}
+			keys = append(keys, k)
+		}
+		return pathSegment{kind: segKey, keys: keys}, next, nil
+	default:
+		n, err := strconv.Atoi(inner)
+		if err != nil {
+			return pathSegment{}, 0, fmt.Errorf("invalid index %q (use [n], [-n], [*] or ['key'])", inner)
+		}
+		return pathSegment{kind: segIndex, index: n}, next, nil
+	}
+}
+
+func closingBracket(p string, open int) int {
+	var quote byte
+	depth := 0
+	for j := open; j < len(p); j++ {
+		c := p[j]
+		if quote != 0 {
+			if c == '\\' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth == 0 && c == ']' {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		if quote != 0 {
+			if c == '\\' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		} else if c == sep {
+			parts = append(parts, s[start:j])
+			start = j + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"') || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("invalid quoted key %s", s)
+	}
+	body := s[1 : len(s)-1]
+	var sb strings.Builder
+	for j := 0; j < len(body); j++ {
+		if body[j] == '\\' && j+1 < len(body) {
+			j++
+		}
+		sb.WriteByte(body[j])
+	}
+	return sb.String(), nil
+}
+
+func parseFilter(expr string) (*pathFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return nil, fmt.Errorf("filter must be written as [?(...)], got [?%s]", expr)
+	}
+	expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("filter must start with @, got %q", expr)
+	}
+
+	f := &pathFilter{}
+	lhs := expr
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if idx := indexOutsideQuotes(expr, op); idx != -1 {
+			lhs = strings.TrimSpace(expr[:idx])
+			f.op = op
+			lit, err := parseLiteral(strings.TrimSpace(expr[idx+len(op):]))
+			if err != nil {
+				return nil, err
+			}
+			f.value = lit
+			break
+		}
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(lhs, "@"), ".") {
+		if part != "" {
+			f.field = append(f.field, part)
+		}
+	}
+	return f, nil
+}
+
+func indexOutsideQuotes(s, sub string) int {
+	var quote byte
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		if quote != 0 {
+			if c == '\\' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if strings.HasPrefix(s[j:], sub) {
+			return j
+		}
+	}
+	return -1
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "'") || strings.HasPrefix(s, "\"") {
+		return unquote(s)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter literal %q", s)
+	}
+	return f, nil
+}
+
+// evalJSONPath applies segs to root. For definite paths a missing node is an
+// error with a hint; for wildcard, filter and recursive paths missing nodes are
+// simply skipped.
+func evalJSONPath(root interface{}, segs []pathSegment) ([]interface{}, error) {
+	strict := definite(segs)
+	nodes := []interface{}{root}
+	loc := "$"
+
+	for _, seg := range segs {
+		var next []interface{}
+		for _, n := range nodes {
+			candidates := []interface{}{n}
+			if seg.recursive {
+				candidates = descendants(n, nil)
+			}
+			for _, c := range candidates {
+				out, err := applySegment(c, seg, loc, strict && !seg.recursive)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, out...)
+			}
+		}
+		nodes = next
+		loc += segmentString(seg)
+	}
+	return nodes, nil
+}
+
+func applySegment(n interface{}, seg pathSegment, loc string, strict bool) ([]interface{}, error) {
+	switch seg.kind {
+	case segKey:
+		if seg.numeric {
+			if arr, ok := asArray(n); ok {
+				return indexArray(arr, seg.index, loc, strict)
+			}
+		}
+		obj, ok := asObject(n)
+		if !ok {
+			if strict {
+				return nil, notObjectError(n, seg.keys[0], loc)
+			}
+			return nil, nil
+		}
+		var out []interface{}
+		for _, k := range seg.keys {
+			v, ok := obj[k]
+			if !ok {
+				if strict {
+					return nil, missingKeyError(obj, k, loc)
+				}
+				continue
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case segIndex:
+		arr, ok := asArray(n)
+		if !ok {
+			if strict {
+				return nil, fmt.Errorf("JSON_GET failed: cannot index %s with [%d]: value is %s, not an array", loc, seg.index, describeJSON(n))
+			}
+			return nil, nil
+		}
+		return indexArray(arr, seg.index, loc, strict)
+	case segWildcard:
+		if arr, ok := asArray(n); ok {
+			return arr, nil
+		}
+		if obj, ok := asObject(n); ok {
+			keys := sortedKeys(obj)
+			out := make([]interface{}, 0, len(keys))
+			for _, k := range keys {
+				out = append(out, obj[k])
+			}
+			return out, nil
+		}
+		return nil, nil
+	case segFilter:
+		var items []interface{}
+		if arr, ok := asArray(n); ok {
+			items = arr
+		} else if obj, ok := asObject(n); ok {
+			for _, k := range sortedKeys(obj) {
+				items = append(items, obj[k])
+			}
+		}
+		var out []interface{}
+		for _, it := range items {
+			if seg.filter.match(it) {
+				out = append(out, it)
+			}
+		}
+		return out, nil
+	}
+	return nil, nil
+}
+
+func indexArray(arr []interface{}, idx int, loc string, strict bool) ([]interface{}, error) {
+	i := idx
+	if i < 0 {
+		i += len(arr)
+	}
+	if i < 0 || i >= len(arr) {
+		if strict {
+			return nil, fmt.Errorf("JSON_GET failed: index %d out of range at %s (array length %d)", idx, loc, len(arr))
+		}
+		return nil, nil
+	}
+	return []interface{}{arr[i]}, nil
+}
+
+func (f *pathFilter) match(item interface{}) bool {
+	cur := item
+	for _, k := range f.field {
+		obj, ok := asObject(cur)
+		if !ok {
+			return false
+		}
+		cur, ok = obj[k]
+		if !ok {
+			return false
+		}
+	}
+	if f.op == "" {
+		return cur != nil && cur != false
+	}
+
+	if fv, ok := f.value.(float64); ok {
+		cv, ok := toFloat(cur)
+		if !ok {
+			return f.op == "!="
+		}
+		switch f.op {
+		case "==":
+			return cv == fv
+		case "!=":
+			return cv != fv
+		case "<":
+			return cv < fv
+		case "<=":
+			return cv <= fv
+		case ">":
+			return cv > fv
+		case ">=":
+			return cv >= fv
+		}
+		return false
+	}
+
+	if fs, ok := f.value.(string); ok {
+		cs, ok := cur.(string)
+		if !ok {
+			return f.op == "!="
+		}
+		switch f.op {
+		case "==":
+			return cs == fs
+		case "!=":
+			return cs != fs
+		case "<":
+			return cs < fs
+		case "<=":
+			return cs <= fs
+		case ">":
+			return cs > fs
+		case ">=":
+			return cs >= fs
+		}
+		return false
+	}
+
+	switch f.op {
+	case "==":
+		return cur == f.value
+	case "!=":
+		return cur != f.value
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case runtime.Value:
+		return toFloat(n.V)
+	}
+	return 0, false
+}
+
+func descendants(n interface{}, acc []interface{}) []interface{} {
+	acc = append(acc, n)
+	if arr, ok := asArray(n); ok {
+		for _, c := range arr {
+			acc = descendants(c, acc)
+		}
+	} else if obj, ok := asObject(n); ok {
+		for _, k := range sortedKeys(obj) {
+			acc = descendants(obj[k], acc)
+		}
+	}
+	return acc
+}
+
+// asArray normalizes the array-like representations used by JSON, ROWS and LIST values.
+func asArray(v interface{}) ([]interface{}, bool) {
+	switch a := v.(type) {
+	case []interface{}:
+		return a, true
+	case []map[string]interface{}:
+		out := make([]interface{}, len(a))
+		for i, m := range a {
+			out[i] = m
+		}
+		return out, true
+	case []runtime.Value:
+		out := make([]interface{}, len(a))
+		for i, e := range a {
+			out[i] = e.V
+		}
+		return out, true
+	case runtime.Value:
+		return asArray(a.V)
+	}
+	return nil, false
+}
+
+func asObject(v interface{}) (map[string]interface{}, bool) {
+	switch o := v.(type) {
+	case map[string]interface{}:
+		return o, true
+	case runtime.Value:
+		return asObject(o.V)
+	}
+	return nil, false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func segmentString(seg pathSegment) string {
+	prefix := ""
+	if seg.recursive {
+		prefix = ".."
+	}
+	switch seg.kind {
+	case segKey:
+		if seg.numeric {
+			return prefix + "[" + strconv.Itoa(seg.index) + "]"
+		}
+		if len(seg.keys) == 1 {
+			if prefix == "" {
+				prefix = "."
+			}
+			return prefix + seg.keys[0]
+		}
+		return prefix + "['" + strings.Join(seg.keys, "','") + "']"
+	case segIndex:
+		return prefix + "[" + strconv.Itoa(seg.index) + "]"
+	case segWildcard:
+		return prefix + "[*]"
+	case segFilter:
+		return prefix + "[?]"
+	}
+	return ""
+}
+
+func describeJSON(v interface{}) string {
+	if arr, ok := asArray(v); ok {
+		return fmt.Sprintf("an array of %d", len(arr))
+	}
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case string, runtime.TextHandle:
+		return "a string"
+	case float64, int:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func notObjectError(n interface{}, key, loc string) error {
+	msg := fmt.Sprintf("JSON_GET failed: cannot read key %q at %s: value is %s, not an object", key, loc, describeJSON(n))
+	if _, ok := asArray(n); ok {
+		msg += fmt.Sprintf("; use %s[0].%s for one element or %s[*].%s for all", loc, key, loc, key)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func missingKeyError(obj map[string]interface{}, key, loc string) error {
+	keys := sortedKeys(obj)
+	msg := fmt.Sprintf("JSON_GET failed: key %q not found at %s", key, loc)
+	if best := closestKey(key, keys); best != "" {
+		msg += fmt.Sprintf("; did you mean %q?", best)
+	}
+	return fmt.Errorf("%s Available keys: %v", msg, keys)
+}
+
+// closestKey returns the candidate within a small edit distance of key, if any.
+func closestKey(key string, candidates []string) string {
+	best := ""
+	bestDist := math.MaxInt
+	lk := strings.ToLower(key)
+	for _, c := range candidates {
+		d := editDistance(lk, strings.ToLower(c))
+		if d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	limit := len(key)/3 + 1
+	if bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
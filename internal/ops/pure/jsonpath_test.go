@@ -0,0 +1,104 @@
+package pure
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestJSONGet_Paths(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	doc := ts.Add(`{
+		"items": [
+			{"id": 1, "name": "apple", "price": 1.5, "tags": ["fruit"]},
+			{"id": 2, "name": "bread", "price": 3, "tags": []},
+			{"id": 3, "name": "cheese", "price": 12, "meta": {"name": "aged"}}
+		],
+		"owner": {"name": "ann"}
+	}`)
+	src, err := JSONParse(s, runtime.Value{Kind: runtime.KindText, V: doc})
+	if err != nil {
+		t.Fatalf("JSONParse failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		kind runtime.Kind
+		want string
+	}{
+		{"items.0.name", runtime.KindText, "apple"},
+		{"items[1].id", runtime.KindInt, "2"},
+		{"$.items[-1].name", runtime.KindText, "cheese"},
+		{"$['owner']['name']", runtime.KindText, "ann"},
+		{"owner", runtime.KindStruct, ""},
+		{"items", runtime.KindRows, ""},
+		{"items[0].price", runtime.KindFloat, "1.5"},
+		{"items[*].id", runtime.KindList, "1,2,3"},
+		{"items[?(@.price > 2)].name", runtime.KindList, "bread,cheese"},
+		{"items[?(@.name == 'apple')].id", runtime.KindList, "1"},
+		{"items[?(@.meta)].id", runtime.KindList, "3"},
+		{"$..name", runtime.KindList, "apple,bread,cheese,aged,ann"},
+	}
+
+	for _, tt := range tests {
+		res, err := JSONGet(s, src, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if res.Kind != tt.kind {
+			t.Errorf("%s: expected kind %s, got %s", tt.path, tt.kind, res.Kind)
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+		if got := render(ts, res); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestJSONGet_Hints(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	src := runtime.Value{Kind: runtime.KindJSON, V: map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"name": "a"}},
+	}}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"items[0].nmae", `did you mean "name"`},
+		{"items.name", "items[*].name"},
+		{"items[4]", "out of range"},
+		{"items[", "unterminated"},
+	}
+	for _, tt := range tests {
+		_, err := JSONGet(s, src, tt.path)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.path, tt.want, err)
+		}
+	}
+}
+
+func render(ts *mockTextStore, v runtime.Value) string {
+	switch v.Kind {
+	case runtime.KindText:
+		text, _ := ts.Get(v.V.(runtime.TextHandle))
+		return text
+	case runtime.KindList:
+		var parts []string
+		for _, e := range v.V.([]runtime.Value) {
+			parts = append(parts, render(ts, e))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprintf("%v", v.V)
+	}
+}
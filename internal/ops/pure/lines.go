@@ -0,0 +1,149 @@
+package pure
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// lineIndex records where each line of a text starts. Lines are numbered
+// from 1 and split on "\n", so a trailing newline yields a final empty line
+// (matching the line count reported by STATS).
+type lineIndex struct {
+	text   string
+	starts []int
+}
+
+func newLineIndex(text string) lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return lineIndex{text: text, starts: starts}
+}
+
+func (li lineIndex) count() int { return len(li.starts) }
+
+// span returns the byte range of line n (1-based) without its terminator.
+func (li lineIndex) span(n int) runtime.Span {
+	start, end := li.starts[n-1], len(li.text)
+	if n < len(li.starts) {
+		end = li.starts[n] - 1
+	}
+	if end > start && li.text[end-1] == '\r' {
+		end--
+	}
+	return runtime.Span{Start: start, End: end}
+}
+
+// lineOf returns the 1-based line containing offset.
+func (li lineIndex) lineOf(offset int) int {
+	return sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset })
+}
+
+// GrepLines implements the GREP_LINES operation.
+// PATTERN is a regular expression matched against each line separately. Each
+// matching line becomes a row with line (1-based), column (of the first match,
+// 1-based, in characters), span (the line), match_span, text (the line as a
+// string), and context (a TEXT slice of the CONTEXT lines before and after,
+// clipped to the document) with context_span, context_start and context_end.
+func GrepLines(s *runtime.Session, source runtime.Value, pattern runtime.Value, context int) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	pat, _ := s.Stores.Text.Get(pattern.V.(runtime.TextHandle))
+
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("GREP_LINES invalid pattern %q: %v", pat, err)
+	}
+	if context < 0 {
+		return runtime.Value{}, fmt.Errorf("GREP_LINES: CONTEXT must be >= 0, got %d", context)
+	}
+	rows, err := grepRows(s, h, text, re, context)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("GREP_LINES failed: %v", err)
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
+
+// grepRows returns the GREP_LINES rows for the lines of text (the content
+// of h) that match re.
+func grepRows(s *runtime.Session, h runtime.TextHandle, text string, re *regexp.Regexp, context int) ([]map[string]interface{}, error) {
+	li := newLineIndex(text)
+	rows := []map[string]interface{}{}
+	for n := 1; n <= li.count(); n++ {
+		sp := li.span(n)
+		line := text[sp.Start:sp.End]
+		loc := re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+
+		first, last := max(1, n-context), min(li.count(), n+context)
+		ctxSpan := runtime.Span{Start: li.span(first).Start, End: li.span(last).End}
+		ctx, err := sliceValue(s, h, ctxSpan.Start, ctxSpan.End)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, map[string]interface{}{
+			"line":          n,
+			"column":        utf8.RuneCountInString(line[:loc[0]]) + 1,
+			"span":          sp,
+			"match_span":    runtime.Span{Start: sp.Start + loc[0], End: sp.Start + loc[1]},
+			"text":          line,
+			"context":       ctx,
+			"context_span":  ctxSpan,
+			"context_start": first,
+			"context_end":   last,
+		})
+	}
+	return rows, nil
+}
+
+// LineAt implements the LINE_AT operation.
+// It returns a STRUCT with line and column (both 1-based; column counts
+// characters) and line_span for the line containing OFFSET. The end of the
+// text is a valid offset.
+func LineAt(s *runtime.Session, source runtime.Value, offset int) (runtime.Value, error) {
+	text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	if offset < 0 || offset > len(text) {
+		return runtime.Value{}, fmt.Errorf("LINE_AT: offset %d out of range (text has %d bytes)", offset, len(text))
+	}
+
+	li := newLineIndex(text)
+	n := li.lineOf(offset)
+	start := li.starts[n-1]
+	return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{
+		"line":      n,
+		"column":    utf8.RuneCountInString(text[start:offset]) + 1,
+		"line_span": li.span(n),
+	}}, nil
+}
+
+// LineRange implements the LINE_RANGE operation.
+// It returns a TEXT slice of lines FROM through TO (1-based, inclusive),
+// without the final line terminator. TO is clipped to the last line.
+func LineRange(s *runtime.Session, source runtime.Value, from, to int) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+
+	li := newLineIndex(text)
+	if from < 1 || from > li.count() {
+		return runtime.Value{}, fmt.Errorf("LINE_RANGE: FROM %d out of range (text has %d lines)", from, li.count())
+	}
+	if to < from {
+		return runtime.Value{}, fmt.Errorf("LINE_RANGE: TO %d is before FROM %d", to, from)
+	}
+	to = min(to, li.count())
+
+	res, err := sliceValue(s, h, li.starts[from-1], li.span(to).End)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("LINE_RANGE failed: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package pure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestLineOps(t *testing.T) {
+	const log = "boot ok\r\nworker started\nERROR disk full\nretrying\nörror? ERROR again\n"
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	src := runtime.Value{Kind: runtime.KindText, V: ts.Add(log)}
+	text := func(v interface{}) string {
+		got, _ := ts.Get(v.(runtime.Value).V.(runtime.TextHandle))
+		return got
+	}
+
+	res, err := GrepLines(s, src, runtime.Value{Kind: runtime.KindText, V: ts.Add(`ERROR`)}, 1)
+	if err != nil {
+		t.Fatalf("GREP_LINES failed: %v", err)
+	}
+	hits := res.V.([]map[string]interface{})
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 hits, got %v", hits)
+	}
+	if hits[0]["line"] != 3 || hits[0]["text"] != "ERROR disk full" || text(hits[0]["context"]) != "worker started\nERROR disk full\nretrying" {
+		t.Errorf("unexpected first hit: %v / %q", hits[0], text(hits[0]["context"]))
+	}
+	if hits[1]["line"] != 5 || hits[1]["column"] != 8 || hits[1]["context_end"] != 6 {
+		t.Errorf("unexpected second hit: %v", hits[1])
+	}
+	if sp := hits[1]["match_span"].(runtime.Span); log[sp.Start:sp.End] != "ERROR" {
+		t.Errorf("unexpected match span: %q", log[sp.Start:sp.End])
+	}
+	if _, err := GrepLines(s, src, runtime.Value{Kind: runtime.KindText, V: ts.Add(`(`)}, 0); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	res, err = LineAt(s, src, strings.Index(log, "disk"))
+	if err != nil {
+		t.Fatalf("LINE_AT failed: %v", err)
+	}
+	pos := res.V.(map[string]interface{})
+	if pos["line"] != 3 || pos["column"] != 7 {
+		t.Errorf("unexpected position: %v", pos)
+	}
+	if sp := pos["line_span"].(runtime.Span); log[sp.Start:sp.End] != "ERROR disk full" {
+		t.Errorf("unexpected line span: %q", log[sp.Start:sp.End])
+	}
+	if _, err := LineAt(s, src, len(log)+1); err == nil {
+		t.Error("expected error for offset past the end")
+	}
+
+	res, err = LineRange(s, src, 1, 2)
+	if err != nil {
+		t.Fatalf("LINE_RANGE failed: %v", err)
+	}
+	if got := text(res); got != "boot ok\r\nworker started" {
+		t.Errorf("unexpected range: %q", got)
+	}
+	res, _ = LineRange(s, src, 4, 100)
+	if got := text(res); got != "retrying\nörror? ERROR again\n" {
+		t.Errorf("unexpected clipped range: %q", got)
+	}
+	if _, err := LineRange(s, src, 0, 1); err == nil {
+		t.Error("expected error for line 0")
+	}
+}
@@ -0,0 +1,297 @@
+package pure
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// mdLine is one line of a Markdown document. start and end are byte offsets
+// in the source; end excludes the line terminator, next is the start of the
+// following line.
+type mdLine struct {
+	text       string
+	start, end int
+	next       int
+	inFence    bool // inside (or delimiting) a fenced code block
+}
+
+// mdFence is a fenced code block.
+type mdFence struct {
+	lang               string
+	start, end         int // whole block including fences
+	bodyStart, bodyEnd int
+}
+
+var (
+	fenceOpenRe  = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+	atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	tableDelimRe = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	inlineCodeRe = regexp.MustCompile("`+[^`]*`+")
+	inlineLinkRe = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*(<[^>]*>|[^\s)]+)(?:\s+"([^"]*)")?\s*\)`)
+	autolinkRe   = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+)
+
+// scanMarkdown splits text into lines and locates fenced code blocks.
+// An unclosed fence runs to the end of the document, as in CommonMark.
+func scanMarkdown(text string) ([]mdLine, []mdFence) {
+	var lines []mdLine
+	for pos := 0; pos < len(text); {
+		end := strings.IndexByte(text[pos:], '\n')
+		next := len(text)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += pos
+			next = end + 1
+		}
+		lines = append(lines, mdLine{text: strings.TrimSuffix(text[pos:end], "\r"), start: pos, end: end, next: next})
+		pos = next
+	}
+
+	var fences []mdFence
+	for i := 0; i < len(lines); i++ {
+		m := fenceOpenRe.FindStringSubmatch(lines[i].text)
+		if m == nil {
+			continue
+		}
+		marker := m[1]
+		f := mdFence{lang: m[2], start: lines[i].start, bodyStart: lines[i].next, end: len(text), bodyEnd: len(text)}
+		lines[i].inFence = true
+		j := i + 1
+		for ; j < len(lines); j++ {
+			lines[j].inFence = true
+			closing := strings.TrimSpace(lines[j].text)
+			if strings.HasPrefix(closing, marker) && strings.Trim(closing, marker[:1]) == "" {
+				f.bodyEnd, f.end = lines[j].start, lines[j].end
+				break
+			}
+		}
+		fences = append(fences, f)
+		i = j
+	}
+	return lines, fences
+}
+
+// sliceValue returns a TEXT value for text[start:end] of the source handle.
+func sliceValue(s *runtime.Session, h runtime.TextHandle, start, end int) (runtime.Value, error) {
+	sh, err := s.Stores.Text.Slice(h, start, end)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	return runtime.Value{Kind: runtime.KindText, V: sh}, nil
+}
+
+// MdSections implements the MD_SECTIONS operation.
+// Each ATX heading (# .. ######) outside code fences starts a section that
+// runs until the next heading of the same or a higher level, so a section's
+// span includes its subsections. Rows have heading, level, span, body_span and
+// body (a TEXT slice of the source).
+func MdSections(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	lines, _ := scanMarkdown(text)
+
+	type heading struct {
+		title     string
+		level     int
+		start     int // heading line
+		bodyStart int
+	}
+	var heads []heading
+	for _, l := range lines {
+		if l.inFence {
+			continue
+		}
+		if m := atxHeadingRe.FindStringSubmatch(l.text); m != nil {
+			heads = append(heads, heading{title: strings.TrimSpace(m[2]), level: len(m[1]), start: l.start, bodyStart: l.next})
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(heads))
+	for i, hd := range heads {
+		end := len(text)
+		for _, later := range heads[i+1:] {
+			if later.level <= hd.level {
+				end = later.start
+				break
+			}
+		}
+		body, err := sliceValue(s, h, hd.bodyStart, end)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("MD_SECTIONS failed: %v", err)
+		}
+		rows = append(rows, map[string]interface{}{
+			"heading":   hd.title,
+			"level":     hd.level,
+			"span":      runtime.Span{Start: hd.start, End: end},
+			"body_span": runtime.Span{Start: hd.bodyStart, End: end},
+			"body":      body,
+		})
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
+
+// MdCodeBlocks implements the MD_CODE_BLOCKS operation.
+// Rows have lang (the info string's first word, possibly empty), span (the
+// block including fences), body_span and body.
+func MdCodeBlocks(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	_, fences := scanMarkdown(text)
+
+	rows := make([]map[string]interface{}, 0, len(fences))
+	for _, f := range fences {
+		bodyStart := min(f.bodyStart, f.bodyEnd)
+		body, err := sliceValue(s, h, bodyStart, f.bodyEnd)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("MD_CODE_BLOCKS failed: %v", err)
+		}
+		rows = append(rows, map[string]interface{}{
+			"lang":      f.lang,
+			"span":      runtime.Span{Start: f.start, End: f.end},
+			"body_span": runtime.Span{Start: bodyStart, End: f.bodyEnd},
+			"body":      body,
+		})
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
+
+// splitTableRow splits a pipe-table line into trimmed cells, honouring \|.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			sb.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		default:
+			sb.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(sb.String()))
+}
+
+// MdTable implements the MD_TABLE operation.
+// INDEX selects the pipe table (0-based, in document order, outside code
+// fences). Each body row becomes a row keyed by the header cells, plus
+// "_span" with the row's line span. Missing cells are empty strings; extra
+// cells are dropped.
+func MdTable(s *runtime.Session, source runtime.Value, index int) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	lines, _ := scanMarkdown(text)
+
+	found := 0
+	for i := 0; i+1 < len(lines); i++ {
+		hdr, delim := lines[i], lines[i+1]
+		if hdr.inFence || delim.inFence || !strings.Contains(hdr.text, "|") || !tableDelimRe.MatchString(delim.text) {
+			continue
+		}
+		if found != index {
+			found++
+			continue
+		}
+
+		cols := splitTableRow(hdr.text)
+		rows := []map[string]interface{}{}
+		for j := i + 2; j < len(lines); j++ {
+			l := lines[j]
+			if l.inFence || strings.TrimSpace(l.text) == "" || !strings.Contains(l.text, "|") {
+				break
+			}
+			cells := splitTableRow(l.text)
+			row := map[string]interface{}{"_span": runtime.Span{Start: l.start, End: l.end}}
+			for k, c := range cols {
+				row[c] = ""
+				if k < len(cells) {
+					row[c] = cells[k]
+				}
+			}
+			rows = append(rows, row)
+		}
+		return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+	}
+
+	return runtime.Value{}, fmt.Errorf("MD_TABLE: table %d not found (document has %d table(s))", index, found)
+}
+
+// MdLinks implements the MD_LINKS operation.
+// It finds inline links [text](url "title"), images ![alt](src) and
+// autolinks <https://...> outside code. Rows have kind ("link" or "image"),
+// text, url, title and span.
+func MdLinks(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	_, fences := scanMarkdown(text)
+
+	// Blank out code so its brackets are not read as links; offsets are kept.
+	masked := []byte(text)
+	blank := func(start, end int) {
+		for i := start; i < end; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+	for _, f := range fences {
+		blank(f.start, f.end)
+	}
+	for _, loc := range inlineCodeRe.FindAllIndex(masked, -1) {
+		blank(loc[0], loc[1])
+	}
+
+	type link struct {
+		row   map[string]interface{}
+		start int
+	}
+	var links []link
+	for _, m := range inlineLinkRe.FindAllSubmatchIndex(masked, -1) {
+		kind := "link"
+		if m[3] > m[2] {
+			kind = "image"
+		}
+		title := ""
+		if m[8] >= 0 {
+			title = text[m[8]:m[9]]
+		}
+		links = append(links, link{start: m[0], row: map[string]interface{}{
+			"kind":  kind,
+			"text":  text[m[4]:m[5]],
+			"url":   strings.Trim(text[m[6]:m[7]], "<>"),
+			"title": title,
+			"span":  runtime.Span{Start: m[0], End: m[1]},
+		}})
+	}
+	for _, m := range autolinkRe.FindAllSubmatchIndex(masked, -1) {
+		url := text[m[2]:m[3]]
+		links = append(links, link{start: m[0], row: map[string]interface{}{
+			"kind":  "link",
+			"text":  url,
+			"url":   url,
+			"title": "",
+			"span":  runtime.Span{Start: m[0], End: m[1]},
+		}})
+	}
+
+	// Report links in document order.
+	sort.SliceStable(links, func(i, j int) bool { return links[i].start < links[j].start })
+	rows := make([]map[string]interface{}, 0, len(links))
+	for _, l := range links {
+		rows = append(rows, l.row)
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: rows}, nil
+}
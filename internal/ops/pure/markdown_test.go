@@ -0,0 +1,86 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+const mdDoc = "# Guide\n" +
+	"Intro with [docs](https://example.com/docs \"Docs\") and `[not](a-link)`.\n" +
+	"## Install\n" +
+	"```sh\n" +
+	"# not a heading\n" +
+	"go get ./...\n" +
+	"```\n" +
+	"## Usage\n" +
+	"| name | value |\n" +
+	"|:-----|------:|\n" +
+	"| a \\| b | 1 |\n" +
+	"| c |\n" +
+	"\n" +
+	"![logo](img/logo.png) <https://example.org>\n" +
+	"# Appendix\n"
+
+func TestMarkdownOps(t *testing.T) {
+	ts := store.NewTextStore()
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	src := runtime.Value{Kind: runtime.KindText, V: ts.Add(mdDoc)}
+	at := func(sp runtime.Span) string { return mdDoc[sp.Start:sp.End] }
+	text := func(v interface{}) string {
+		got, _ := ts.Get(v.(runtime.Value).V.(runtime.TextHandle))
+		return got
+	}
+
+	res, err := MdSections(s, src)
+	if err != nil {
+		t.Fatalf("MD_SECTIONS failed: %v", err)
+	}
+	secs := res.V.([]map[string]interface{})
+	if len(secs) != 4 {
+		t.Fatalf("expected 4 sections, got %d: %v", len(secs), secs)
+	}
+	if secs[0]["heading"] != "Guide" || secs[0]["level"] != 1 || at(secs[0]["span"].(runtime.Span)) != mdDoc[:len(mdDoc)-len("# Appendix\n")] {
+		t.Errorf("unexpected first section: %v", secs[0])
+	}
+	if secs[1]["heading"] != "Install" || text(secs[1]["body"]) != "```sh\n# not a heading\ngo get ./...\n```\n" {
+		t.Errorf("unexpected Install section: %v / %q", secs[1], text(secs[1]["body"]))
+	}
+
+	res, _ = MdCodeBlocks(s, src)
+	blocks := res.V.([]map[string]interface{})
+	if len(blocks) != 1 || blocks[0]["lang"] != "sh" || text(blocks[0]["body"]) != "# not a heading\ngo get ./...\n" {
+		t.Errorf("unexpected code blocks: %v", blocks)
+	}
+	if at(blocks[0]["span"].(runtime.Span)) != "```sh\n# not a heading\ngo get ./...\n```" {
+		t.Errorf("unexpected code block span: %q", at(blocks[0]["span"].(runtime.Span)))
+	}
+
+	res, err = MdTable(s, src, 0)
+	if err != nil {
+		t.Fatalf("MD_TABLE failed: %v", err)
+	}
+	rows := res.V.([]map[string]interface{})
+	if len(rows) != 2 || rows[0]["name"] != "a | b" || rows[0]["value"] != "1" || rows[1]["value"] != "" {
+		t.Errorf("unexpected table rows: %v", rows)
+	}
+	if at(rows[1]["_span"].(runtime.Span)) != "| c |" {
+		t.Errorf("unexpected row span: %q", at(rows[1]["_span"].(runtime.Span)))
+	}
+	if _, err := MdTable(s, src, 1); err == nil {
+		t.Error("expected error for missing table")
+	}
+
+	res, _ = MdLinks(s, src)
+	links := res.V.([]map[string]interface{})
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %v", links)
+	}
+	if links[0]["url"] != "https://example.com/docs" || links[0]["title"] != "Docs" || at(links[0]["span"].(runtime.Span)) != `[docs](https://example.com/docs "Docs")` {
+		t.Errorf("unexpected link: %v", links[0])
+	}
+	if links[1]["kind"] != "image" || links[1]["url"] != "img/logo.png" || links[2]["url"] != "https://example.org" {
+		t.Errorf("unexpected links: %v", links[1:])
+	}
+}
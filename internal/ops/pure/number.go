@@ -0,0 +1,162 @@
+package pure
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// numberLocale describes how a locale writes numbers.
+type numberLocale struct {
+	groups  []string // thousands separators
+	decimal string
+}
+
+// numberLocales maps the LOCALE enum of PARSE_INT and PARSE_NUMBER to its format.
+var numberLocales = map[string]numberLocale{
+	"EN": {groups: []string{","}, decimal: "."},                     // 1,234,567.89
+	"DE": {groups: []string{"."}, decimal: ","},                     // 1.234.567,89
+	"FR": {groups: []string{" ", "\u00a0", "\u202f"}, decimal: ","}, // 1 234 567,89
+	"CH": {groups: []string{"'", "’"}, decimal: "."},                // 1'234'567.89
+}
+
+// splitNumber validates a locale-formatted number and returns its sign, its
+// integer digits with separators removed and its fractional digits.
+// Separators must group the integer part in threes ("1,234", not "12,34").
+func splitNumber(op, text, locale string) (neg bool, whole, frac string, err error) {
+	loc, ok := numberLocales[locale]
+	if !ok {
+		return false, "", "", fmt.Errorf("%s: unknown locale %q", op, locale)
+	}
+	bad := func(reason string) (bool, string, string, error) {
+		return false, "", "", fmt.Errorf("%s: %q is not a %s number: %s", op, text, locale, reason)
+	}
+
+	num := strings.TrimSpace(text)
+	if strings.HasPrefix(num, "-") || strings.HasPrefix(num, "+") {
+		neg = num[0] == '-'
+		num = num[1:]
+	}
+	if i := strings.Index(num, loc.decimal); i >= 0 {
+		num, frac = num[:i], num[i+len(loc.decimal):]
+		if frac == "" || !allDigits(frac) {
+			return bad("invalid fractional part")
+		}
+	}
+
+	for _, g := range loc.groups {
+		num = strings.ReplaceAll(num, g, "\x00")
+	}
+	groups := strings.Split(num, "\x00")
+	for i, g := range groups {
+		switch {
+		case g == "" || !allDigits(g):
+			return bad("unexpected character")
+		case i > 0 && len(g) != 3, i == 0 && len(groups) > 1 && len(g) > 3:
+			return bad("thousands separators must group digits in threes")
+		}
+	}
+	return neg, strings.Join(groups, ""), frac, nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseInt implements the PARSE_INT operation.
+func ParseInt(s *runtime.Session, source runtime.Value, locale string) (runtime.Value, error) {
+	text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	neg, whole, frac, err := splitNumber("PARSE_INT", text, locale)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	if frac != "" {
+		return runtime.Value{}, fmt.Errorf("PARSE_INT: %q has a fractional part; use PARSE_NUMBER", text)
+	}
+	if neg {
+		whole = "-" + whole
+	}
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("PARSE_INT: %q overflows INT", text)
+	}
+	return runtime.Value{Kind: runtime.KindInt, V: int(n)}, nil
+}
+
+// ParseNumber implements the PARSE_NUMBER operation.
+func ParseNumber(s *runtime.Session, source runtime.Value, locale string) (runtime.Value, error) {
+	text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+	neg, whole, frac, err := splitNumber("PARSE_NUMBER", text, locale)
+	if err != nil {
+		return runtime.Value{}, err
+	}
+	lit := whole
+	if frac != "" {
+		lit += "." + frac
+	}
+	f, err := strconv.ParseFloat(lit, 64)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("PARSE_NUMBER: %q is out of range", text)
+	}
+	if neg {
+		f = -f
+	}
+	return runtime.Value{Kind: runtime.KindFloat, V: f}, nil
+}
+
+// IntArith implements the integer arithmetic operations ADD, SUB, MUL, DIV,
+// MOD, MIN and MAX. Results that do not fit in an INT are errors rather than
+// wrapping; DIV truncates toward zero and MOD takes the sign of A.
+func IntArith(s *runtime.Session, op string, a, b runtime.Value) (runtime.Value, error) {
+	x, y := a.V.(int), b.V.(int)
+	overflow := func(sym string) (runtime.Value, error) {
+		return runtime.Value{}, fmt.Errorf("%s: integer overflow: %d %s %d", op, x, sym, y)
+	}
+
+	var r int
+	switch op {
+	case "ADD":
+		if (y > 0 && x > math.MaxInt-y) || (y < 0 && x < math.MinInt-y) {
+			return overflow("+")
+		}
+		r = x + y
+	case "SUB":
+		if (y < 0 && x > math.MaxInt+y) || (y > 0 && x < math.MinInt+y) {
+			return overflow("-")
+		}
+		r = x - y
+	case "MUL":
+		if x != 0 && y != 0 {
+			r = x * y
+			if r/y != x || (x == -1 && y == math.MinInt) || (y == -1 && x == math.MinInt) {
+				return overflow("*")
+			}
+		}
+	case "DIV", "MOD":
+		if y == 0 {
+			return runtime.Value{}, fmt.Errorf("%s: division by zero", op)
+		}
+		if op == "MOD" {
+			r = x % y
+		} else if x == math.MinInt && y == -1 {
+			return overflow("/")
+		} else {
+			r = x / y
+		}
+	case "MIN":
+		r = min(x, y)
+	case "MAX":
+		r = max(x, y)
+	default:
+		return runtime.Value{}, fmt.Errorf("unknown arithmetic operation %q", op)
+	}
+	return runtime.Value{Kind: runtime.KindInt, V: r}, nil
+}
@@ -0,0 +1,103 @@
+package pure
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestParseNumbers(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	text := func(str string) runtime.Value {
+		return runtime.Value{Kind: runtime.KindText, V: ts.Add(str)}
+	}
+
+	ints := []struct {
+		in, locale string
+		want       int
+	}{
+		{" 1,234,567 ", "EN", 1234567},
+		{"-42", "EN", -42},
+		{"1.234", "DE", 1234},
+		{"1 234", "FR", 1234},
+		{"1'000", "CH", 1000},
+	}
+	for _, tt := range ints {
+		res, err := ParseInt(s, text(tt.in), tt.locale)
+		if err != nil || res.Kind != runtime.KindInt || res.V != tt.want {
+			t.Errorf("PARSE_INT %q %s: expected %d, got %v (err %v)", tt.in, tt.locale, tt.want, res.V, err)
+		}
+	}
+
+	floats := []struct {
+		in, locale string
+		want       float64
+	}{
+		{"1,234.50", "EN", 1234.5},
+		{"1.234,5", "DE", 1234.5},
+		{"-0,25", "FR", -0.25},
+	}
+	for _, tt := range floats {
+		res, err := ParseNumber(s, text(tt.in), tt.locale)
+		if err != nil || res.Kind != runtime.KindFloat || res.V != tt.want {
+			t.Errorf("PARSE_NUMBER %q %s: expected %v, got %v (err %v)", tt.in, tt.locale, tt.want, res.V, err)
+		}
+	}
+
+	errs := []struct {
+		in, locale, want string
+	}{
+		{"12,34", "EN", "in threes"},
+		{"1,234", "DE", "fractional part"},
+		{"12.5", "EN", "use PARSE_NUMBER"},
+		{"abc", "EN", "unexpected character"},
+		{"99999999999999999999", "EN", "overflows"},
+	}
+	for _, tt := range errs {
+		_, err := ParseInt(s, text(tt.in), tt.locale)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("PARSE_INT %q %s: expected error containing %q, got %v", tt.in, tt.locale, tt.want, err)
+		}
+	}
+}
+
+func TestIntArith(t *testing.T) {
+	s := runtime.NewSession(runtime.Policy{}, nil)
+	i := func(n int) runtime.Value { return runtime.Value{Kind: runtime.KindInt, V: n} }
+
+	tests := []struct {
+		op   string
+		a, b int
+		want int
+		err  string
+	}{
+		{"ADD", 2, 3, 5, ""},
+		{"SUB", 2, 3, -1, ""},
+		{"MUL", -4, 3, -12, ""},
+		{"DIV", -7, 2, -3, ""},
+		{"MOD", -7, 2, -1, ""},
+		{"MIN", 7, 2, 2, ""},
+		{"MAX", 7, 2, 7, ""},
+		{"ADD", math.MaxInt, 1, 0, "overflow"},
+		{"SUB", math.MinInt, 1, 0, "overflow"},
+		{"MUL", math.MaxInt/2 + 1, 2, 0, "overflow"},
+		{"DIV", math.MinInt, -1, 0, "overflow"},
+		{"DIV", 1, 0, 0, "division by zero"},
+		{"MOD", 1, 0, 0, "division by zero"},
+	}
+	for _, tt := range tests {
+		res, err := IntArith(s, tt.op, i(tt.a), i(tt.b))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s %d %d: expected error containing %q, got %v", tt.op, tt.a, tt.b, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || res.V != tt.want {
+			t.Errorf("%s %d %d: expected %d, got %v (err %v)", tt.op, tt.a, tt.b, tt.want, res.V, err)
+		}
+	}
+}
@@ -7,5 +7,5 @@ import (
 // OffsetAdd implements the OFFSET_ADD operation.
 func OffsetAdd(s *runtime.Session, offset runtime.Value, amount int) (runtime.Value, error) {
 	val := offset.V.(int)
-	return runtime.Value{Kind: runtime.KindOffset, V: val + amount}, nil
+	return runtime.Value{Kind: runtime.KindOffset, V: val + amount, Source: offset.Source}, nil
 }
@@ -1,7 +1,12 @@
 package pure
 
 import (
+	"cmp"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/agenthands/envllm/internal/runtime"
 )
 
@@ -50,18 +55,25 @@ func FilterRows(s *runtime.Session, source runtime.Value, key string, op string,
 		match := false
 		switch op {
 		case "==":
-			match = fmt.Sprintf("%v", rowVal) == fmt.Sprintf("%v", val.V)
+			if val.Kind == runtime.KindTimestamp {
+				c, ok := compareCell(s, rowVal, val)
+				match = ok && c == 0
+			} else {
+				match = fmt.Sprintf("%v", rowVal) == fmt.Sprintf("%v", val.V)
+			}
 		case "!=":
-			match = fmt.Sprintf("%v", rowVal) != fmt.Sprintf("%v", val.V)
+			if val.Kind == runtime.KindTimestamp {
+				c, ok := compareCell(s, rowVal, val)
+				match = !ok || c != 0
+			} else {
+				match = fmt.Sprintf("%v", rowVal) != fmt.Sprintf("%v", val.V)
+			}
 		case ">":
-			// Basic numeric compare
-			rv, _ := rowVal.(float64)
-			vv, _ := val.V.(float64)
-			match = rv > vv
+			c, ok := compareCell(s, rowVal, val)
+			match = ok && c > 0
 		case "<":
-			rv, _ := rowVal.(float64)
-			vv, _ := val.V.(float64)
-			match = rv < vv
+			c, ok := compareCell(s, rowVal, val)
+			match = ok && c < 0
 		}
 
 		if match {
@@ -72,6 +84,104 @@ func FilterRows(s *runtime.Session, source runtime.Value, key string, op string,
 	return runtime.Value{Kind: runtime.KindRows, V: result}, nil
 }
 
+// compareCell orders a row cell against a filter value. Timestamps compare
+// chronologically (date strings in the row are parsed); INT and FLOAT compare
+// numerically. ok is false when the two cannot be ordered.
+func compareCell(s *runtime.Session, cell interface{}, val runtime.Value) (int, bool) {
+	if val.Kind == runtime.KindTimestamp {
+		t, ok := asTime(cell, s.Now())
+		if !ok {
+			return 0, false
+		}
+		return t.Compare(val.V.(time.Time)), true
+	}
+	a, aok := toFloat(cell)
+	b, bok := toFloat(val.V)
+	if !aok || !bok {
+		return 0, false
+	}
+	return cmp.Compare(a, b), true
+}
+
+// SortRows implements the SORT_ROWS operation. The sort is stable. Keys are
+// compared numerically when every present value is a number, chronologically
+// when every value is a timestamp or date string, and as text otherwise; TEXT
+// cells compare by their content. Rows missing the key go last in either
+// order.
+func SortRows(s *runtime.Session, source runtime.Value, key string, order string) (runtime.Value, error) {
+	rows := source.V.([]map[string]interface{})
+
+	type keyed struct {
+		row     map[string]interface{}
+		key     interface{}
+		present bool
+	}
+	items := make([]keyed, len(rows))
+	numeric, temporal := true, true
+	now := s.Now()
+	for i, row := range rows {
+		v, ok := row[key]
+		items[i] = keyed{row: row, present: ok}
+		if !ok {
+			continue
+		}
+		v = cellValue(s, v)
+		items[i].key = v
+		if _, ok := toFloat(v); !ok {
+			numeric = false
+		}
+		if _, ok := asTime(v, now); !ok {
+			temporal = false
+		}
+	}
+
+	compare := func(a, b interface{}) int {
+		switch {
+		case numeric:
+			x, _ := toFloat(a)
+			y, _ := toFloat(b)
+			return cmp.Compare(x, y)
+		case temporal:
+			x, _ := asTime(a, now)
+			y, _ := asTime(b, now)
+			return x.Compare(y)
+		default:
+			return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.present || !b.present {
+			return a.present && !b.present
+		}
+		if order == "DESC" {
+			return compare(a.key, b.key) > 0
+		}
+		return compare(a.key, b.key) < 0
+	})
+
+	sorted := make([]map[string]interface{}, len(items))
+	for i, it := range items {
+		sorted[i] = it.row
+	}
+	return runtime.Value{Kind: runtime.KindRows, V: sorted}, nil
+}
+
+// cellValue unwraps a row cell for comparison, resolving TEXT handles to
+// their content.
+func cellValue(s *runtime.Session, v interface{}) interface{} {
+	switch c := v.(type) {
+	case runtime.Value:
+		return cellValue(s, c.V)
+	case runtime.TextHandle:
+		if text, ok := s.Stores.Text.Get(c); ok {
+			return text
+		}
+	}
+	return v
+}
+
 // AggregateRows groups and computes simple aggregates.
 func AggregateRows(s *runtime.Session, source runtime.Value, groupBy string, compute string) (runtime.Value, error) {
 	if source.Kind != runtime.KindRows {
@@ -0,0 +1,92 @@
+package pure
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/validate"
+)
+
+// ValidateJSON implements the VALIDATE_JSON operation.
+// It returns a report STRUCT {valid, error_count, errors} where each error row
+// carries the JSON pointer, failing keyword and message of one violation.
+func ValidateJSON(s *runtime.Session, source runtime.Value, schema runtime.Value) (runtime.Value, error) {
+	_, violations, err := checkSchema(s, source, schema)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("VALIDATE_JSON failed: %v", err)
+	}
+
+	rows := make([]map[string]interface{}, 0, len(violations))
+	for _, v := range violations {
+		rows = append(rows, map[string]interface{}{
+			"pointer": v.Pointer,
+			"keyword": v.Keyword,
+			"message": v.Message,
+		})
+	}
+	report := map[string]interface{}{
+		"valid":       len(violations) == 0,
+		"error_count": len(violations),
+		"errors":      rows,
+	}
+	return runtime.Value{Kind: runtime.KindStruct, V: report}, nil
+}
+
+// AssertSchema implements the ASSERT_SCHEMA operation. It returns true when
+// source matches the schema and a *runtime.SchemaViolationError otherwise.
+func AssertSchema(s *runtime.Session, source runtime.Value, schema runtime.Value) (runtime.Value, error) {
+	name, violations, err := checkSchema(s, source, schema)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("ASSERT_SCHEMA failed: %v", err)
+	}
+	if len(violations) > 0 {
+		return runtime.Value{}, &runtime.SchemaViolationError{Schema: name, Violations: violations}
+	}
+	return runtime.Value{Kind: runtime.KindBool, V: true}, nil
+}
+
+// checkSchema resolves the schema (inline document or registered name) and
+// validates source against it. TEXT sources are parsed as JSON first.
+func checkSchema(s *runtime.Session, source runtime.Value, schema runtime.Value) (string, []runtime.SchemaViolation, error) {
+	h := schema.V.(runtime.TextHandle)
+	ref, _ := s.Stores.Text.Get(h)
+
+	name, doc := "inline", strings.TrimSpace(ref)
+	if !strings.HasPrefix(doc, "{") && doc != "true" && doc != "false" {
+		name = doc
+		var ok bool
+		doc, ok = s.Schemas[name]
+		if !ok {
+			known := make([]string, 0, len(s.Schemas))
+			for k := range s.Schemas {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return name, nil, fmt.Errorf("schema %q is not registered. Registered schemas: %v", name, known)
+		}
+	}
+
+	compiled, err := validate.CompileSchema(name, doc, s.Schemas)
+	if err != nil {
+		return name, nil, err
+	}
+
+	var instance interface{}
+	if source.Kind == runtime.KindText {
+		text, _ := s.Stores.Text.Get(source.V.(runtime.TextHandle))
+		if err := json.Unmarshal([]byte(text), &instance); err != nil {
+			return name, []runtime.SchemaViolation{{Pointer: "", Keyword: "json", Message: fmt.Sprintf("not valid JSON: %v", err)}}, nil
+		}
+	} else {
+		instance, err = ToJSON(s, source)
+		if err != nil {
+			return name, nil, err
+		}
+	}
+
+	violations, err := validate.ValidateInstance(compiled, instance)
+	return name, violations, err
+}
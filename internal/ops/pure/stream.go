@@ -0,0 +1,158 @@
+package pure
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// scanChunk is the read size for streaming scans over stores that implement
+// runtime.TextRangeReader.
+const scanChunk = 1 << 20
+
+// textReaderAt adapts a handle in a runtime.TextRangeReader to io.ReaderAt.
+type textReaderAt struct {
+	rr runtime.TextRangeReader
+	h  runtime.TextHandle
+}
+
+func (r textReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return r.rr.ReadTextAt(r.h, p, off)
+}
+
+// rangeReader returns the store's range reader when it has one.
+func rangeReader(s *runtime.Session) (runtime.TextRangeReader, bool) {
+	rr, ok := s.Stores.Text.(runtime.TextRangeReader)
+	return rr, ok
+}
+
+// readRange reads [start, end) of h.
+func readRange(rr runtime.TextRangeReader, h runtime.TextHandle, start, end int) (string, error) {
+	buf := make([]byte, end-start)
+	n, err := rr.ReadTextAt(h, buf, int64(start))
+	if err == io.EOF && n == len(buf) {
+		err = nil
+	}
+	return string(buf[:n]), err
+}
+
+// streamFindText is FIND_TEXT over a range reader. Chunks overlap by
+// len(needle)-1 bytes so matches across chunk boundaries are found.
+func streamFindText(rr runtime.TextRangeReader, h runtime.TextHandle, needle string, last, ignoreCase bool) (int, error) {
+	size := h.Bytes
+	if needle == "" {
+		if last {
+			return size, nil
+		}
+		return 0, nil
+	}
+	if ignoreCase {
+		needle = foldCase(needle)
+	}
+	overlap := len(needle) - 1
+
+	search := func(start, end int) (int, error) {
+		chunk, err := readRange(rr, h, start, end)
+		if err != nil {
+			return -1, err
+		}
+		if ignoreCase {
+			chunk = foldCase(chunk)
+		}
+		if last {
+			return strings.LastIndex(chunk, needle), nil
+		}
+		return strings.Index(chunk, needle), nil
+	}
+
+	if last {
+		for end := size; end > 0; end -= scanChunk {
+			start := max(end-scanChunk-overlap, 0)
+			i, err := search(start, end)
+			if err != nil || i >= 0 {
+				return start + i, err
+			}
+		}
+		return -1, nil
+	}
+	for start := 0; start < size; start += scanChunk {
+		i, err := search(start, min(start+scanChunk+overlap, size))
+		if err != nil || i >= 0 {
+			return start + i, err
+		}
+	}
+	return -1, nil
+}
+
+// streamFindRegex returns the submatch indices of the first or last match of
+// re in h, reading through a buffered reader rather than loading the text.
+// LAST walks the matches the way regexp's FindAll does, so it picks the same
+// match as the in-memory search.
+func streamFindRegex(rr runtime.TextRangeReader, h runtime.TextHandle, re *regexp.Regexp, last bool) ([]int, error) {
+	ra := textReaderAt{rr: rr, h: h}
+	size := int64(h.Bytes)
+
+	find := func(re *regexp.Regexp, pos int64) []int {
+		m := re.FindReaderSubmatchIndex(bufio.NewReaderSize(io.NewSectionReader(ra, pos, size-pos), 64<<10))
+		for i := range m {
+			if m[i] >= 0 {
+				m[i] += int(pos)
+			}
+		}
+		return m
+	}
+	m := find(re, 0)
+	if !last || m == nil {
+		return m, nil
+	}
+
+	// A reader starting mid-text would take its first byte for the start of
+	// the text, so ^ and \b would match there. Later searches start one rune
+	// early instead and match that rune before the pattern, which gives the
+	// pattern the same context as in the whole text.
+	after, err := regexp.Compile(`(?s:.)(` + re.String() + `)`)
+	if err != nil {
+		return nil, err
+	}
+	var found []int
+	for pos, prevEnd := int64(0), -1; pos <= size; {
+		if pos > 0 {
+			prev, err := readRange(rr, h, int(max(pos-utf8.UTFMax, 0)), int(pos))
+			if err != nil {
+				return nil, err
+			}
+			_, n := utf8.DecodeLastRuneInString(prev)
+			if m = find(after, pos-int64(n)); m != nil {
+				m = m[2:]
+			}
+		}
+		if m == nil {
+			break
+		}
+		// As in FindAll, an empty match right after the previous match is
+		// skipped and an empty match advances the search by one rune.
+		accept := true
+		if int64(m[1]) == pos {
+			if m[0] == prevEnd {
+				accept = false
+			}
+			next, err := readRange(rr, h, int(pos), int(min(pos+utf8.UTFMax, size)))
+			if err != nil {
+				return nil, err
+			}
+			_, n := utf8.DecodeRuneInString(next)
+			pos += int64(max(n, 1))
+		} else {
+			pos = int64(m[1])
+		}
+		prevEnd = m[1]
+		if accept {
+			found = m
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,102 @@
+package pure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+func TestStreamingSearch(t *testing.T) {
+	ds, err := store.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	defer ds.Close()
+	s := runtime.NewSession(runtime.Policy{}, ds)
+
+	// Place needles so they straddle chunk boundaries.
+	pad := strings.Repeat(".", scanChunk-3)
+	doc := pad + "NEEDLE-1" + pad + "needle-2" + pad
+	src := runtime.Value{Kind: runtime.KindText, V: ds.Add(doc)}
+	text := func(v string) runtime.Value { return runtime.Value{Kind: runtime.KindText, V: ds.Add(v)} }
+
+	for _, tc := range []struct {
+		mode       string
+		ignoreCase bool
+		want       int
+	}{
+		{"FIRST", false, strings.Index(doc, "NEEDLE")},
+		{"LAST", false, strings.Index(doc, "NEEDLE")},
+		{"LAST", true, strings.Index(doc, "needle")},
+	} {
+		res, err := FindText(s, src, text("NEEDLE"), tc.mode, tc.ignoreCase)
+		if err != nil || res.V.(int) != tc.want {
+			t.Errorf("FIND_TEXT %s ignoreCase=%v = %v, %v; want %d", tc.mode, tc.ignoreCase, res.V, err, tc.want)
+		}
+	}
+
+	for mode, want := range map[string]string{"FIRST": "NEEDLE-1", "LAST": "needle-2"} {
+		res, err := FindRegex(s, src, text(`(?i)needle-(\d)`), mode)
+		if err != nil {
+			t.Fatalf("FIND_REGEX failed: %v", err)
+		}
+		m := res.V.(map[string]interface{})
+		sp := m["span"].(runtime.Span)
+		if doc[sp.Start:sp.End] != want || m["groups"].([]runtime.Span)[1].Start != sp.End-1 {
+			t.Errorf("FIND_REGEX %s matched %q, want %q", mode, doc[sp.Start:sp.End], want)
+		}
+	}
+	res, _ := FindRegex(s, src, text(`absent`), "FIRST")
+	if res.V.(map[string]interface{})["success"] != false {
+		t.Errorf("expected no match, got %v", res.V)
+	}
+}
+
+func TestStreamingSearchMatchesInMemory(t *testing.T) {
+	ds, err := store.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	defer ds.Close()
+	streamed := runtime.NewSession(runtime.Policy{}, ds)
+	mem := runtime.NewSession(runtime.Policy{}, &mockTextStore{content: make(map[string]string)})
+
+	doc := "alpha beta\nbeta gamma alphabet\nİSTANBUL İstanbul KELVIN K ÄÖÜ straße\n\xffend"
+	search := func(s *runtime.Session, find func(s *runtime.Session, src, arg runtime.Value) (runtime.Value, error), arg string) interface{} {
+		add := func(v string) runtime.Value { return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(v)} }
+		res, err := find(s, add(doc), add(arg))
+		if err != nil {
+			t.Fatalf("search %q failed: %v", arg, err)
+		}
+		return res.V
+	}
+
+	for _, pat := range []string{`^\w+`, `^.`, `(?m)^.`, `\b\w`, `(?m)^\w+`, `\bbeta\b`, `\Bet`, `a*`, `$`, `(?m)$`, `\w+$`, `(?i)istanbul`, `\x{FFFD}e`} {
+		for _, mode := range []string{"FIRST", "LAST"} {
+			find := func(s *runtime.Session, src, arg runtime.Value) (runtime.Value, error) {
+				return FindRegex(s, src, arg, mode)
+			}
+			if got, want := search(streamed, find, pat), search(mem, find, pat); !reflect.DeepEqual(got, want) {
+				t.Errorf("FIND_REGEX %q %s: streamed %v, in memory %v", pat, mode, got, want)
+			}
+		}
+	}
+
+	for _, needle := range []string{"istanbul", "İstanbul", "kelvin", "äöü STRASSE", "ÄÖÜ", "end"} {
+		for _, mode := range []string{"FIRST", "LAST"} {
+			find := func(s *runtime.Session, src, arg runtime.Value) (runtime.Value, error) {
+				return FindText(s, src, arg, mode, true)
+			}
+			got, want := search(streamed, find, needle), search(mem, find, needle)
+			if got != want {
+				t.Errorf("FIND_TEXT %q %s: streamed %v, in memory %v", needle, mode, got, want)
+			}
+			if i := got.(int); i >= 0 && !strings.EqualFold(doc[i:i+len(needle)], needle) {
+				t.Errorf("FIND_TEXT %q %s: offset %d points at %q", needle, mode, i, doc[i:i+len(needle)])
+			}
+		}
+	}
+}
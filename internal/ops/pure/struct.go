@@ -2,6 +2,7 @@ package pure
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/agenthands/envllm/internal/runtime"
 )
@@ -39,7 +40,11 @@ func GetField(s *runtime.Session, source runtime.Value, field string) (runtime.V
 	case int:
 		return runtime.Value{Kind: runtime.KindJSON, V: v}, nil
 	case float64:
-		return runtime.Value{Kind: runtime.KindJSON, V: int(v)}, nil
+		// Only integral numbers become ints; 1.5 must not silently become 1.
+		if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+			return runtime.Value{Kind: runtime.KindJSON, V: int(v)}, nil
+		}
+		return runtime.Value{Kind: runtime.KindJSON, V: v}, nil
 	case string:
 		return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(v)}, nil
 	case bool:
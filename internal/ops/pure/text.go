@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/agenthands/envllm/internal/runtime"
 )
@@ -24,15 +26,23 @@ func Stats(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
 // FindText implements the FIND_TEXT operation.
 func FindText(s *runtime.Session, source runtime.Value, needle runtime.Value, mode string, ignoreCase bool) (runtime.Value, error) {
 	h := source.V.(runtime.TextHandle)
-	text, _ := s.Stores.Text.Get(h)
 	n := needle.V.(runtime.TextHandle)
 	ntext, _ := s.Stores.Text.Get(n)
 
+	if rr, ok := rangeReader(s); ok {
+		pos, err := streamFindText(rr, h, ntext, mode == "LAST", ignoreCase)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("FIND_TEXT failed: %v", err)
+		}
+		return runtime.Value{Kind: runtime.KindOffset, V: pos}, nil
+	}
+	text, _ := s.Stores.Text.Get(h)
+
 	searchText := text
 	searchNeedle := ntext
 	if ignoreCase {
-		searchText = strings.ToLower(text)
-		searchNeedle = strings.ToLower(ntext)
+		searchText = foldCase(text)
+		searchNeedle = foldCase(ntext)
 	}
 
 	pos := -1
@@ -45,6 +55,24 @@ func FindText(s *runtime.Session, source runtime.Value, needle runtime.Value, mo
 	return runtime.Value{Kind: runtime.KindOffset, V: pos}, nil
 }
 
+// foldCase lowercases text for IGNORE_CASE searches. Runes whose lowercase
+// form has a different UTF-8 length (such as U+0130 or the Kelvin sign) are
+// kept, so an offset in the result is the same offset in text.
+func foldCase(text string) string {
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		if l := unicode.ToLower(r); l != r && utf8.RuneLen(l) == n {
+			sb.WriteRune(l)
+		} else {
+			sb.WriteString(text[i : i+n])
+		}
+		i += n
+	}
+	return sb.String()
+}
+
 // WindowText implements the WINDOW_TEXT operation.
 func WindowText(s *runtime.Session, source runtime.Value, center int, radius int) (runtime.Value, error) {
 	h := source.V.(runtime.TextHandle)
@@ -73,8 +101,7 @@ func SliceText(s *runtime.Session, source runtime.Value, start, end int) (runtim
 // FindRegex implements the FIND_REGEX operation.
 func FindRegex(s *runtime.Session, source runtime.Value, pattern runtime.Value, mode string) (runtime.Value, error) {
 	h := source.V.(runtime.TextHandle)
-	text, _ := s.Stores.Text.Get(h)
-	
+
 	ph := pattern.V.(runtime.TextHandle)
 	pat, _ := s.Stores.Text.Get(ph)
 
@@ -83,16 +110,23 @@ func FindRegex(s *runtime.Session, source runtime.Value, pattern runtime.Value,
 		return runtime.Value{}, fmt.Errorf("FIND_REGEX invalid pattern %q: %v", pat, err)
 	}
 
-	matches := re.FindAllStringSubmatchIndex(text, -1)
-	if len(matches) == 0 {
-		return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{"success": false}}, nil
-	}
-
 	var match []int
-	if mode == "FIRST" {
-		match = matches[0]
-	} else if mode == "LAST" {
-		match = matches[len(matches)-1]
+	if rr, ok := rangeReader(s); ok {
+		match, err = streamFindRegex(rr, h, re, mode == "LAST")
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("FIND_REGEX failed: %v", err)
+		}
+	} else {
+		text, _ := s.Stores.Text.Get(h)
+		matches := re.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) > 0 && mode == "FIRST" {
+			match = matches[0]
+		} else if len(matches) > 0 && mode == "LAST" {
+			match = matches[len(matches)-1]
+		}
+	}
+	if match == nil {
+		return runtime.Value{Kind: runtime.KindStruct, V: map[string]interface{}{"success": false}}, nil
 	}
 
 	// Create RegexMatch struct
@@ -121,8 +155,8 @@ func AfterText(s *runtime.Session, source runtime.Value, needle runtime.Value, m
 	searchText := text
 	searchNeedle := ntext
 	if ignoreCase {
-		searchText = strings.ToLower(text)
-		searchNeedle = strings.ToLower(ntext)
+		searchText = foldCase(text)
+		searchNeedle = foldCase(ntext)
 	}
 
 	pos := -1
@@ -272,20 +306,28 @@ func ExtractValue(s *runtime.Session, source runtime.Value, key runtime.Value, u
 // GetSpanStart implements the GET_SPAN_START operation.
 func GetSpanStart(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
 	span := source.V.(runtime.Span)
-	return runtime.Value{Kind: runtime.KindOffset, V: span.Start}, nil
+	return runtime.Value{Kind: runtime.KindOffset, V: span.Start, Source: span.Source}, nil
 }
 
 // GetSpanEnd implements the GET_SPAN_END operation.
 func GetSpanEnd(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
 	span := source.V.(runtime.Span)
-	return runtime.Value{Kind: runtime.KindOffset, V: span.End}, nil
+	return runtime.Value{Kind: runtime.KindOffset, V: span.End, Source: span.Source}, nil
 }
 
 // ConcatText implements the CONCAT_TEXT operation.
 func ConcatText(s *runtime.Session, a, b runtime.Value) (runtime.Value, error) {
 	ha := a.V.(runtime.TextHandle)
 	hb := b.V.(runtime.TextHandle)
-	
+
+	if c, ok := s.Stores.Text.(runtime.TextConcatenator); ok {
+		h, err := c.Concat(ha, hb)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("CONCAT_TEXT failed: %v", err)
+		}
+		return runtime.Value{Kind: runtime.KindText, V: h}, nil
+	}
+
 	ta, _ := s.Stores.Text.Get(ha)
 	tb, _ := s.Stores.Text.Get(hb)
 	
@@ -307,11 +349,36 @@ func Offset(s *runtime.Session, val int) (runtime.Value, error) {
 }
 
 // Span implements the SPAN constructor.
-func Span(s *runtime.Session, start, end int) (runtime.Value, error) {
-	return runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: start, End: end}}, nil
+// The span takes its source from START and END. When they come from
+// different texts derived from the same original, END is translated into
+// START's text; offsets from unrelated texts are rejected.
+func Span(s *runtime.Session, start, end runtime.Value) (runtime.Value, error) {
+	sp := runtime.Span{Start: start.V.(int), End: end.V.(int), Source: start.Source}
+	switch {
+	case end.Source == "" || end.Source == start.Source:
+	case start.Source == "":
+		sp.Source = end.Source
+	default:
+		startRoot, startBase := s.Origin(start.Source)
+		endRoot, endBase := s.Origin(end.Source)
+		if startRoot != endRoot {
+			return runtime.Value{}, fmt.Errorf("SPAN: START is from TEXT %s but END is from unrelated TEXT %s", start.Source, end.Source)
+		}
+		sp.End += endBase - startBase
+	}
+	return runtime.Value{Kind: runtime.KindSpan, V: sp}, nil
 }
 
 // AsSpan implements the AS_SPAN operation.
-func AsSpan(s *runtime.Session, offset, length int) (runtime.Value, error) {
-	return runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: offset, End: offset + length}}, nil
+func AsSpan(s *runtime.Session, offset runtime.Value, length int) (runtime.Value, error) {
+	start := offset.V.(int)
+	return runtime.Value{Kind: runtime.KindSpan, V: runtime.Span{Start: start, End: start + length, Source: offset.Source}}, nil
+}
+
+// ResolveSpan implements the RESOLVE_SPAN operation.
+// It maps a span of a window or slice, however deeply nested, back to the
+// text the chain started from; the result names that text as its source.
+// Spans of original texts and spans without a source are returned as is.
+func ResolveSpan(s *runtime.Session, span runtime.Value) (runtime.Value, error) {
+	return runtime.Value{Kind: runtime.KindSpan, V: s.ResolveSpan(span.V.(runtime.Span))}, nil
 }
@@ -0,0 +1,98 @@
+package pure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// mapText applies fn to the content of a TEXT handle and returns a new handle.
+func mapText(s *runtime.Session, source runtime.Value, fn func(string) string) (runtime.Value, error) {
+	h := source.V.(runtime.TextHandle)
+	text, _ := s.Stores.Text.Get(h)
+	return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(fn(text))}, nil
+}
+
+// TrimText implements the TRIM_TEXT operation.
+func TrimText(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return mapText(s, source, strings.TrimSpace)
+}
+
+// LowerText implements the LOWER_TEXT operation.
+func LowerText(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return mapText(s, source, strings.ToLower)
+}
+
+// UpperText implements the UPPER_TEXT operation.
+func UpperText(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return mapText(s, source, strings.ToUpper)
+}
+
+// NormalizeWhitespace implements the NORMALIZE_WHITESPACE operation.
+// Runs of whitespace (including newlines) collapse to a single space and the
+// result is trimmed.
+func NormalizeWhitespace(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return mapText(s, source, func(text string) string {
+		return strings.Join(strings.Fields(text), " ")
+	})
+}
+
+// ReplaceText implements the REPLACE_TEXT operation (all occurrences).
+func ReplaceText(s *runtime.Session, source, old, new runtime.Value) (runtime.Value, error) {
+	o, _ := s.Stores.Text.Get(old.V.(runtime.TextHandle))
+	n, _ := s.Stores.Text.Get(new.V.(runtime.TextHandle))
+	if o == "" {
+		return runtime.Value{}, fmt.Errorf("REPLACE_TEXT: OLD must not be empty")
+	}
+	return mapText(s, source, func(text string) string {
+		return strings.ReplaceAll(text, o, n)
+	})
+}
+
+// backrefRe matches sed-style group references (\1) in a replacement.
+var backrefRe = regexp.MustCompile(`\\(\d+)`)
+
+// ReplaceRegex implements the REPLACE_REGEX operation.
+// The replacement may reference groups as $1, ${1}, ${name} or \1.
+func ReplaceRegex(s *runtime.Session, source, pattern, replacement runtime.Value) (runtime.Value, error) {
+	pat, _ := s.Stores.Text.Get(pattern.V.(runtime.TextHandle))
+	repl, _ := s.Stores.Text.Get(replacement.V.(runtime.TextHandle))
+
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return runtime.Value{}, fmt.Errorf("REPLACE_REGEX invalid pattern %q: %v", pat, err)
+	}
+	repl = backrefRe.ReplaceAllString(repl, `$${$1}`)
+
+	return mapText(s, source, func(text string) string {
+		return re.ReplaceAllString(text, repl)
+	})
+}
+
+// quotePairs lists the opening and closing quote characters STRIP_QUOTES removes.
+var quotePairs = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'`':  '`',
+	'“':  '”',
+	'‘':  '’',
+	'«':  '»',
+}
+
+// StripQuotes implements the STRIP_QUOTES operation.
+// Surrounding whitespace is trimmed, then one matching pair of quotes is
+// removed if the text both starts and ends with it.
+func StripQuotes(s *runtime.Session, source runtime.Value) (runtime.Value, error) {
+	return mapText(s, source, func(text string) string {
+		text = strings.TrimSpace(text)
+		open, n := utf8.DecodeRuneInString(text)
+		close, ok := quotePairs[open]
+		if !ok || len(text) < n+utf8.RuneLen(close) || !strings.HasSuffix(text, string(close)) {
+			return text
+		}
+		return text[n : len(text)-utf8.RuneLen(close)]
+	})
+}
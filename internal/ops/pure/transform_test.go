@@ -0,0 +1,62 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestTextTransforms(t *testing.T) {
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	text := func(str string) runtime.Value {
+		return runtime.Value{Kind: runtime.KindText, V: ts.Add(str)}
+	}
+
+	tests := []struct {
+		name string
+		run  func() (runtime.Value, error)
+		want string
+	}{
+		{"trim", func() (runtime.Value, error) { return TrimText(s, text("  ACME Corp \n")) }, "ACME Corp"},
+		{"lower", func() (runtime.Value, error) { return LowerText(s, text("ÄBC")) }, "äbc"},
+		{"upper", func() (runtime.Value, error) { return UpperText(s, text("abc")) }, "ABC"},
+		{"normalize", func() (runtime.Value, error) { return NormalizeWhitespace(s, text(" a \t b\n\nc ")) }, "a b c"},
+		{"replace", func() (runtime.Value, error) { return ReplaceText(s, text("1,234,567"), text(","), text("")) }, "1234567"},
+		{"regex dollar", func() (runtime.Value, error) {
+			return ReplaceRegex(s, text("2024-03-09"), text(`(\d+)-(\d+)-(\d+)`), text("$3/$2/$1"))
+		}, "09/03/2024"},
+		{"regex named", func() (runtime.Value, error) {
+			return ReplaceRegex(s, text("Total: 12."), text(`(?P<n>\d+)\.$`), text("${n}"))
+		}, "Total: 12"},
+		{"regex backslash", func() (runtime.Value, error) {
+			return ReplaceRegex(s, text("id=7"), text(`id=(\d)`), text(`\1x`))
+		}, "7x"},
+		{"strip double", func() (runtime.Value, error) { return StripQuotes(s, text(` "ACME" `)) }, "ACME"},
+		{"strip curly", func() (runtime.Value, error) { return StripQuotes(s, text("“ACME”")) }, "ACME"},
+		{"strip unmatched", func() (runtime.Value, error) { return StripQuotes(s, text(`"ACME'`)) }, `"ACME'`},
+		{"strip lone quote", func() (runtime.Value, error) { return StripQuotes(s, text(`"`)) }, `"`},
+	}
+
+	for _, tt := range tests {
+		res, err := tt.run()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if res.Kind != runtime.KindText {
+			t.Errorf("%s: expected TEXT, got %s", tt.name, res.Kind)
+			continue
+		}
+		if got, _ := ts.Get(res.V.(runtime.TextHandle)); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := ReplaceRegex(s, text("x"), text("("), text("")); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if _, err := ReplaceText(s, text("x"), text(""), text("y")); err == nil {
+		t.Error("expected error for empty OLD")
+	}
+}
@@ -7,44 +7,56 @@ import (
 	"github.com/agenthands/envllm/internal/runtime"
 )
 
+// Args holds the arguments of an op call by keyword. Every param of the
+// signature is present: params a statement left out hold their default.
+type Args map[string]runtime.Value
+
 // OpImplementation is the function signature for operation logic.
-type OpImplementation func(s *runtime.Session, args []runtime.Value) (runtime.Value, error)
+type OpImplementation func(s *runtime.Session, args Args) (runtime.Value, error)
 
 // Registry maps operation names to their implementations and metadata.
 type Registry struct {
-	Table *Table
-	impls map[string]OpImplementation
+	Table  *Table
+	impls  map[string]OpImplementation
+	owners map[string]string
 }
 
-func NewRegistry(tbl *Table) *Registry {
+// NewRegistry returns a registry holding the default modules. It fails if
+// tbl defines one of their ops differently from the module.
+func NewRegistry(tbl *Table) (*Registry, error) {
 	r := &Registry{
-		Table: tbl,
-		impls: make(map[string]OpImplementation),
+		Table:  tbl,
+		impls:  make(map[string]OpImplementation),
+		owners: make(map[string]string),
+	}
+	for _, m := range DefaultModules() {
+		if err := r.RegisterModule(m); err != nil {
+			return nil, err
+		}
 	}
-	r.RegisterModule(&CoreModule{})
-	r.RegisterModule(&FSModule{})
-	r.RegisterModule(&WebModule{})
-	return r
+	return r, nil
 }
 
 // Dispatch implements runtime.OpDispatcher.
 func (r *Registry) Dispatch(s *runtime.Session, name string, args []ast.KwArg) (runtime.Value, error) {
-	// 1. Validate signature and evaluate args
-	var vargs []ValidatedKwArg
+	// 1. Bind and evaluate args
 	opDef, ok := r.Table.Ops[name]
 	if !ok {
 		return runtime.Value{}, fmt.Errorf("unknown operation: %s", name)
 	}
 
-	if len(args) != len(opDef.Signature) {
-		return runtime.Value{}, fmt.Errorf("%s: expected %d arguments, got %d", name, len(opDef.Signature), len(args))
+	keywords := make([]string, len(args))
+	for i, arg := range args {
+		keywords[i] = arg.Keyword
+	}
+	if _, err := opDef.Bind(keywords); err != nil {
+		return runtime.Value{}, err
 	}
 
-	for i, arg := range args {
-		param := opDef.Signature[i]
-		
+	vals := make(Args, len(args))
+	for _, arg := range args {
+		param, _ := opDef.Param(arg.Keyword)
 		var val runtime.Value
-		var err error
 
 		// If it's an enum, we allow raw identifiers as strings
 		if len(param.Enum) > 0 {
@@ -55,20 +67,54 @@ func (r *Registry) Dispatch(s *runtime.Session, name string, args []ast.KwArg) (
 
 		// If not already resolved as enum identifier, evaluate it
 		if val.Kind == "" {
+			var err error
 			val, err = s.EvalExpr(arg.Value)
 			if err != nil {
 				return runtime.Value{}, err
 			}
 		}
+		vals[arg.Keyword] = val
+	}
+
+	return r.Call(s, name, vals)
+}
+
+// Call runs op name on evaluated arguments, as Dispatch does once it has
+// evaluated a statement's clauses. Params missing from args take their
+// defaults. Ops composed from other ops run their steps through it.
+func (r *Registry) Call(s *runtime.Session, name string, args Args) (runtime.Value, error) {
+	opDef, ok := r.Table.Ops[name]
+	if !ok {
+		return runtime.Value{}, fmt.Errorf("unknown operation: %s", name)
+	}
+	for kw := range args {
+		if _, ok := opDef.Param(kw); !ok {
+			return runtime.Value{}, fmt.Errorf("%s: unknown keyword %s", name, kw)
+		}
+	}
+
+	var vargs []ValidatedKwArg
+	for _, param := range opDef.Signature {
+		val, ok := args[param.Kw]
+		if !ok {
+			if !param.Omittable() {
+				return runtime.Value{}, fmt.Errorf("%s: missing keyword %s", name, param.Kw)
+			}
+			// Left out: use the param's default
+			var err error
+			if val, err = param.DefaultValue(); err != nil {
+				return runtime.Value{}, fmt.Errorf("%s: %v", name, err)
+			}
+		}
 
 		// Promote STRING to TEXT if needed
 		if param.Type == runtime.KindText && val.Kind == runtime.KindString && s.Stores.Text != nil {
 			h := s.Stores.Text.Add(val.V.(string))
 			val = runtime.Value{Kind: runtime.KindText, V: h}
 		}
-		vargs = append(vargs, ValidatedKwArg{Keyword: arg.Keyword, Value: val})
+		vargs = append(vargs, ValidatedKwArg{Keyword: param.Kw, Value: val})
 	}
-	
+
 	op, err := r.Table.ValidateSignature(name, vargs)
 	if err != nil {
 		return runtime.Value{}, err
@@ -92,21 +138,26 @@ func (r *Registry) Dispatch(s *runtime.Session, name string, args []ast.KwArg) (
 		return runtime.Value{}, fmt.Errorf("operation %q has no implementation", name)
 	}
 
-	// 4. Prepare positional args for implementation
-	var posArgs []runtime.Value
+	// 4. Prepare keyword args for implementation
+	kwArgs := make(Args, len(vargs))
 	for _, v := range vargs {
-		posArgs = append(posArgs, v.Value)
+		kwArgs[v.Keyword] = v.Value
 	}
 
-	// 5. Execute
-	res, err := impl(s, posArgs)
+	// 5. Execute, on each document when SOURCE is a DOCS collection
+	var res runtime.Value
+	if kwArgs["SOURCE"].Kind == runtime.KindDocs && op.TakesDocs("SOURCE") {
+		res, err = eachDoc(s, op, impl, kwArgs)
+	} else {
+		res, err = callAnchored(s, op, impl, kwArgs)
+	}
 	if err != nil {
 		return runtime.Value{}, err
 	}
 
 	// 6. Final type check
-	if op.ResultType != "" && res.Kind != op.ResultType {
-		return runtime.Value{}, fmt.Errorf("%s: result type mismatch: expected %s, got %s", name, op.ResultType, res.Kind)
+	if want := op.ResultTypeFor(kwArgs["SOURCE"].Kind); want != "" && res.Kind != want {
+		return runtime.Value{}, fmt.Errorf("%s: result type mismatch: expected %s, got %s", name, want, res.Kind)
 	}
 
 	return res, nil
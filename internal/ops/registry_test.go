@@ -46,7 +46,7 @@ func exprToKwArg(kw string, expr ast.Expr) ast.KwArg {
 
 func TestSubcall(t *testing.T) {
 	tbl, _ := LoadTable("../../assets/ops.json")
-	reg := NewRegistry(tbl)
+	reg := mustRegistry(t, tbl)
 	ts := &mockTextStore{content: make(map[string]string)}
 	s := runtime.NewSession(runtime.Policy{
 		MaxSubcalls: 2, 
@@ -92,7 +92,7 @@ func TestSubcall(t *testing.T) {
 
 func TestSubcall_BudgetExceeded(t *testing.T) {
 	tbl, _ := LoadTable("../../assets/ops.json")
-	reg := NewRegistry(tbl)
+	reg := mustRegistry(t, tbl)
 	ts := &mockTextStore{content: make(map[string]string)}
 	s := runtime.NewSession(runtime.Policy{
 		MaxSubcalls: 1, 
@@ -149,7 +149,7 @@ func TestSubcall_BudgetExceeded(t *testing.T) {
 
 func TestCapabilityGating(t *testing.T) {
 	tbl, _ := LoadTable("../../assets/ops.json")
-	reg := NewRegistry(tbl)
+	reg := mustRegistry(t, tbl)
 	ts := &mockTextStore{content: make(map[string]string)}
 	
 	// Policy without 'llm' capability
@@ -202,7 +202,7 @@ func TestTable_ValidateSignature_Errors(t *testing.T) {
 
 func TestRegistry_Dispatch_Errors(t *testing.T) {
 	tbl, _ := LoadTable("../../assets/ops.json")
-	reg := NewRegistry(tbl)
+	reg := mustRegistry(t, tbl)
 	ts := &mockTextStore{content: make(map[string]string)}
 	s := runtime.NewSession(runtime.Policy{}, ts)
 
@@ -224,7 +224,7 @@ func TestRegistry_Dispatch_Errors(t *testing.T) {
 
 	// Test Result type mismatch
 	reg.RegisterModule(&CoreModule{}) // restore STATS
-	reg.impls["STATS"] = func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+	reg.impls["STATS"] = func(s *runtime.Session, args Args) (runtime.Value, error) {
 		return runtime.Value{Kind: runtime.KindInt, V: 1}, nil
 	}
 	_, err = reg.Dispatch(s, "STATS", args)
@@ -235,7 +235,7 @@ func TestRegistry_Dispatch_Errors(t *testing.T) {
 
 func TestRegistry_Dispatch_AllPure(t *testing.T) {
 	tbl, _ := LoadTable("../../assets/ops.json")
-	reg := NewRegistry(tbl)
+	reg := mustRegistry(t, tbl)
 	ts := &mockTextStore{content: make(map[string]string)}
 	s := runtime.NewSession(runtime.Policy{}, ts)
 	
@@ -268,6 +268,41 @@ func TestRegistry_Dispatch_AllPure(t *testing.T) {
 	}
 }
 
+func TestRegistry_Dispatch_KeywordArgs(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	reg := mustRegistry(t, tbl)
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+	s.Env.Define("h", runtime.Value{Kind: runtime.KindText, V: ts.Add("Hello hello")})
+
+	// MODE and IGNORE_CASE take their defaults, and clause order does not
+	// matter to the runtime.
+	res, err := reg.Dispatch(s, "FIND_TEXT", []ast.KwArg{
+		exprToKwArg("NEEDLE", &ast.StringExpr{Value: "hello"}),
+		exprToKwArg("SOURCE", &ast.IdentExpr{Name: "h"}),
+	})
+	if err != nil || res.Kind != runtime.KindOffset || res.V != 6 {
+		t.Errorf("expected offset 6, got %v %v", res, err)
+	}
+
+	res, err = reg.Dispatch(s, "FIND_TEXT", []ast.KwArg{
+		exprToKwArg("SOURCE", &ast.IdentExpr{Name: "h"}),
+		exprToKwArg("NEEDLE", &ast.StringExpr{Value: "hello"}),
+		exprToKwArg("IGNORE_CASE", &ast.BoolExpr{Value: true}),
+	})
+	if err != nil || res.V != 0 {
+		t.Errorf("expected offset 0, got %v %v", res, err)
+	}
+
+	_, err = reg.Dispatch(s, "FIND_TEXT", []ast.KwArg{
+		exprToKwArg("SOURCE", &ast.IdentExpr{Name: "h"}),
+		exprToKwArg("MODE", &ast.IdentExpr{Name: "LAST"}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing keyword NEEDLE") {
+		t.Errorf("expected missing keyword error, got %v", err)
+	}
+}
+
 func TestLoadTable_Error(t *testing.T) {
 	_, err := LoadTable("non-existent.json")
 	if err == nil {
@@ -281,3 +316,68 @@ func TestLoadTable_Error(t *testing.T) {
 		t.Errorf("expected error for invalid JSON")
 	}
 }
+
+func mustRegistry(t *testing.T, tbl *Table) *Registry {
+	t.Helper()
+	reg, err := NewRegistry(tbl)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	return reg
+}
+
+func TestAnchorSpans_CopiesOnWrite(t *testing.T) {
+	hit := map[string]interface{}{"span": runtime.Span{Start: 1, End: 2}, "n": 1}
+	plain := map[string]interface{}{"id": "a"}
+	rows := []map[string]interface{}{plain, hit}
+
+	got, changed := anchorSpans(rows, "t:1")
+	if !changed {
+		t.Fatal("expected the span to be anchored")
+	}
+	out := got.([]map[string]interface{})
+	if sp := out[1]["span"].(runtime.Span); sp.Source != "t:1" {
+		t.Errorf("expected the span to name its source, got %+v", sp)
+	}
+	if sp := hit["span"].(runtime.Span); sp.Source != "" {
+		t.Errorf("expected the input row to stay unchanged, got %+v", sp)
+	}
+	if rows[1]["span"].(runtime.Span).Source != "" {
+		t.Error("expected the input slice to stay unchanged")
+	}
+
+	if got, changed := anchorSpans(plain, "t:1"); changed || fmt.Sprintf("%p", got) != fmt.Sprintf("%p", plain) {
+		t.Error("expected a value without spans to be returned as is")
+	}
+}
+
+func TestRegistry_AnchorsOnSource(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	reg := mustRegistry(t, tbl)
+	ts := &mockTextStore{content: make(map[string]string)}
+	s := runtime.NewSession(runtime.Policy{}, ts)
+
+	// FIND_TEXT results point into SOURCE.
+	doc := ts.Add("say hello")
+	res, err := reg.Call(s, "FIND_TEXT", Args{
+		"SOURCE": {Kind: runtime.KindText, V: doc},
+		"NEEDLE": {Kind: runtime.KindString, V: "hello"},
+	})
+	if err != nil || res.Source != doc.ID {
+		t.Errorf("expected an offset into SOURCE, got %+v %v", res, err)
+	}
+
+	// FIELD is a TEXT param too, but spans GET_FIELD returns do not point
+	// into it.
+	rec := map[string]interface{}{"span": runtime.Span{Start: 1, End: 2}}
+	res, err = reg.Call(s, "GET_FIELD", Args{
+		"SOURCE": {Kind: runtime.KindStruct, V: rec},
+		"FIELD":  {Kind: runtime.KindString, V: "span"},
+	})
+	if err != nil {
+		t.Fatalf("GET_FIELD failed: %v", err)
+	}
+	if sp, ok := res.V.(runtime.Span); !ok || sp.Source != "" {
+		t.Errorf("expected the span to stay unanchored, got %+v", res.V)
+	}
+}
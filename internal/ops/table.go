@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/agenthands/envllm/assets"
+	"github.com/agenthands/envllm/internal/ast"
 	"github.com/agenthands/envllm/internal/runtime"
 )
 
@@ -14,20 +17,188 @@ type Table struct {
 	Ops     map[string]*Op `json:"-"`
 }
 
-// Op represents a single operation definition.
+// Op represents a single operation definition. An op with Docs set also
+// takes a DOCS collection as its TEXT SOURCE: it then runs on the text of
+// every document and returns ROWS, one per document with a hit.
 type Op struct {
 	Name         string   `json:"name"`
 	Capabilities []string `json:"capabilities"`
 	ResultType   runtime.Kind  `json:"result_type"`
 	Signature    []Param  `json:"signature"`
 	Into         bool     `json:"into"`
+	Docs         bool     `json:"docs,omitempty"`
 }
 
-// Param represents a keyword-type pair in an operation signature.
+// Param represents a keyword-type pair in an operation signature. A param
+// that is Optional or has a Default may be left out of a statement: it then
+// takes its Default, read as a literal of the param's type, or NULL when it
+// has none. Arguments are matched to params by keyword; the signature order
+// is the canonical order the formatter writes them in.
 type Param struct {
-	Kw   string       `json:"kw"`
-	Type runtime.Kind `json:"type,omitempty"`
-	Enum []string     `json:"enum,omitempty"`
+	Kw       string       `json:"kw"`
+	Type     runtime.Kind `json:"type,omitempty"`
+	Enum     []string     `json:"enum,omitempty"`
+	Optional bool         `json:"optional,omitempty"`
+	Default  string       `json:"default,omitempty"`
+}
+
+// Omittable reports whether p may be left out of a statement.
+func (p Param) Omittable() bool {
+	return p.Optional || p.Default != ""
+}
+
+// DefaultValue returns the value a left-out p takes. A Default that does not
+// parse for p's type, or is not one of its Enum values, is an error.
+func (p Param) DefaultValue() (runtime.Value, error) {
+	if p.Default == "" {
+		return runtime.Value{Kind: runtime.KindNull}, nil
+	}
+	if len(p.Enum) > 0 {
+		for _, e := range p.Enum {
+			if e == p.Default {
+				return runtime.Value{Kind: runtime.KindString, V: p.Default}, nil
+			}
+		}
+		return runtime.Value{}, fmt.Errorf("%s default %q is not one of %v", p.Kw, p.Default, p.Enum)
+	}
+	switch p.Type {
+	case runtime.KindBool:
+		b, err := strconv.ParseBool(p.Default)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("%s default %q is not a BOOL", p.Kw, p.Default)
+		}
+		return runtime.Value{Kind: runtime.KindBool, V: b}, nil
+	case runtime.KindInt:
+		n, err := strconv.Atoi(p.Default)
+		if err != nil {
+			return runtime.Value{}, fmt.Errorf("%s default %q is not an INT", p.Kw, p.Default)
+		}
+		return runtime.Value{Kind: runtime.KindInt, V: n}, nil
+	case runtime.KindText, runtime.KindString, "":
+		return runtime.Value{Kind: runtime.KindString, V: p.Default}, nil
+	}
+	return runtime.Value{}, fmt.Errorf("%s: %s params cannot have a default", p.Kw, p.Type)
+}
+
+// DefaultExpr returns the literal the formatter writes for a left-out p.
+func (p Param) DefaultExpr() ast.Expr {
+	v, err := p.DefaultValue()
+	if err != nil {
+		return &ast.NullExpr{Kind: "NULL"}
+	}
+	switch x := v.V.(type) {
+	case bool:
+		return &ast.BoolExpr{Kind: "BOOL", Value: x}
+	case int:
+		return &ast.IntExpr{Kind: "INT", Value: x}
+	case string:
+		if len(p.Enum) > 0 {
+			return &ast.IdentExpr{Kind: "IDENT", Name: x}
+		}
+		return &ast.StringExpr{Kind: "STRING", Value: x}
+	}
+	return &ast.NullExpr{Kind: "NULL"}
+}
+
+// Param returns the param of op with the given keyword.
+func (op *Op) Param(kw string) (Param, bool) {
+	for _, p := range op.Signature {
+		if p.Kw == kw {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// MinArgs returns the number of arguments a statement must give: the
+// params that cannot be left out.
+func (op *Op) MinArgs() int {
+	n := 0
+	for _, p := range op.Signature {
+		if !p.Omittable() {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckArgCount reports an error unless a statement giving n arguments fits
+// op's signature.
+func (op *Op) CheckArgCount(n int) error {
+	min, max := op.MinArgs(), len(op.Signature)
+	if n >= min && n <= max {
+		return nil
+	}
+	if min == max {
+		return fmt.Errorf("%s: expected %d arguments, got %d", op.Name, max, n)
+	}
+	return fmt.Errorf("%s: expected %d to %d arguments, got %d", op.Name, min, max, n)
+}
+
+// Bind matches the keywords of a statement's arguments to op's params. For
+// each param it returns the index of the argument giving it, or -1 if the
+// param was left out. Unknown or repeated keywords and left-out params that
+// are not omittable are errors.
+func (op *Op) Bind(keywords []string) ([]int, error) {
+	idx := make([]int, len(op.Signature))
+	for i := range idx {
+		idx[i] = -1
+	}
+	for j, kw := range keywords {
+		i := op.paramIndex(kw)
+		if i < 0 {
+			return nil, fmt.Errorf("%s: unknown keyword %s", op.Name, kw)
+		}
+		if idx[i] >= 0 {
+			return nil, fmt.Errorf("%s: keyword %s given twice", op.Name, kw)
+		}
+		idx[i] = j
+	}
+	for i, p := range op.Signature {
+		if idx[i] < 0 && !p.Omittable() {
+			return nil, fmt.Errorf("%s: missing keyword %s", op.Name, p.Kw)
+		}
+	}
+	return idx, nil
+}
+
+// InOrder reports whether keywords follow the canonical signature order.
+// They must bind to op.
+func (op *Op) InOrder(keywords []string) bool {
+	last := -1
+	for _, kw := range keywords {
+		i := op.paramIndex(kw)
+		if i < last {
+			return false
+		}
+		last = i
+	}
+	return true
+}
+
+// TakesDocs reports whether the param kw of op accepts a DOCS collection
+// in place of its TEXT.
+func (op *Op) TakesDocs(kw string) bool {
+	p, ok := op.Param(kw)
+	return op.Docs && kw == "SOURCE" && ok && p.Type == runtime.KindText
+}
+
+// ResultTypeFor returns the kind op produces when its SOURCE has the given
+// kind: ROWS when it runs over a DOCS collection, ResultType otherwise.
+func (op *Op) ResultTypeFor(source runtime.Kind) runtime.Kind {
+	if source == runtime.KindDocs && op.TakesDocs("SOURCE") {
+		return runtime.KindRows
+	}
+	return op.ResultType
+}
+
+func (op *Op) paramIndex(kw string) int {
+	for i, p := range op.Signature {
+		if p.Kw == kw {
+			return i
+		}
+	}
+	return -1
 }
 
 // LoadTable reads and parses the ops.json file.
@@ -36,13 +207,39 @@ func LoadTable(path string) (*Table, error) {
 	if err != nil {
 		return nil, err
 	}
+	t, err := ParseTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return t, nil
+}
+
+// LoadTableOrDefault loads the ops table at path, or the built-in table
+// when path is empty.
+func LoadTableOrDefault(path string) (*Table, error) {
+	if path == "" {
+		return DefaultTable()
+	}
+	return LoadTable(path)
+}
 
+// DefaultTable returns a fresh copy of the built-in ops table embedded from
+// assets/ops.json.
+func DefaultTable() (*Table, error) {
+	return ParseTable(assets.OpsJSON)
+}
+
+// ParseTable parses and checks an ops table in the ops.json format.
+func ParseTable(data []byte) (*Table, error) {
 	var raw struct {
 		Version string `json:"version"`
 		Ops     []Op   `json:"ops"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid ops table: %v", err)
+	}
+	if raw.Version == "" {
+		return nil, fmt.Errorf("invalid ops table: missing version")
 	}
 
 	t := &Table{
@@ -50,7 +247,21 @@ func LoadTable(path string) (*Table, error) {
 		Ops:     make(map[string]*Op),
 	}
 
-	for _, op := range raw.Ops {
+	for i, op := range raw.Ops {
+		if op.Name == "" {
+			return nil, fmt.Errorf("invalid ops table: op %d has no name", i)
+		}
+		if _, ok := t.Ops[op.Name]; ok {
+			return nil, fmt.Errorf("invalid ops table: op %s is defined twice", op.Name)
+		}
+		for j, p := range op.Signature {
+			if p.Kw == "" {
+				return nil, fmt.Errorf("invalid ops table: %s: param %d has no keyword", op.Name, j)
+			}
+			if _, err := p.DefaultValue(); err != nil {
+				return nil, fmt.Errorf("invalid ops table: %s: %v", op.Name, err)
+			}
+		}
 		o := op
 		t.Ops[op.Name] = &o
 	}
@@ -71,18 +282,24 @@ func (t *Table) ValidateSignature(name string, args []ValidatedKwArg) (*Op, erro
 		return nil, fmt.Errorf("unknown operation: %s", name)
 	}
 
-	if len(args) != len(op.Signature) {
-		return nil, fmt.Errorf("%s: expected %d arguments, got %d", name, len(op.Signature), len(args))
+	keywords := make([]string, len(args))
+	for i, a := range args {
+		keywords[i] = a.Keyword
+	}
+	if _, err := op.Bind(keywords); err != nil {
+		return nil, err
 	}
 
-	for i, param := range op.Signature {
-		arg := args[i]
-		if arg.Keyword != param.Kw {
-			return nil, fmt.Errorf("%s: argument %d keyword mismatch: expected %s, got %s", name, i, param.Kw, arg.Keyword)
+	for _, arg := range args {
+		param, _ := op.Param(arg.Keyword)
+
+		// A left-out optional param without a default is NULL
+		if arg.Value.Kind == runtime.KindNull && param.Optional && param.Default == "" {
+			continue
 		}
 
 		// Type checking
-		if param.Type != "" && arg.Value.Kind != param.Type {
+		if param.Type != "" && arg.Value.Kind != param.Type && !(arg.Value.Kind == runtime.KindDocs && op.TakesDocs(param.Kw)) {
 			return nil, fmt.Errorf("%s: argument %s type mismatch: expected %s, got %s", name, param.Kw, param.Type, arg.Value.Kind)
 		}
 
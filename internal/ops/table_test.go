@@ -1,7 +1,10 @@
 package ops
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
 	"github.com/agenthands/envllm/internal/runtime"
 )
 
@@ -90,3 +93,91 @@ func TestTable_ValidateSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultTable(t *testing.T) {
+	tbl, err := DefaultTable()
+	if err != nil {
+		t.Fatalf("DefaultTable failed: %v", err)
+	}
+	file, _ := LoadTable("../../assets/ops.json")
+	if d := Diff(file, tbl); len(d) > 0 {
+		t.Errorf("embedded table differs from assets/ops.json: %v", d)
+	}
+
+	// Each call returns a copy that registries may extend.
+	tbl.Ops["STATS"].ResultType = runtime.KindInt
+	again, _ := LoadTableOrDefault("")
+	if again.Ops["STATS"].ResultType != runtime.KindStruct {
+		t.Errorf("expected a fresh copy of the built-in table")
+	}
+}
+
+func TestParseTable_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":            `ops`,
+		"missing version":     `{"ops": []}`,
+		"unnamed op":          `{"version": "0.2", "ops": [{"name": ""}]}`,
+		"duplicate op":        `{"version": "0.2", "ops": [{"name": "A"}, {"name": "A"}]}`,
+		"param without kw":    `{"version": "0.2", "ops": [{"name": "A", "signature": [{"type": "TEXT"}]}]}`,
+		"bad default":         `{"version": "0.2", "ops": [{"name": "A", "signature": [{"kw": "N", "type": "INT", "default": "x"}]}]}`,
+		"bad bool default":    `{"version": "0.2", "ops": [{"name": "A", "signature": [{"kw": "B", "type": "BOOL", "default": "yes"}]}]}`,
+		"default not in enum": `{"version": "0.2", "ops": [{"name": "A", "signature": [{"kw": "MODE", "enum": ["FIRST", "LAST"], "default": "ALL"}]}]}`,
+	}
+	for name, data := range tests {
+		if _, err := ParseTable([]byte(data)); err == nil || !strings.Contains(err.Error(), "invalid ops table") {
+			t.Errorf("%s: expected invalid ops table error, got %v", name, err)
+		}
+	}
+}
+
+func TestOp_Bind(t *testing.T) {
+	tbl, _ := LoadTable("../../assets/ops.json")
+	find := tbl.Ops["FIND_TEXT"]
+
+	idx, err := find.Bind([]string{"NEEDLE", "SOURCE", "IGNORE_CASE"})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if want := []int{1, 0, -1, 2}; !reflect.DeepEqual(idx, want) {
+		t.Errorf("expected %v, got %v", want, idx)
+	}
+	if find.InOrder([]string{"NEEDLE", "SOURCE"}) || !find.InOrder([]string{"SOURCE", "NEEDLE", "IGNORE_CASE"}) {
+		t.Error("InOrder disagrees with the signature order")
+	}
+
+	for frag, kws := range map[string][]string{
+		"unknown keyword X":          {"SOURCE", "NEEDLE", "X"},
+		"keyword SOURCE given twice": {"SOURCE", "SOURCE", "NEEDLE"},
+		"missing keyword NEEDLE":     {"SOURCE", "MODE"},
+	} {
+		if _, err := find.Bind(kws); err == nil || !strings.Contains(err.Error(), frag) {
+			t.Errorf("expected %q, got %v", frag, err)
+		}
+	}
+}
+
+func TestParam_DefaultValue(t *testing.T) {
+	tests := []struct {
+		p    Param
+		want runtime.Value
+	}{
+		{Param{Kw: "A", Type: runtime.KindText, Optional: true}, runtime.Value{Kind: runtime.KindNull}},
+		{Param{Kw: "A", Type: runtime.KindBool, Default: "false"}, runtime.Value{Kind: runtime.KindBool, V: false}},
+		{Param{Kw: "A", Type: runtime.KindInt, Default: "3"}, runtime.Value{Kind: runtime.KindInt, V: 3}},
+		{Param{Kw: "A", Enum: []string{"X", "Y"}, Default: "Y"}, runtime.Value{Kind: runtime.KindString, V: "Y"}},
+		{Param{Kw: "A", Type: runtime.KindText, Default: "hi"}, runtime.Value{Kind: runtime.KindString, V: "hi"}},
+	}
+	for _, tc := range tests {
+		got, err := tc.p.DefaultValue()
+		if err != nil || got != tc.want {
+			t.Errorf("%+v: expected %v, got %v %v", tc.p, tc.want, got, err)
+		}
+	}
+
+	if _, err := (Param{Kw: "A", Type: runtime.KindRows, Default: "x"}).DefaultValue(); err == nil {
+		t.Error("expected ROWS params to reject a default")
+	}
+	if _, err := (Param{Kw: "A", Enum: []string{"X", "Y"}, Default: "Z"}).DefaultValue(); err == nil || !strings.Contains(err.Error(), "not one of") {
+		t.Errorf("expected a default outside the enum to be rejected, got %v", err)
+	}
+}
@@ -44,13 +44,13 @@ func (m *WebModule) Operations() []Op {
 func (m *WebModule) Handlers() map[string]OpImplementation {
 	// For now, these are just mocks to demonstrate the registry
 	return map[string]OpImplementation{
-		"NAVIGATE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"NAVIGATE": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			return runtime.Value{Kind: runtime.KindBool, V: true}, nil
 		},
-		"CLICK": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"CLICK": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			return runtime.Value{Kind: runtime.KindBool, V: true}, nil
 		},
-		"TYPE": func(s *runtime.Session, args []runtime.Value) (runtime.Value, error) {
+		"TYPE": func(s *runtime.Session, args Args) (runtime.Value, error) {
 			return runtime.Value{Kind: runtime.KindBool, V: true}, nil
 		},
 	}
@@ -448,11 +448,39 @@ func (p *baseParser) parseExpr() (ast.Expr, error) {
 		e := &ast.NullExpr{Loc: p.curToken.Loc, Kind: "NULL"}
 		p.nextToken()
 		return e, nil
+	case lex.TypeLBracket:
+		return p.parseList()
 	default:
 		return nil, fmt.Errorf("%s: expected expression, got %v", p.curToken.Loc, p.curToken.Type)
 	}
 }
 
+func (p *baseParser) parseList() (*ast.ListExpr, error) {
+	e := &ast.ListExpr{Loc: p.curToken.Loc, Kind: "LIST"}
+	p.nextToken() // [
+
+	for p.curToken.Type != lex.TypeRBracket {
+		if p.curToken.Type == lex.TypeLBracket {
+			return nil, fmt.Errorf("%s: nested lists are not supported", p.curToken.Loc)
+		}
+		item, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		e.Items = append(e.Items, item)
+
+		if p.curToken.Type == lex.TypeComma {
+			p.nextToken()
+			continue
+		}
+		if p.curToken.Type != lex.TypeRBracket {
+			return nil, fmt.Errorf("%s: expected ',' or ']' in list, got %v (%q)", p.curToken.Loc, p.curToken.Type, p.curToken.Value)
+		}
+	}
+	p.nextToken() // ]
+	return e, nil
+}
+
 func (p *baseParser) parseSetFinal() (*ast.SetFinalStmt, error) {
 	stmt := &ast.SetFinalStmt{Loc: p.curToken.Loc, Type: "set_final"}
 	p.nextToken()
@@ -465,6 +493,14 @@ func (p *baseParser) parseSetFinal() (*ast.SetFinalStmt, error) {
 		return nil, err
 	}
 	stmt.Source = val
+	if p.curToken.Value == "CITING" {
+		p.nextToken()
+		citing, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Citing = citing
+	}
 	if err := p.expectNewline(); err != nil {
 		return nil, err
 	}
@@ -92,6 +92,8 @@ func TestParser_Errors(t *testing.T) {
 		{"Invalid expression", "CELL plan:\n PRINT SOURCE @\n"},
 		{"Invalid indentation", "CELL plan:\nPRINT SOURCE x\n"},
 		{"Too much indentation", "CELL plan:\n   PRINT SOURCE x\n"},
+		{"Unterminated list", "CELL plan:\n PRINT SOURCE [1, 2\n"},
+		{"Nested list", "CELL plan:\n PRINT SOURCE [[1]]\n"},
 	}
 
 	for _, tt := range tests {
@@ -110,6 +112,39 @@ func TestParser_Errors(t *testing.T) {
 	}
 }
 
+func TestParser_ListLiteral(t *testing.T) {
+	input := `RLMDSL 0.1
+CELL plan:
+  JSON_OBJECT KEYS ["name", "total"] VALUES [name, 42] INTO rec
+  JSON_ARRAY ITEMS [] INTO empty
+`
+	l := lex.NewLexer("list.rlm", input)
+	p := NewParser(l, ModeCompat)
+	prog, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	cell := prog.Task.Body[0].(*ast.Cell)
+	op := cell.Stmts[0].(*ast.OpStmt)
+	keys, ok := op.Args[0].Value.(*ast.ListExpr)
+	if !ok || len(keys.Items) != 2 {
+		t.Fatalf("expected KEYS list of 2 items, got %#v", op.Args[0].Value)
+	}
+	vals := op.Args[1].Value.(*ast.ListExpr)
+	if _, ok := vals.Items[0].(*ast.IdentExpr); !ok {
+		t.Errorf("expected identifier item, got %T", vals.Items[0])
+	}
+	if _, ok := vals.Items[1].(*ast.IntExpr); !ok {
+		t.Errorf("expected int item, got %T", vals.Items[1])
+	}
+
+	empty := cell.Stmts[1].(*ast.OpStmt).Args[0].Value.(*ast.ListExpr)
+	if len(empty.Items) != 0 {
+		t.Errorf("expected empty list, got %d items", len(empty.Items))
+	}
+}
+
 func TestParser_StrictMode(t *testing.T) {
 	tests := []struct {
 		name    string
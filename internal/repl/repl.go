@@ -16,17 +16,28 @@ import (
 
 const PROMPT = "rlm> "
 
-// Start starts the REPL.
+// Start starts the REPL with the built-in ops table.
 func Start(in io.Reader, out io.Writer) {
+	if err := StartWithOpsTable(in, out, ""); err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+	}
+}
+
+// StartWithOpsTable starts the REPL with the ops table at path, or the
+// built-in table when path is empty. It fails if the table is invalid.
+func StartWithOpsTable(in io.Reader, out io.Writer, path string) error {
 	scanner := bufio.NewScanner(in)
 	
 	// Setup session
 	ts := store.NewTextStore()
-	tbl, err := ops.LoadTable("assets/ops.json")
+	tbl, err := ops.LoadTableOrDefault(path)
+	if err != nil {
+		return fmt.Errorf("failed to load ops table: %v", err)
+	}
+	reg, err := ops.NewRegistry(tbl)
 	if err != nil {
-		tbl, _ = ops.LoadTable("../../assets/ops.json")
+		return fmt.Errorf("failed to load ops table: %v", err)
 	}
-	reg := ops.NewRegistry(tbl)
 	session := runtime.NewSession(runtime.Policy{MaxStmtsPerCell: 100}, ts)
 	session.Dispatcher = reg
 
@@ -37,12 +48,12 @@ func Start(in io.Reader, out io.Writer) {
 		fmt.Fprint(out, PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
-			return
+			return nil
 		}
 
 		line := scanner.Text()
 		if line == "exit" {
-			return
+			return nil
 		}
 
 		if line == "" {
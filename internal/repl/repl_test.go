@@ -57,3 +57,11 @@ func TestREPL_Error(t *testing.T) {
 		t.Errorf("expected error in output, got %q", output)
 	}
 }
+
+func TestREPL_InvalidOpsTable(t *testing.T) {
+	var out bytes.Buffer
+	err := StartWithOpsTable(strings.NewReader("exit\n"), &out, "missing.json")
+	if err == nil || !strings.Contains(err.Error(), "failed to load ops table") {
+		t.Errorf("expected ops table error, got %v", err)
+	}
+}
@@ -106,6 +106,9 @@ func (r *DotAccessRule) Apply(ctx context.Context, prog *ast.Program, matches []
 						if id, ok := s.Source.(*ast.IdentExpr); ok && id == targetIdent {
 							s.Source = &ast.IdentExpr{Name: newName, Kind: "IDENT"}
 						}
+						if id, ok := s.Citing.(*ast.IdentExpr); ok && id == targetIdent {
+							s.Citing = &ast.IdentExpr{Name: newName, Kind: "IDENT"}
+						}
 					}
 				}
 				newCell.Stmts = append(newCell.Stmts, stmt)
@@ -56,12 +56,12 @@ func (r *UndefinedToLiteralRule) Match(ctx context.Context, prog *ast.Program, e
 				continue
 			}
 
-			for i, arg := range op.Args {
+			for _, arg := range op.Args {
 				if id, ok := arg.Value.(*ast.IdentExpr); ok {
 					if !symbols[id.Name] {
 						// Undefined! Check if expected type is TEXT or STRING or similar
-						param := opDef.Signature[i]
-						if param.Type == "TEXT" || param.Type == "" { // "" often means ANY/STRING in some signatures
+						param, known := opDef.Param(arg.Keyword)
+						if known && param.Type == "TEXT" || param.Type == "" { // "" often means ANY/STRING in some signatures
 							matches = append(matches, MatchResult{Node: id})
 						}
 					}
@@ -0,0 +1,30 @@
+package runtime
+
+// A DOCS value is a collection of documents, held as a
+// []map[string]interface{} like ROWS so that FOR_EACH can iterate it. Each
+// item has an id (string), metadata (map[string]interface{}) and text (a
+// TEXT Value).
+
+// NewDoc returns a DOCS item.
+func NewDoc(id string, metadata map[string]interface{}, text TextHandle) map[string]interface{} {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":       id,
+		"metadata": metadata,
+		"text":     Value{Kind: KindText, V: text},
+	}
+}
+
+// DocText returns the TEXT handle of a DOCS item.
+func DocText(doc map[string]interface{}) (TextHandle, bool) {
+	switch t := doc["text"].(type) {
+	case Value:
+		h, ok := t.V.(TextHandle)
+		return h, ok
+	case TextHandle:
+		return t, true
+	}
+	return TextHandle{}, false
+}
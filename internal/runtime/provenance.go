@@ -0,0 +1,111 @@
+package runtime
+
+import "fmt"
+
+// DeriveHandle records that child holds the bytes of parent starting at
+// start, as produced by a window or slice. A child that is parent itself
+// is returned unchanged.
+func DeriveHandle(parent, child TextHandle, start int) TextHandle {
+	if child.ID == parent.ID {
+		return child
+	}
+	child.Parent = parent.ID
+	child.Base = start
+	child.Root, child.RootBase = parent.Origin()
+	child.RootBase += start
+	return child
+}
+
+// Origin returns the root text h was derived from and the offset of h's
+// first byte in it. A text that was not derived is its own root.
+func (h TextHandle) Origin() (string, int) {
+	if h.Root == "" {
+		return h.ID, 0
+	}
+	return h.Root, h.RootBase
+}
+
+// trackLineage remembers the lineage of derived handles held by v, so that
+// offsets naming them as their source can be translated later. Stores give
+// each derived range its own ID, so an ID seen again has the same origin.
+func (s *Session) trackLineage(v interface{}) {
+	for _, h := range appendHandles(nil, v) {
+		if h.Root == "" {
+			continue
+		}
+		if s.lineage == nil {
+			s.lineage = make(map[string]TextHandle)
+		}
+		if _, ok := s.lineage[h.ID]; !ok {
+			s.lineage[h.ID] = h
+		}
+	}
+}
+
+// Origin returns the root text of the text with the given ID and the offset
+// of its first byte in that root.
+func (s *Session) Origin(id string) (string, int) {
+	if h, ok := s.lineage[id]; ok {
+		return h.Origin()
+	}
+	return id, 0
+}
+
+// Rebase translates pos, an offset into the text source, into an offset
+// into target. Both texts must derive from the same root and the result
+// must fall inside target. Unanchored offsets (empty source) and negative
+// "not found" offsets are returned unchanged.
+func (s *Session) Rebase(source string, pos int, target TextHandle) (int, error) {
+	if source == "" || source == target.ID || pos < 0 {
+		return pos, nil
+	}
+	s.trackLineage(target)
+	srcRoot, srcBase := s.Origin(source)
+	dstRoot, dstBase := s.Origin(target.ID)
+	if srcRoot != dstRoot {
+		return 0, fmt.Errorf("offset from TEXT %s cannot be used with TEXT %s: they do not derive from the same text", source, target.ID)
+	}
+	translated := pos + srcBase - dstBase
+	if translated < 0 || translated > target.Bytes {
+		return 0, fmt.Errorf("offset %d of TEXT %s falls outside TEXT %s", pos, source, target.ID)
+	}
+	return translated, nil
+}
+
+// ResolveSpan maps sp to coordinates in the root text its source derives
+// from.
+func (s *Session) ResolveSpan(sp Span) Span {
+	if sp.Source == "" {
+		return sp
+	}
+	root, base := s.Origin(sp.Source)
+	return Span{Start: sp.Start + base, End: sp.End + base, Source: root}
+}
+
+// citations converts the CITING value of SET_FINAL, a CITE result or a LIST
+// or ROWS of them, into the citations reported with the result.
+func citations(v Value) ([]map[string]interface{}, error) {
+	var out []map[string]interface{}
+	switch x := v.V.(type) {
+	case map[string]interface{}:
+		out = append(out, x)
+	case []map[string]interface{}:
+		out = append(out, x...)
+	case []Value:
+		for _, e := range x {
+			m, ok := e.V.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("SET_FINAL: CITING list holds %s, expected CITE results", e.Kind)
+			}
+			out = append(out, m)
+		}
+	default:
+		return nil, fmt.Errorf("SET_FINAL: CITING expects a CITE result or a LIST or ROWS of them, got %s", v.Kind)
+	}
+	for _, c := range out {
+		if _, ok := c["hash"]; !ok {
+			return nil, fmt.Errorf("SET_FINAL: CITING value is not a CITE result")
+		}
+	}
+	return out, nil
+}
@@ -6,16 +6,17 @@ import (
 
 // ExecResult represents the result of executing an RLM program or cell.
 type ExecResult struct {
-	SchemaVersion string                 `json:"schema_version"`
-	Cell          CellInfo               `json:"cell"`
-	Status        string                 `json:"status"`
-	VarsDelta     map[string]Value       `json:"vars_delta"`
-	Result        *Value                 `json:"result,omitempty"`
-	Final         *Value                 `json:"final,omitempty"`
-	Budgets       map[string]BudgetStats `json:"budgets"`
-	Events        []Event                `json:"events"`
-	Errors        []Error                `json:"errors"`
-	Truncated     TruncationFlags        `json:"truncated"`
+	SchemaVersion string                   `json:"schema_version"`
+	Cell          CellInfo                 `json:"cell"`
+	Status        string                   `json:"status"`
+	VarsDelta     map[string]Value         `json:"vars_delta"`
+	Result        *Value                   `json:"result,omitempty"`
+	Final         *Value                   `json:"final,omitempty"`
+	Citations     []map[string]interface{} `json:"citations,omitempty"`
+	Budgets       map[string]BudgetStats   `json:"budgets"`
+	Events        []Event                  `json:"events"`
+	Errors        []Error                  `json:"errors"`
+	Truncated     TruncationFlags          `json:"truncated"`
 }
 
 type CellInfo struct {
@@ -33,7 +33,39 @@ func (e *ExtensionVersionUnsupportedError) Error() string {
 	return fmt.Sprintf("ERR_EXTENSION_VERSION_UNSUPPORTED: %s (requested %s, available %s)", e.Extension, e.Requested, e.Available)
 }
 
-// TextStore interface.
+// SchemaViolation describes a single JSON Schema failure.
+type SchemaViolation struct {
+	Pointer string `json:"pointer"` // JSON pointer into the validated instance
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// SchemaViolationError is returned by ASSERT_SCHEMA when data does not match its schema.
+type SchemaViolationError struct {
+	Schema     string
+	Violations []SchemaViolation
+}
+
+// String formats the violation as "pointer: message", using "/" for the document root.
+func (v SchemaViolation) String() string {
+	p := v.Pointer
+	if p == "" {
+		p = "/"
+	}
+	return fmt.Sprintf("%s: %s", p, v.Message)
+}
+
+func (e *SchemaViolationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("ERR_SCHEMA_VIOLATION: %d violation(s) of schema %q: %s", len(e.Violations), e.Schema, strings.Join(parts, "; "))
+}
+
+// TextStore interface. Handles returned by Window and Slice must have an ID
+// that stands for one range of one root text: offsets and spans name their
+// source by ID, and the session translates them by that ID's lineage.
 type TextStore interface {
 	Add(text string) TextHandle
 	Get(h TextHandle) (string, bool)
@@ -41,6 +73,38 @@ type TextStore interface {
 	Slice(h TextHandle, start, end int) (TextHandle, error)
 }
 
+// TextConcatenator is implemented by text stores that can join two handles
+// without copying their content.
+type TextConcatenator interface {
+	Concat(a, b TextHandle) (TextHandle, error)
+}
+
+// TextRangeReader is implemented by text stores that can read part of a text
+// without materializing all of it. It follows io.ReaderAt semantics.
+type TextRangeReader interface {
+	ReadTextAt(h TextHandle, p []byte, off int64) (int, error)
+}
+
+// TextFileLoader is implemented by text stores that can add a file's content
+// without reading it into memory.
+type TextFileLoader interface {
+	AddFile(path string) (TextHandle, error)
+}
+
+// TextCollector is implemented by text stores that can free handles a
+// session no longer references. The session calls Collect at the end of
+// each cell with the handles reachable from its variables and final value.
+type TextCollector interface {
+	Collect(live []TextHandle)
+}
+
+// TextMemoryLimiter is implemented by text stores with a memory limit.
+// OverLimit returns an error while the limit is exceeded; the session then
+// fails with a BudgetExceededError.
+type TextMemoryLimiter interface {
+	OverLimit() error
+}
+
 // OpDispatcher allows the runtime to execute operations defined elsewhere.
 type OpDispatcher interface {
 	Dispatch(s *Session, name string, args []ast.KwArg) (Value, error)
@@ -85,6 +149,8 @@ type Session struct {
 	}
 	Policy Policy
 	Final  *Value
+	// Citations attached to the final value by SET_FINAL ... CITING
+	Citations []map[string]interface{}
 	
 	// Stats for budgeting
 	StmtsExecuted  int
@@ -106,6 +172,70 @@ type Session struct {
 	Host Host
 	// Trace Sink
 	TraceSink trace.Sink
+	// Named JSON schemas registered by the host (name -> schema document)
+	Schemas map[string]string
+	// Clock supplies "now" for date ops; nil means time.Now
+	Clock func() time.Time
+
+	// lineage holds derived handles by ID, for translating offsets
+	lineage map[string]TextHandle
+}
+
+// collectText lets a collecting text store free handles that no variable
+// or the final value refers to.
+func (s *Session) collectText() {
+	c, ok := s.Stores.Text.(TextCollector)
+	if !ok {
+		return
+	}
+	var live []TextHandle
+	for _, v := range s.Env.vars {
+		live = appendHandles(live, v)
+	}
+	if s.Final != nil {
+		live = appendHandles(live, *s.Final)
+	}
+	c.Collect(live)
+}
+
+// appendHandles appends the text handles held by v, including those nested
+// in structs, rows and lists.
+func appendHandles(out []TextHandle, v interface{}) []TextHandle {
+	switch x := v.(type) {
+	case Value:
+		return appendHandles(out, x.V)
+	case *Value:
+		if x != nil {
+			return appendHandles(out, x.V)
+		}
+	case TextHandle:
+		return append(out, x)
+	case map[string]interface{}:
+		for _, e := range x {
+			out = appendHandles(out, e)
+		}
+	case []map[string]interface{}:
+		for _, e := range x {
+			out = appendHandles(out, e)
+		}
+	case []Value:
+		for _, e := range x {
+			out = appendHandles(out, e)
+		}
+	case []interface{}:
+		for _, e := range x {
+			out = appendHandles(out, e)
+		}
+	}
+	return out
+}
+
+// Now returns the current time according to the session clock.
+func (s *Session) Now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
 }
 
 func (s *Session) emitTrace(step trace.TraceStep) {
@@ -179,6 +309,7 @@ func (s *Session) GenerateResult(status string, errors []Error) ExecResult {
 		Status:    status,
 		VarsDelta: s.VarsDelta,
 		Final:     s.Final,
+		Citations: s.Citations,
 		Events:    s.Events,
 		Errors:    errors,
 	}
@@ -271,7 +402,8 @@ func (s *Session) ExecuteIf(ctx context.Context, stmt *ast.IfStmt) error {
 func (s *Session) ExecuteCell(ctx context.Context, cell *ast.Cell) error {
 	s.StartTime = time.Now()
 	s.CurrentCell = cell.Name
-	
+	defer s.collectText()
+
 	for _, stmt := range cell.Stmts {
 		if err := s.ExecuteStmt(ctx, stmt); err != nil {
 			return err
@@ -300,6 +432,15 @@ func (s *Session) ExecuteStmt(ctx context.Context, stmt ast.Stmt) error {
 			return err
 		}
 		s.Final = &val
+		if st.Citing != nil {
+			cites, err := s.EvalExpr(st.Citing)
+			if err != nil {
+				return err
+			}
+			if s.Citations, err = citations(cites); err != nil {
+				return err
+			}
+		}
 	case *ast.PrintStmt:
 		val, err := s.EvalExpr(st.Source)
 		if err != nil {
@@ -311,8 +452,8 @@ func (s *Session) ExecuteStmt(ctx context.Context, stmt ast.Stmt) error {
 		if !ok {
 			return fmt.Errorf("undefined collection: %s", st.Collection)
 		}
-		if collVal.Kind != KindRows {
-			return fmt.Errorf("FOR_EACH expects ROWS, got %s", collVal.Kind)
+		if collVal.Kind != KindRows && collVal.Kind != KindDocs {
+			return fmt.Errorf("FOR_EACH expects ROWS or DOCS, got %s", collVal.Kind)
 		}
 		
 		rows := collVal.V.([]map[string]interface{})
@@ -367,6 +508,14 @@ func (s *Session) ExecuteStmt(ctx context.Context, stmt ast.Stmt) error {
 		}
 		
 		res, err := s.Dispatcher.Dispatch(s, st.OpName, st.Args)
+		if err == nil {
+			s.trackLineage(res)
+			if lim, ok := s.Stores.Text.(TextMemoryLimiter); ok {
+				if lerr := lim.OverLimit(); lerr != nil {
+					err = &BudgetExceededError{Message: lerr.Error()}
+				}
+			}
+		}
 		if err != nil {
 			s.emitTrace(trace.TraceStep{
 				Op:       st.OpName,
@@ -419,6 +568,16 @@ func (s *Session) EvalExpr(expr ast.Expr) (Value, error) {
 		return Value{Kind: KindBool, V: e.Value}, nil
 	case *ast.NullExpr:
 		return Value{Kind: KindNull, V: nil}, nil
+	case *ast.ListExpr:
+		items := make([]Value, 0, len(e.Items))
+		for _, item := range e.Items {
+			v, err := s.EvalExpr(item)
+			if err != nil {
+				return Value{}, err
+			}
+			items = append(items, v)
+		}
+		return Value{Kind: KindList, V: items}, nil
 	default:
 		return Value{}, fmt.Errorf("unknown expression type: %T", expr)
 	}
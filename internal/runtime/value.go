@@ -3,44 +3,60 @@ package runtime
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Kind represents the type of an RLM value.
 type Kind string
 
 const (
-	KindInt    Kind = "INT"
-	KindBool   Kind = "BOOL"
-	KindText   Kind = "TEXT"
-	KindJSON   Kind = "JSON"
-	KindSpan   Kind = "SPAN"
-	KindString Kind = "STRING"
-	KindList   Kind = "LIST"
-	KindNull   Kind = "NULL"
-	KindOffset Kind = "OFFSET"
-	KindCost   Kind = "COST"
-	KindStruct Kind = "STRUCT"
-	KindRows   Kind = "ROWS"
+	KindInt       Kind = "INT"
+	KindFloat     Kind = "FLOAT"
+	KindBool      Kind = "BOOL"
+	KindText      Kind = "TEXT"
+	KindJSON      Kind = "JSON"
+	KindSpan      Kind = "SPAN"
+	KindString    Kind = "STRING"
+	KindList      Kind = "LIST"
+	KindNull      Kind = "NULL"
+	KindOffset    Kind = "OFFSET"
+	KindCost      Kind = "COST"
+	KindStruct    Kind = "STRUCT"
+	KindRows      Kind = "ROWS"
+	KindTimestamp Kind = "TIMESTAMP"
+	KindBytes     Kind = "BYTES"
+	KindDocs      Kind = "DOCS"
 )
 
 // Value represents a typed value in the RLM runtime.
 type Value struct {
 	Kind Kind
 	V    interface{}
+	// Source is the ID of the TEXT an OFFSET points into, if known.
+	Source string
 }
 
-// Span represents a range in text.
+// Span represents a range in text. Source is the ID of the TEXT the range
+// belongs to; spans built from plain integers have none.
 type Span struct {
-	Start int `json:"start"`
-	End   int `json:"end"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Source string `json:"source,omitempty"`
 }
 
-// TextHandle represents a reference to text in the TextStore.
+// TextHandle represents a reference to text in the TextStore. Handles made
+// by windowing or slicing another text record where they came from: Parent
+// and Base locate the handle in the text it was cut from, Root and RootBase
+// in the original text at the start of the chain.
 type TextHandle struct {
 	ID           string `json:"id"`
 	Bytes        int    `json:"bytes"`
 	Preview      string `json:"preview,omitempty"`
 	PreviewBytes int    `json:"preview_bytes,omitempty"`
+	Parent       string `json:"parent,omitempty"`
+	Base         int    `json:"base,omitempty"`
+	Root         string `json:"root,omitempty"`
+	RootBase     int    `json:"root_base,omitempty"`
 }
 
 // KwArg represents a keyword-value pair in a statement.
@@ -50,27 +66,32 @@ type KwArg struct {
 }
 
 // MarshalJSON implements custom JSON encoding for Value as required by product-guidelines.md.
+// BYTES values ([]byte) are encoded as base64 strings.
 func (v Value) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Kind Kind        `json:"kind"`
-		V    interface{} `json:"v"`
+		Kind   Kind        `json:"kind"`
+		V      interface{} `json:"v"`
+		Source string      `json:"source,omitempty"`
 	}{
-		Kind: v.Kind,
-		V:    v.V,
+		Kind:   v.Kind,
+		V:      v.V,
+		Source: v.Source,
 	})
 }
 
 // UnmarshalJSON implements custom JSON decoding for Value.
 func (v *Value) UnmarshalJSON(data []byte) error {
 	var raw struct {
-		Kind Kind            `json:"kind"`
-		V    json.RawMessage `json:"v"`
+		Kind   Kind            `json:"kind"`
+		V      json.RawMessage `json:"v"`
+		Source string          `json:"source"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
 	}
 
 	v.Kind = raw.Kind
+	v.Source = raw.Source
 	switch v.Kind {
 	case KindInt:
 		var i int
@@ -78,6 +99,12 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		v.V = i
+	case KindFloat:
+		var f float64
+		if err := json.Unmarshal(raw.V, &f); err != nil {
+			return err
+		}
+		v.V = f
 	case KindBool:
 		var b bool
 		if err := json.Unmarshal(raw.V, &b); err != nil {
@@ -140,6 +167,36 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		v.V = l
+	case KindTimestamp:
+		var t time.Time
+		if err := json.Unmarshal(raw.V, &t); err != nil {
+			return err
+		}
+		v.V = t
+	case KindBytes:
+		var b []byte
+		if err := json.Unmarshal(raw.V, &b); err != nil {
+			return err
+		}
+		v.V = b
+	case KindDocs:
+		var docs []struct {
+			ID       string                 `json:"id"`
+			Metadata map[string]interface{} `json:"metadata"`
+			Text     Value                  `json:"text"`
+		}
+		if err := json.Unmarshal(raw.V, &docs); err != nil {
+			return err
+		}
+		items := make([]map[string]interface{}, len(docs))
+		for i, d := range docs {
+			h, ok := d.Text.V.(TextHandle)
+			if !ok {
+				return fmt.Errorf("DOCS item %q: text must be TEXT, got %s", d.ID, d.Text.Kind)
+			}
+			items[i] = NewDoc(d.ID, d.Metadata, h)
+		}
+		v.V = items
 	default:
 		return fmt.Errorf("unknown value kind: %s", v.Kind)
 	}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestValueJSON(t *testing.T) {
@@ -16,6 +17,16 @@ func TestValueJSON(t *testing.T) {
 			val:  Value{Kind: KindInt, V: 450},
 			want: `{"kind":"INT","v":450}`,
 		},
+		{
+			name: "FLOAT",
+			val:  Value{Kind: KindFloat, V: 1234.5},
+			want: `{"kind":"FLOAT","v":1234.5}`,
+		},
+		{
+			name: "TIMESTAMP",
+			val:  Value{Kind: KindTimestamp, V: time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+			want: `{"kind":"TIMESTAMP","v":"2025-03-04T10:00:00Z"}`,
+		},
 		{
 			name: "BOOL",
 			val:  Value{Kind: KindBool, V: true},
@@ -31,6 +42,11 @@ func TestValueJSON(t *testing.T) {
 			val:  Value{Kind: KindText, V: TextHandle{ID: "t1", Bytes: 100}},
 			want: `{"kind":"TEXT","v":{"id":"t1","bytes":100}}`,
 		},
+		{
+			name: "BYTES",
+			val:  Value{Kind: KindBytes, V: []byte{0xff, 0x00, 'h', 'i'}},
+			want: `{"kind":"BYTES","v":"/wBoaQ=="}`,
+		},
 		{
 			name: "LIST",
 			val: Value{Kind: KindList, V: []Value{
@@ -73,6 +89,17 @@ func TestValueUnmarshal_JSON(t *testing.T) {
 	}
 }
 
+func TestValueUnmarshal_Float(t *testing.T) {
+	data := `{"kind":"FLOAT","v":3}`
+	var v Value
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if f, ok := v.V.(float64); v.Kind != KindFloat || !ok || f != 3 {
+		t.Errorf("expected FLOAT 3, got %s %T", v.Kind, v.V)
+	}
+}
+
 func TestValueUnmarshal_Span(t *testing.T) {
 	data := `{"kind":"SPAN","v":{"start":10,"end":20}}`
 	var v Value
@@ -106,3 +133,16 @@ func TestValueUnmarshal_Error(t *testing.T) {
 		t.Errorf("expected error for unknown kind")
 	}
 }
+
+func TestValueUnmarshal_Bytes(t *testing.T) {
+	var v Value
+	if err := json.Unmarshal([]byte(`{"kind":"BYTES","v":"/wBoaQ=="}`), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if b, ok := v.V.([]byte); !ok || string(b) != "\xff\x00hi" {
+		t.Errorf("expected raw bytes, got %#v", v.V)
+	}
+	if err := json.Unmarshal([]byte(`{"kind":"BYTES","v":"not base64!"}`), &v); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+}
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// segmentSize is the size at which DiskStore starts a new segment file. A
+// single text larger than this gets a segment of its own.
+const segmentSize = 1 << 30
+
+type segment struct {
+	f    *os.File
+	size int64
+}
+
+// diskText locates a text, or a slice of one, inside a segment. Text that
+// could not be written has no segment and is read from mem instead.
+type diskText struct {
+	seg *segment
+	mem string
+	off int64
+	n   int64
+}
+
+func (t diskText) readAt(p []byte, off int64) (int, error) {
+	if t.seg == nil {
+		return copy(p, t.mem[t.off+off:t.off+t.n]), nil
+	}
+	return t.seg.f.ReadAt(p, t.off+off)
+}
+
+type diskViewKey struct {
+	seg    *segment
+	off, n int64
+}
+
+// DiskStore is a TextStore that keeps text in append-only segment files
+// instead of memory. Added texts and files are streamed to the end of the
+// current segment; Get, Window and Slice locate text by segment offset and
+// read it with pread, so slices never copy and only Get materializes
+// content. Texts added with identical content share a handle.
+//
+// DiskStore implements runtime.TextFileLoader and runtime.TextRangeReader,
+// which READ_FILE, FIND_TEXT and FIND_REGEX use to avoid loading whole texts.
+// It is safe for concurrent use.
+type DiskStore struct {
+	mu     sync.Mutex
+	dir    string
+	temp   bool
+	segs   []*segment
+	texts  map[string]diskText
+	byHash map[[sha256.Size]byte]string
+	views  map[diskViewKey]string
+	next   int
+}
+
+// NewDiskStore creates a DiskStore with segment files in dir, which is
+// created if needed. An empty dir means a temporary directory that Close
+// removes.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	temp := dir == ""
+	if temp {
+		var err error
+		if dir, err = os.MkdirTemp("", "envllm-text-"); err != nil {
+			return nil, fmt.Errorf("disk store: %v", err)
+		}
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("disk store: %v", err)
+	}
+	return &DiskStore{
+		dir:    dir,
+		temp:   temp,
+		texts:  make(map[string]diskText),
+		byHash: make(map[[sha256.Size]byte]string),
+		views:  make(map[diskViewKey]string),
+	}, nil
+}
+
+// Close closes the segment files, removing them if the store is temporary.
+// Handles are invalid afterwards.
+func (s *DiskStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var first error
+	for _, seg := range s.segs {
+		if err := seg.f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	s.segs = nil
+	if s.temp {
+		if err := os.RemoveAll(s.dir); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// segmentFor returns a segment with room for n more bytes.
+func (s *DiskStore) segmentFor(n int64) (*segment, error) {
+	if len(s.segs) > 0 {
+		last := s.segs[len(s.segs)-1]
+		if last.size == 0 || last.size+n <= segmentSize {
+			return last, nil
+		}
+	}
+	name := filepath.Join(s.dir, fmt.Sprintf("segment-%06d.txt", len(s.segs)))
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{f: f}
+	s.segs = append(s.segs, seg)
+	return seg, nil
+}
+
+func (s *DiskStore) newID() string {
+	s.next++
+	return "d:" + strconv.Itoa(s.next)
+}
+
+func (s *DiskStore) handle(id string, n int64) runtime.TextHandle {
+	return runtime.TextHandle{ID: id, Bytes: int(n)}
+}
+
+// appendFrom streams r (n bytes) to the end of a segment and registers it,
+// reusing an existing handle when the content is already stored. The range
+// is reserved under s.mu but written without it, so a long copy does not
+// block readers of the store.
+func (s *DiskStore) appendFrom(r io.Reader, n int64) (runtime.TextHandle, error) {
+	s.mu.Lock()
+	seg, err := s.segmentFor(n)
+	if err != nil {
+		s.mu.Unlock()
+		return runtime.TextHandle{}, err
+	}
+	off := seg.size
+	seg.size += n
+	s.mu.Unlock()
+
+	sum := sha256.New()
+	written, err := io.Copy(io.NewOffsetWriter(seg.f, off), io.TeeReader(io.LimitReader(r, n), sum))
+	if err == nil && written != n {
+		err = fmt.Errorf("expected %d bytes, got %d", n, written)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.release(seg, off, n)
+		return runtime.TextHandle{}, err
+	}
+	var key [sha256.Size]byte
+	copy(key[:], sum.Sum(nil))
+	if id, ok := s.byHash[key]; ok {
+		s.release(seg, off, n)
+		return s.handle(id, n), nil
+	}
+
+	id := s.newID()
+	s.texts[id] = diskText{seg: seg, off: off, n: n}
+	s.byHash[key] = id
+	return s.handle(id, n), nil
+}
+
+// release gives back the range [off, off+n) reserved in seg. If another
+// range was reserved after it meanwhile, the bytes are left unused.
+func (s *DiskStore) release(seg *segment, off, n int64) {
+	if seg.size == off+n {
+		seg.size = off
+		seg.f.Truncate(off)
+	}
+}
+
+// emptyText returns the handle of the empty text, which needs no segment.
+func (s *DiskStore) emptyText() runtime.TextHandle {
+	key := sha256.Sum256(nil)
+	id, ok := s.byHash[key]
+	if !ok {
+		id = s.newID()
+		s.texts[id] = diskText{}
+		s.byHash[key] = id
+	}
+	return s.handle(id, 0)
+}
+
+// Add adds text to the store and returns a TextHandle. If the text cannot
+// be written it is kept in memory instead.
+func (s *DiskStore) Add(text string) runtime.TextHandle {
+	h, err := s.appendFrom(strings.NewReader(text), int64(len(text)))
+	if err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id := s.newID()
+		s.texts[id] = diskText{mem: text, n: int64(len(text))}
+		return s.handle(id, int64(len(text)))
+	}
+	return h
+}
+
+// AddFile streams the file at path into the store.
+func (s *DiskStore) AddFile(path string) (runtime.TextHandle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return runtime.TextHandle{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return runtime.TextHandle{}, err
+	}
+	return s.appendFrom(f, info.Size())
+}
+
+// Get returns the text content for a given handle.
+func (s *DiskStore) Get(h runtime.TextHandle) (string, bool) {
+	s.mu.Lock()
+	t, ok := s.texts[h.ID]
+	s.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	buf := make([]byte, t.n)
+	if _, err := t.readAt(buf, 0); err != nil && err != io.EOF {
+		return "", false
+	}
+	return string(buf), true
+}
+
+// ReadTextAt reads len(p) bytes of h's content starting at off.
+func (s *DiskStore) ReadTextAt(h runtime.TextHandle, p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	t, ok := s.texts[h.ID]
+	s.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("text not found: %s", h.ID)
+	}
+	if off >= t.n {
+		return 0, io.EOF
+	}
+	want := min(int64(len(p)), t.n-off)
+	n, err := t.readAt(p[:want], off)
+	if err == nil && want < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Window creates a new snippet based on a center and radius, returning a new handle.
+func (s *DiskStore) Window(h runtime.TextHandle, center, radius int) (runtime.TextHandle, error) {
+	return s.view(h, center-radius, center+radius, true)
+}
+
+// Slice creates a new snippet based on start and end indices.
+func (s *DiskStore) Slice(h runtime.TextHandle, start, end int) (runtime.TextHandle, error) {
+	return s.view(h, start, end, false)
+}
+
+// view clamps [start, end) to h the way TextStore does and returns a handle
+// for that range without reading it.
+func (s *DiskStore) view(h runtime.TextHandle, start, end int, window bool) (runtime.TextHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.texts[h.ID]
+	if !ok {
+		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", h.ID)
+	}
+	size := int(t.n)
+	start = max(start, 0)
+	end = min(end, size)
+	if start > end {
+		if !window {
+			return s.emptyText(), nil
+		}
+		end = max(end, 0)
+		start = end
+	}
+	if start == 0 && end == size {
+		return h, nil
+	}
+
+	sub := diskText{seg: t.seg, mem: t.mem, off: t.off + int64(start), n: int64(end - start)}
+	key := diskViewKey{seg: t.seg, off: sub.off, n: sub.n}
+	if t.seg != nil {
+		if id, ok := s.views[key]; ok {
+			return runtime.DeriveHandle(h, s.handle(id, key.n), start), nil
+		}
+	}
+	id := s.newID()
+	s.texts[id] = sub
+	if t.seg != nil {
+		s.views[key] = id
+	}
+	return runtime.DeriveHandle(h, s.handle(id, sub.n), start), nil
+}
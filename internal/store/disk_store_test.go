@@ -0,0 +1,127 @@
+package store
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestDiskStoreMatchesTextStore(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	defer disk.Close()
+	flat := NewTextStore()
+
+	text := strings.Repeat("Lorem ipsum dolor sit amet. ", 30)
+	type pair struct{ d, f runtime.TextHandle }
+	handles := []pair{{disk.Add(text), flat.Add(text)}}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 1000; i++ {
+		p := handles[rng.Intn(len(handles))]
+		a, b := rng.Intn(p.f.Bytes+20)-10, rng.Intn(p.f.Bytes+20)-10
+		var next pair
+		if i%2 == 0 {
+			next.d, _ = disk.Slice(p.d, a, b)
+			next.f, _ = flat.Slice(p.f, a, b)
+		} else {
+			next.d, _ = disk.Window(p.d, a, b/4)
+			next.f, _ = flat.Window(p.f, a, b/4)
+		}
+		gotD, okD := disk.Get(next.d)
+		gotF, _ := flat.Get(next.f)
+		if !okD || gotD != gotF || next.d.Bytes != next.f.Bytes {
+			t.Fatalf("step %d: disk %q (%d bytes), flat %q (%d bytes)", i, gotD, next.d.Bytes, gotF, next.f.Bytes)
+		}
+		handles = append(handles, next)
+	}
+
+	if again := disk.Add(text); again.ID != handles[0].d.ID {
+		t.Errorf("identical Add should return the same handle")
+	}
+}
+
+func TestDiskStoreFilesAndRanges(t *testing.T) {
+	disk, err := NewDiskStore("")
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "big.log")
+	content := strings.Repeat("0123456789", 10000)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := disk.AddFile(path)
+	if err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	if h.Bytes != len(content) {
+		t.Fatalf("expected %d bytes, got %d", len(content), h.Bytes)
+	}
+	if same := disk.Add(content); same.ID != h.ID {
+		t.Errorf("Add of a file's content should reuse its handle")
+	}
+
+	sub, _ := disk.Slice(h, 95, 105)
+	buf := make([]byte, 8)
+	n, err := disk.ReadTextAt(sub, buf, 4)
+	if n != 6 || err != io.EOF || string(buf[:n]) != "901234" {
+		t.Errorf("ReadTextAt = %d, %v, %q", n, err, buf[:n])
+	}
+	if _, err := disk.AddFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	dir := disk.dir
+	if err := disk.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("temporary store directory should be removed, got %v", err)
+	}
+}
+
+func TestDiskStoreReadsDuringImport(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	defer disk.Close()
+	h := disk.Add("already stored")
+
+	// An import stalled halfway must not block readers of other texts.
+	r, w := io.Pipe()
+	done := make(chan runtime.TextHandle)
+	go func() {
+		imported, err := disk.appendFrom(r, 10)
+		if err != nil {
+			t.Errorf("appendFrom failed: %v", err)
+		}
+		done <- imported
+	}()
+	w.Write([]byte("12345"))
+	if got, ok := disk.Get(h); !ok || got != "already stored" {
+		t.Errorf("Get during import = %q %v", got, ok)
+	}
+	if sub, err := disk.Slice(h, 0, 7); err != nil || sub.Bytes != 7 {
+		t.Errorf("Slice during import = %+v %v", sub, err)
+	}
+	w.Write([]byte("67890"))
+	w.Close()
+
+	imported := <-done
+	if got, _ := disk.Get(imported); got != "1234567890" {
+		t.Errorf("expected the imported text, got %q", got)
+	}
+	if again := disk.Add("1234567890"); again.ID != imported.ID {
+		t.Errorf("expected identical content to share a handle, got %s and %s", again.ID, imported.ID)
+	}
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"fmt"
+	"hash/maphash"
+	"strconv"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// ropeNode is one entry of a RopeStore. Exactly one shape applies:
+//   - leaf:   text holds the content.
+//   - concat: parts are joined lazily; text caches the result once read.
+//   - view:   a range [start, end) of base, which is a leaf or a concat.
+type ropeNode struct {
+	id   string
+	size int
+	refs int // views and concatenations built on this node, plus SharedStore owners
+	hash uint64
+
+	text         string
+	materialized bool
+
+	parts []*ropeNode
+
+	base       *ropeNode
+	start, end int
+}
+
+type viewKey struct {
+	base       *ropeNode
+	start, end int
+}
+
+// RopeStore is a TextStore whose slices and windows are views into the text
+// they were cut from rather than copies, and whose concatenations are joined
+// only when read. Handle IDs come from a counter instead of a content hash,
+// so creating a view costs O(1) regardless of its length; identical Add
+// calls and identical views still return the same handle.
+//
+// RopeStore is not safe for concurrent use; SharedStore wraps it with
+// locking and reference tracking.
+type RopeStore struct {
+	seed   maphash.Seed
+	nodes  map[string]*ropeNode
+	leaves map[uint64][]*ropeNode // Add dedup, by content hash
+	views  map[viewKey]*ropeNode
+	next   int
+	bytes  int64 // leaf content plus cached concatenations
+}
+
+// NewRopeStore creates an empty RopeStore.
+func NewRopeStore() *RopeStore {
+	return &RopeStore{
+		seed:   maphash.MakeSeed(),
+		nodes:  make(map[string]*ropeNode),
+		leaves: make(map[uint64][]*ropeNode),
+		views:  make(map[viewKey]*ropeNode),
+	}
+}
+
+func (s *RopeStore) put(prefix string, n *ropeNode) runtime.TextHandle {
+	s.next++
+	n.id = prefix + strconv.Itoa(s.next)
+	s.nodes[n.id] = n
+	return n.handle()
+}
+
+func (n *ropeNode) handle() runtime.TextHandle {
+	return runtime.TextHandle{ID: n.id, Bytes: n.size}
+}
+
+// Add adds text to the store and returns a TextHandle.
+func (s *RopeStore) Add(text string) runtime.TextHandle {
+	sum := maphash.String(s.seed, text)
+	for _, n := range s.leaves[sum] {
+		if n.text == text {
+			return n.handle()
+		}
+	}
+	n := &ropeNode{size: len(text), hash: sum, text: text, materialized: true}
+	s.leaves[sum] = append(s.leaves[sum], n)
+	s.bytes += int64(len(text))
+	return s.put("t:", n)
+}
+
+// Get returns the text content for a given handle. Reading a view of a leaf
+// does not copy; reading a concatenation joins it once and caches the result.
+func (s *RopeStore) Get(h runtime.TextHandle) (string, bool) {
+	n, ok := s.nodes[h.ID]
+	if !ok {
+		return "", false
+	}
+	return s.content(n), true
+}
+
+func (s *RopeStore) content(n *ropeNode) string {
+	if n.base != nil {
+		return s.content(n.base)[n.start:n.end]
+	}
+	if !n.materialized {
+		var sb strings.Builder
+		sb.Grow(n.size)
+		for _, p := range n.parts {
+			sb.WriteString(s.content(p))
+		}
+		n.text, n.materialized = sb.String(), true
+		s.bytes += int64(n.size)
+	}
+	return n.text
+}
+
+// Window creates a view based on a center and radius.
+func (s *RopeStore) Window(h runtime.TextHandle, center, radius int) (runtime.TextHandle, error) {
+	n, ok := s.nodes[h.ID]
+	if !ok {
+		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", h.ID)
+	}
+
+	start := max(center-radius, 0)
+	end := max(min(center+radius, n.size), 0)
+	start = min(start, end)
+	return runtime.DeriveHandle(h, s.view(n, start, end), start), nil
+}
+
+// Slice creates a view based on start and end indices.
+func (s *RopeStore) Slice(h runtime.TextHandle, start, end int) (runtime.TextHandle, error) {
+	n, ok := s.nodes[h.ID]
+	if !ok {
+		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", h.ID)
+	}
+
+	start = max(start, 0)
+	end = min(end, n.size)
+	if start > end {
+		return s.Add(""), nil
+	}
+	return runtime.DeriveHandle(h, s.view(n, start, end), start), nil
+}
+
+// Concat joins a and b without copying either.
+func (s *RopeStore) Concat(a, b runtime.TextHandle) (runtime.TextHandle, error) {
+	na, ok := s.nodes[a.ID]
+	if !ok {
+		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", a.ID)
+	}
+	nb, ok := s.nodes[b.ID]
+	if !ok {
+		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", b.ID)
+	}
+	switch {
+	case na.size == 0:
+		return nb.handle(), nil
+	case nb.size == 0:
+		return na.handle(), nil
+	}
+	na.refs++
+	nb.refs++
+	return s.put("c:", &ropeNode{size: na.size + nb.size, parts: []*ropeNode{na, nb}}), nil
+}
+
+// view returns a handle for [start, end) of n, which must be in range.
+// Views never nest: a view of a view refers to the underlying node, and a
+// range that falls inside one part of a concatenation refers to that part.
+func (s *RopeStore) view(n *ropeNode, start, end int) runtime.TextHandle {
+	for {
+		if n.base != nil {
+			start, end, n = start+n.start, end+n.start, n.base
+			continue
+		}
+		if start == 0 && end == n.size {
+			return n.handle()
+		}
+		off, inner := 0, (*ropeNode)(nil)
+		for _, p := range n.parts { // leaves have no parts
+			if start >= off && end <= off+p.size {
+				inner = p
+				break
+			}
+			off += p.size
+		}
+		if inner == nil {
+			break
+		}
+		start, end, n = start-off, end-off, inner
+	}
+
+	key := viewKey{base: n, start: start, end: end}
+	if v, ok := s.views[key]; ok {
+		return v.handle()
+	}
+	v := &ropeNode{size: end - start, base: n, start: start, end: end}
+	s.views[key] = v
+	n.refs++
+	return s.put("v:", v)
+}
+
+// release drops one reference to n. A node left without references is
+// removed, which in turn releases the nodes it was built on.
+func (s *RopeStore) release(n *ropeNode) {
+	n.refs--
+	if n.refs > 0 {
+		return
+	}
+	delete(s.nodes, n.id)
+	switch {
+	case n.base != nil:
+		delete(s.views, viewKey{base: n.base, start: n.start, end: n.end})
+		s.release(n.base)
+	case n.parts != nil:
+		s.dropCache(n)
+		for _, p := range n.parts {
+			s.release(p)
+		}
+	default:
+		same := s.leaves[n.hash]
+		for i, l := range same {
+			if l == n {
+				same = append(same[:i], same[i+1:]...)
+				break
+			}
+		}
+		if len(same) == 0 {
+			delete(s.leaves, n.hash)
+		} else {
+			s.leaves[n.hash] = same
+		}
+		s.bytes -= int64(n.size)
+	}
+}
+
+// dropCache forgets the joined content of a concatenation.
+func (s *RopeStore) dropCache(n *ropeNode) {
+	if n.parts != nil && n.materialized {
+		n.text, n.materialized = "", false
+		s.bytes -= int64(n.size)
+	}
+}
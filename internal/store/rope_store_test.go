@@ -0,0 +1,83 @@
+package store
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestRopeStoreMatchesTextStore(t *testing.T) {
+	rope, flat := NewRopeStore(), NewTextStore()
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	type pair struct{ r, f runtime.TextHandle }
+	handles := []pair{{rope.Add(text), flat.Add(text)}}
+
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 2000; i++ {
+		p := handles[rng.Intn(len(handles))]
+		a, b := rng.Intn(p.f.Bytes+20)-10, rng.Intn(p.f.Bytes+20)-10
+		var next pair
+		var errR, errF error
+		if i%2 == 0 {
+			next.r, errR = rope.Slice(p.r, a, b)
+			next.f, errF = flat.Slice(p.f, a, b)
+		} else {
+			next.r, errR = rope.Window(p.r, a, b/4)
+			next.f, errF = flat.Window(p.f, a, b/4)
+		}
+		if errR != nil || errF != nil {
+			t.Fatalf("unexpected errors: %v / %v", errR, errF)
+		}
+		gotR, _ := rope.Get(next.r)
+		gotF, _ := flat.Get(next.f)
+		if gotR != gotF || next.r.Bytes != next.f.Bytes {
+			t.Fatalf("step %d: rope %q (%d bytes), flat %q (%d bytes)", i, gotR, next.r.Bytes, gotF, next.f.Bytes)
+		}
+		handles = append(handles, next)
+	}
+
+	if _, ok := rope.Get(runtime.TextHandle{ID: "t:missing"}); ok {
+		t.Error("expected unknown handle to be missing")
+	}
+	if _, err := rope.Slice(runtime.TextHandle{ID: "t:missing"}, 0, 1); err == nil {
+		t.Error("expected error slicing an unknown handle")
+	}
+}
+
+func TestRopeStoreViewsAndConcat(t *testing.T) {
+	s := NewRopeStore()
+	h := s.Add("Hello World")
+	if again := s.Add("Hello World"); again.ID != h.ID {
+		t.Errorf("identical Add should return the same handle, got %s and %s", h.ID, again.ID)
+	}
+
+	// A view of a view refers to the root, so equal ranges share a handle.
+	world, _ := s.Slice(h, 6, 11)
+	orl, _ := s.Slice(world, 1, 4)
+	direct, _ := s.Slice(h, 7, 10)
+	if orl.ID != direct.ID {
+		t.Errorf("expected nested view to be flattened: %s vs %s", orl.ID, direct.ID)
+	}
+	if whole, _ := s.Slice(h, 0, 100); whole.ID != h.ID {
+		t.Errorf("full-range slice should return the original handle")
+	}
+
+	c, err := s.Concat(world, s.Add("!"))
+	if err != nil {
+		t.Fatalf("Concat failed: %v", err)
+	}
+	if got, _ := s.Get(c); got != "World!" || c.Bytes != 6 {
+		t.Errorf("unexpected concat: %q (%d bytes)", got, c.Bytes)
+	}
+	// A slice inside one part of a concatenation is a view of that part.
+	inner, _ := s.Slice(c, 1, 4)
+	if inner.ID != orl.ID {
+		t.Errorf("expected slice of concat to resolve to %s, got %s", orl.ID, inner.ID)
+	}
+	across, _ := s.Slice(c, 3, 6)
+	if got, _ := s.Get(across); got != "ld!" {
+		t.Errorf("unexpected slice across parts: %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+// SharedStore is a RopeStore that is safe for concurrent use and tracks who
+// references each text, so text nobody needs any more can be freed.
+//
+// Every handle is owned by a Scope. Handles created through the SharedStore
+// itself belong to its built-in scope and live until Release. Sessions should
+// use their own scope (NewScope): the runtime collects a scope at the end of
+// each cell, releasing handles no variable refers to any more, such as
+// temporary windows and promoted string arguments, and Close releases the
+// rest. A text stays stored while any scope owns it or any live view or
+// concatenation is built on it.
+//
+// With a memory limit, cached concatenations are dropped first when the
+// stored bytes exceed it; if that is not enough, scopes report the overrun
+// through OverLimit and the runtime stops the session with a budget error.
+type SharedStore struct {
+	mu    sync.Mutex
+	rope  *RopeStore
+	limit int64
+	own   *Scope
+}
+
+// Scope is a view of a SharedStore that owns the handles created or
+// retrieved through it. Scopes of one store share handles: any scope can
+// read any live handle.
+type Scope struct {
+	store *SharedStore
+	owned map[*ropeNode]bool // guarded by store.mu
+}
+
+// NewSharedStore creates a SharedStore. limit caps the stored bytes; 0 means
+// no limit.
+func NewSharedStore(limit int64) *SharedStore {
+	s := &SharedStore{rope: NewRopeStore(), limit: limit}
+	s.own = s.NewScope()
+	return s
+}
+
+// NewScope returns a new, empty scope of s.
+func (s *SharedStore) NewScope() *Scope {
+	return &Scope{store: s, owned: make(map[*ropeNode]bool)}
+}
+
+// Bytes reports the bytes currently held, including cached concatenations.
+func (s *SharedStore) Bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rope.bytes
+}
+
+// Add adds text to the store and returns a TextHandle.
+func (s *SharedStore) Add(text string) runtime.TextHandle { return s.own.Add(text) }
+
+// Get returns the text content for a given handle.
+func (s *SharedStore) Get(h runtime.TextHandle) (string, bool) { return s.own.Get(h) }
+
+// Window creates a view based on a center and radius.
+func (s *SharedStore) Window(h runtime.TextHandle, center, radius int) (runtime.TextHandle, error) {
+	return s.own.Window(h, center, radius)
+}
+
+// Slice creates a view based on start and end indices.
+func (s *SharedStore) Slice(h runtime.TextHandle, start, end int) (runtime.TextHandle, error) {
+	return s.own.Slice(h, start, end)
+}
+
+// Concat joins a and b without copying either.
+func (s *SharedStore) Concat(a, b runtime.TextHandle) (runtime.TextHandle, error) {
+	return s.own.Concat(a, b)
+}
+
+// Release gives up a handle obtained through the store itself.
+func (s *SharedStore) Release(h runtime.TextHandle) { s.own.Release(h) }
+
+// OverLimit reports whether the store holds more than its memory limit.
+func (s *SharedStore) OverLimit() error { return s.own.OverLimit() }
+
+// trim drops cached concatenations while over the limit. Callers hold mu.
+func (s *SharedStore) trim() {
+	if s.limit <= 0 || s.rope.bytes <= s.limit {
+		return
+	}
+	for _, n := range s.rope.nodes {
+		s.rope.dropCache(n)
+		if s.rope.bytes <= s.limit {
+			return
+		}
+	}
+}
+
+// take records that sc owns h. Callers hold mu.
+func (sc *Scope) take(h runtime.TextHandle) runtime.TextHandle {
+	if n, ok := sc.store.rope.nodes[h.ID]; ok && !sc.owned[n] {
+		sc.owned[n] = true
+		n.refs++
+	}
+	return h
+}
+
+// Add adds text to the store and returns a TextHandle owned by sc.
+func (sc *Scope) Add(text string) runtime.TextHandle {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := sc.take(s.rope.Add(text))
+	s.trim()
+	return h
+}
+
+// Get returns the text content for a given handle.
+func (sc *Scope) Get(h runtime.TextHandle) (string, bool) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text, ok := s.rope.Get(h)
+	s.trim()
+	return text, ok
+}
+
+// Window creates a view based on a center and radius.
+func (sc *Scope) Window(h runtime.TextHandle, center, radius int) (runtime.TextHandle, error) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, err := s.rope.Window(h, center, radius)
+	if err != nil {
+		return w, err
+	}
+	return sc.take(w), nil
+}
+
+// Slice creates a view based on start and end indices.
+func (sc *Scope) Slice(h runtime.TextHandle, start, end int) (runtime.TextHandle, error) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.rope.Slice(h, start, end)
+	if err != nil {
+		return v, err
+	}
+	return sc.take(v), nil
+}
+
+// Concat joins a and b without copying either.
+func (sc *Scope) Concat(a, b runtime.TextHandle) (runtime.TextHandle, error) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.rope.Concat(a, b)
+	if err != nil {
+		return c, err
+	}
+	return sc.take(c), nil
+}
+
+// Release gives up sc's ownership of h.
+func (sc *Scope) Release(h runtime.TextHandle) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n, ok := s.rope.nodes[h.ID]; ok && sc.owned[n] {
+		delete(sc.owned, n)
+		s.rope.release(n)
+	}
+}
+
+// Collect releases every handle owned by sc that is not in live. The
+// runtime calls it with the handles reachable from session variables.
+func (sc *Scope) Collect(live []runtime.TextHandle) {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keep := make(map[string]bool, len(live))
+	for _, h := range live {
+		keep[h.ID] = true
+	}
+	for n := range sc.owned {
+		if !keep[n.id] {
+			delete(sc.owned, n)
+			s.rope.release(n)
+		}
+	}
+}
+
+// Close releases all handles owned by sc.
+func (sc *Scope) Close() {
+	sc.Collect(nil)
+}
+
+// OverLimit reports whether the store holds more than its memory limit.
+func (sc *Scope) OverLimit() error {
+	s := sc.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limit > 0 && s.rope.bytes > s.limit {
+		return fmt.Errorf("text store holds %d bytes, limit is %d", s.rope.bytes, s.limit)
+	}
+	return nil
+}
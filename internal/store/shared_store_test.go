@@ -0,0 +1,104 @@
+package store
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+func TestSharedStoreConcurrentAdd(t *testing.T) {
+	s := NewSharedStore(0)
+	doc := s.Add(strings.Repeat("abc", 1000))
+
+	var wg sync.WaitGroup
+	ids := make([]string, 32)
+	scopes := make([]*Scope, len(ids))
+	for i := range ids {
+		scopes[i] = s.NewScope()
+		wg.Add(1)
+		go func(sc *Scope, i int) {
+			defer wg.Done()
+			ids[i] = sc.Add("same content").ID
+			w, _ := sc.Window(doc, 1500, 10)
+			if got, _ := sc.Get(w); len(got) != 20 {
+				t.Errorf("unexpected window %q", got)
+			}
+		}(scopes[i], i)
+	}
+	wg.Wait()
+	for _, id := range ids[1:] {
+		if id != ids[0] {
+			t.Fatalf("concurrent identical Add returned different handles: %v", ids)
+		}
+	}
+	for _, sc := range scopes {
+		sc.Close()
+	}
+	if got := s.Bytes(); got != 3000 {
+		t.Errorf("expected only the pinned document to remain, store holds %d bytes", got)
+	}
+}
+
+func TestScopeCollectAndRelease(t *testing.T) {
+	s := NewSharedStore(0)
+	sc := s.NewScope()
+	doc := sc.Add("Hello World")
+	word, _ := sc.Slice(doc, 6, 11)
+	tmp, _ := sc.Window(doc, 2, 2)
+
+	// Only the slice is live: the window goes, and the document stays
+	// because the slice is a view of it.
+	sc.Collect([]runtime.TextHandle{word})
+	if _, ok := sc.Get(tmp); ok {
+		t.Error("unreferenced window should be evicted")
+	}
+	if got, ok := sc.Get(word); !ok || got != "World" {
+		t.Errorf("live slice lost: %q", got)
+	}
+	if got, ok := sc.Get(doc); !ok || got != "Hello World" {
+		t.Errorf("document under a live view lost: %q", got)
+	}
+
+	// Another scope's ownership keeps text alive across Release.
+	other := s.NewScope()
+	other.Add("Hello World")
+	sc.Release(word)
+	if _, ok := sc.Get(word); ok {
+		t.Error("released slice should be evicted")
+	}
+	if _, ok := sc.Get(doc); !ok {
+		t.Error("document owned by another scope should remain")
+	}
+	other.Close()
+	if _, ok := s.Get(doc); ok || s.Bytes() != 0 {
+		t.Errorf("store should be empty, holds %d bytes", s.Bytes())
+	}
+}
+
+func TestSharedStoreMemoryLimit(t *testing.T) {
+	s := NewSharedStore(100)
+	sc := s.NewScope()
+	a := sc.Add(strings.Repeat("a", 40))
+	b := sc.Add(strings.Repeat("b", 40))
+	c, _ := sc.Concat(a, b)
+
+	// Reading the concatenation caches 80 bytes; the cache is dropped to
+	// stay under the limit, and the content is still readable.
+	if got, _ := sc.Get(c); len(got) != 80 {
+		t.Fatalf("unexpected concat length %d", len(got))
+	}
+	if err := sc.OverLimit(); err != nil || s.Bytes() != 80 {
+		t.Errorf("expected cache to be dropped, got %v with %d bytes", err, s.Bytes())
+	}
+
+	big := sc.Add(strings.Repeat("c", 50))
+	if sc.OverLimit() == nil {
+		t.Error("expected the limit to be exceeded")
+	}
+	sc.Release(big)
+	if err := sc.OverLimit(); err != nil {
+		t.Errorf("releasing should bring the store under its limit: %v", err)
+	}
+}
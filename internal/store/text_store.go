@@ -3,12 +3,15 @@ package store
 import (
 	"crypto/sha256"
 	"fmt"
+	"sync"
 
 	"github.com/agenthands/envllm/internal/runtime"
 )
 
-// TextStore manages text content and provides handle-based access.
+// TextStore manages text content and provides handle-based access. It is
+// safe for concurrent use.
 type TextStore struct {
+	mu      sync.RWMutex
 	content map[string]string
 }
 
@@ -21,7 +24,9 @@ func NewTextStore() *TextStore {
 // Add adds text to the store and returns a TextHandle.
 func (s *TextStore) Add(text string) runtime.TextHandle {
 	id := fmt.Sprintf("t:%x", sha256.Sum256([]byte(text)))
+	s.mu.Lock()
 	s.content[id] = text
+	s.mu.Unlock()
 	return runtime.TextHandle{
 		ID:    id,
 		Bytes: len(text),
@@ -30,13 +35,15 @@ func (s *TextStore) Add(text string) runtime.TextHandle {
 
 // Get returns the text content for a given handle.
 func (s *TextStore) Get(h runtime.TextHandle) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	text, ok := s.content[h.ID]
 	return text, ok
 }
 
 // Window creates a new snippet based on a center and radius, returning a new handle.
 func (s *TextStore) Window(h runtime.TextHandle, center, radius int) (runtime.TextHandle, error) {
-	text, ok := s.content[h.ID]
+	text, ok := s.Get(h)
 	if !ok {
 		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", h.ID)
 	}
@@ -50,17 +57,19 @@ func (s *TextStore) Window(h runtime.TextHandle, center, radius int) (runtime.Te
 	if end > len(text) {
 		end = len(text)
 	}
+	if end < 0 {
+		end = 0
+	}
 	if start > end {
 		start = end
 	}
 
-	snippet := text[start:end]
-	return s.Add(snippet), nil
+	return s.derive(h, text, start, end), nil
 }
 
 // Slice creates a new snippet based on start and end indices.
 func (s *TextStore) Slice(h runtime.TextHandle, start, end int) (runtime.TextHandle, error) {
-	text, ok := s.content[h.ID]
+	text, ok := s.Get(h)
 	if !ok {
 		return runtime.TextHandle{}, fmt.Errorf("text not found: %s", h.ID)
 	}
@@ -75,6 +84,22 @@ func (s *TextStore) Slice(h runtime.TextHandle, start, end int) (runtime.TextHan
 		return s.Add(""), nil
 	}
 
+	return s.derive(h, text, start, end), nil
+}
+
+// derive returns a handle for text[start:end], cut from h. Its ID hashes
+// where the snippet lies in h's root text as well as its content, so equal
+// snippets cut from different places get distinct handles and each ID has
+// one lineage.
+func (s *TextStore) derive(h runtime.TextHandle, text string, start, end int) runtime.TextHandle {
+	if start == 0 && end == len(text) {
+		return h
+	}
 	snippet := text[start:end]
-	return s.Add(snippet), nil
+	root, base := h.Origin()
+	id := fmt.Sprintf("t:%x", sha256.Sum256([]byte(fmt.Sprintf("%s@%d\x00%s", root, base+start, snippet))))
+	s.mu.Lock()
+	s.content[id] = snippet
+	s.mu.Unlock()
+	return runtime.DeriveHandle(h, runtime.TextHandle{ID: id, Bytes: len(snippet)}, start)
 }
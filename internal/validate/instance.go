@@ -0,0 +1,78 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const schemaBase = "mem://schemas/"
+
+// CompileSchema compiles a JSON Schema document given as text. A $ref may
+// only point at another schema in registered, addressed by its name (e.g.
+// "address.json" or "mem://schemas/address.json"); file, http and any other
+// URL is refused so programs cannot read outside the schemas the host gave.
+func CompileSchema(name, text string, registered map[string]string) (*jsonschema.Schema, error) {
+	url := schemaBase + name + ".json"
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(ref string) (io.ReadCloser, error) {
+		if strings.HasPrefix(ref, schemaBase) {
+			if doc, ok := registered[strings.TrimSuffix(strings.TrimPrefix(ref, schemaBase), ".json")]; ok {
+				return io.NopCloser(strings.NewReader(doc)), nil
+			}
+		}
+		return nil, fmt.Errorf("$ref %q is not a registered schema", ref)
+	}
+	if err := c.AddResource(url, strings.NewReader(text)); err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %v", name, err)
+	}
+	schema, err := c.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %v", name, err)
+	}
+	return schema, nil
+}
+
+// ValidateInstance checks plain JSON data against schema and returns one
+// violation per failing leaf keyword, ordered by instance location.
+func ValidateInstance(schema *jsonschema.Schema, instance interface{}) ([]runtime.SchemaViolation, error) {
+	err := schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	var out []runtime.SchemaViolation
+	var flatten func(*jsonschema.ValidationError)
+	flatten = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			out = append(out, runtime.SchemaViolation{
+				Pointer: e.InstanceLocation,
+				Keyword: keywordOf(e.KeywordLocation),
+				Message: e.Message,
+			})
+			return
+		}
+		for _, c := range e.Causes {
+			flatten(c)
+		}
+	}
+	flatten(ve)
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Pointer < out[j].Pointer })
+	return out, nil
+}
+
+func keywordOf(loc string) string {
+	if i := strings.LastIndex(loc, "/"); i != -1 {
+		return loc[i+1:]
+	}
+	return loc
+}
@@ -1,6 +1,9 @@
 package validate
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"github.com/agenthands/envllm/internal/ast"
 	"github.com/agenthands/envllm/internal/lex"
@@ -70,3 +73,72 @@ func TestNewValidator_Error(t *testing.T) {
 		t.Error("expected error for non-existent schema, got nil")
 	}
 }
+
+func TestValidateInstance(t *testing.T) {
+	schema, err := CompileSchema("invoice", `{
+		"type": "object",
+		"required": ["id", "total"],
+		"properties": {
+			"id": {"type": "string"},
+			"total": {"type": "number", "minimum": 0},
+			"lines": {"type": "array", "items": {"type": "object", "required": ["sku"]}}
+		}
+	}`, nil)
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+
+	ok := map[string]interface{}{"id": "A1", "total": 10}
+	if vs, err := ValidateInstance(schema, ok); err != nil || len(vs) != 0 {
+		t.Errorf("expected valid instance, got %v (err %v)", vs, err)
+	}
+
+	bad := map[string]interface{}{
+		"id":    7,
+		"total": -1.0,
+		"lines": []interface{}{map[string]interface{}{"qty": 1}},
+	}
+	vs, err := ValidateInstance(schema, bad)
+	if err != nil {
+		t.Fatalf("ValidateInstance failed: %v", err)
+	}
+	pointers := map[string]string{}
+	for _, v := range vs {
+		pointers[v.Pointer] = v.Keyword
+	}
+	want := map[string]string{"/id": "type", "/total": "minimum", "/lines/0": "required"}
+	for p, kw := range want {
+		if pointers[p] != kw {
+			t.Errorf("expected violation %s at %s, got %v", kw, p, vs)
+		}
+	}
+}
+
+func TestCompileSchema_Invalid(t *testing.T) {
+	if _, err := CompileSchema("broken", `{"type": 12`, nil); err == nil {
+		t.Error("expected error for malformed schema, got nil")
+	}
+}
+
+func TestCompileSchema_Refs(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"type": "string"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompileSchema("evil", `{"$ref": "file://`+filepath.ToSlash(secret)+`"}`, nil); err == nil || !strings.Contains(err.Error(), "not a registered schema") {
+		t.Errorf("expected file:// $ref to be refused, got %v", err)
+	}
+	if _, err := CompileSchema("evil", `{"$ref": "http://example.com/schema.json"}`, nil); err == nil {
+		t.Error("expected http $ref to be refused")
+	}
+
+	registered := map[string]string{"address": `{"type": "object", "required": ["city"]}`}
+	schema, err := CompileSchema("person", `{"properties": {"home": {"$ref": "address.json"}}}`, registered)
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+	vs, err := ValidateInstance(schema, map[string]interface{}{"home": map[string]interface{}{}})
+	if err != nil || len(vs) != 1 || vs[0].Keyword != "required" {
+		t.Errorf("expected registered $ref to apply, got %v (err %v)", vs, err)
+	}
+}
@@ -0,0 +1,86 @@
+package envllm
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestPublicAPIHidesInternalPackages checks that exported functions,
+// methods and struct fields only name types through this package. Aliases
+// are how internal types are re-exported, so their right-hand sides are
+// allowed.
+func TestPublicAPIHidesInternalPackages(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range pkgs["envllm"].Files {
+		internal := make(map[string]bool)
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if !strings.Contains(path, "/internal/") {
+				continue
+			}
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			internal[name] = true
+		}
+		leaks := func(what string, n ast.Node) {
+			ast.Inspect(n, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if x, ok := sel.X.(*ast.Ident); ok && internal[x.Name] {
+					t.Errorf("%s: %s exposes %s.%s", fset.Position(sel.Pos()), what, x.Name, sel.Sel.Name)
+				}
+				return true
+			})
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if !d.Name.IsExported() || (d.Recv != nil && !exportedRecv(d.Recv)) {
+					continue
+				}
+				leaks(d.Name.Name, d.Type)
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || !ts.Name.IsExported() || ts.Assign.IsValid() {
+						continue
+					}
+					switch typ := ts.Type.(type) {
+					case *ast.StructType:
+						for _, f := range typ.Fields.List {
+							for _, name := range f.Names {
+								if name.IsExported() {
+									leaks(ts.Name.Name+"."+name.Name, f.Type)
+								}
+							}
+						}
+					default:
+						leaks(ts.Name.Name, typ)
+					}
+				}
+			}
+		}
+	}
+}
+
+func exportedRecv(recv *ast.FieldList) bool {
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	id, ok := typ.(*ast.Ident)
+	return ok && id.IsExported()
+}
@@ -0,0 +1,240 @@
+package envllm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/agenthands/envllm/internal/extension"
+	"github.com/agenthands/envllm/internal/lint"
+	"github.com/agenthands/envllm/internal/ops"
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+)
+
+// Module is a set of ops, with their handlers, that an Engine registers
+// next to the built-in core, fs and web modules.
+type Module = ops.Module
+
+// Op describes an op a Module provides: its name, capabilities, result type
+// and keyword signature.
+type Op = ops.Op
+
+// Param is one keyword of an op signature.
+type Param = ops.Param
+
+// Args holds the arguments of an op call by keyword, with left-out params
+// set to their defaults.
+type Args = ops.Args
+
+// OpImplementation is the handler of an op.
+type OpImplementation = ops.OpImplementation
+
+// LintMode selects how strictly an Engine lints programs before running
+// them.
+type LintMode = lint.Mode
+
+const (
+	LintCompat = lint.ModeCompat
+	LintStrict = lint.ModeStrict
+)
+
+// Engine holds an ops table and the registry built from it, so programs can
+// be compiled and executed without loading them again. An Engine is safe
+// for concurrent Compile and Execute calls.
+type Engine struct {
+	table    *ops.Table
+	registry *ops.Registry
+	lintMode LintMode
+}
+
+// EngineOption configures an Engine built by NewEngine.
+type EngineOption func(*engineConfig)
+
+type engineConfig struct {
+	opsTable   string
+	modules    []Module
+	extensions []string
+	lintMode   LintMode
+}
+
+// WithOpsTable makes the Engine use the ops table at path instead of the
+// built-in one.
+func WithOpsTable(path string) EngineOption {
+	return func(c *engineConfig) { c.opsTable = path }
+}
+
+// WithModule registers m after the built-in modules. Its ops become
+// visible to the linter as well as the runtime.
+func WithModule(m Module) EngineOption {
+	return func(c *engineConfig) { c.modules = append(c.modules, m) }
+}
+
+// WithExtensions registers the ops of the extension manifests (*.json) in
+// dir after the built-in modules and those given with WithModule. Their
+// ops run sequences of existing ops or regex templates, and are linted and
+// capability-checked like any other op.
+func WithExtensions(dir string) EngineOption {
+	return func(c *engineConfig) { c.extensions = append(c.extensions, dir) }
+}
+
+// WithLintMode sets the lint mode applied before each execution; the
+// default is LintCompat.
+func WithLintMode(mode LintMode) EngineOption {
+	return func(c *engineConfig) { c.lintMode = mode }
+}
+
+// NewEngine loads the ops table and registers the built-in modules, any
+// modules given with WithModule and any extensions given with
+// WithExtensions. It fails if the table or a manifest is invalid or a
+// module conflicts with it or with another module.
+func NewEngine(opts ...EngineOption) (*Engine, error) {
+	cfg := engineConfig{lintMode: LintCompat}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	tbl, err := ops.LoadTableOrDefault(cfg.opsTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ops table: %v", err)
+	}
+	reg, err := ops.NewRegistry(tbl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ops table: %v", err)
+	}
+	for _, m := range cfg.modules {
+		if err := reg.RegisterModule(m); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range cfg.extensions {
+		if _, err := extension.RegisterDir(reg, dir); err != nil {
+			return nil, fmt.Errorf("failed to load extensions: %v", err)
+		}
+	}
+	return &Engine{table: tbl, registry: reg, lintMode: cfg.lintMode}, nil
+}
+
+var defaultEngine = sync.OnceValues(func() (*Engine, error) {
+	return NewEngine()
+})
+
+// Compile compiles source code into a Program.
+func (e *Engine) Compile(filename string, src string, mode ParseMode) (*Program, error) {
+	return Compile(filename, src, mode)
+}
+
+// Execute lints and runs p with the Engine's ops. The ops table is fixed
+// when the Engine is built, so opt.OpsTable must be empty.
+func (e *Engine) Execute(ctx context.Context, p *Program, opt ExecOptions) (ExecResult, error) {
+	r, err := e.NewRun(opt)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return r.Execute(ctx, p)
+}
+
+// Run executes a sequence of programs in one session, as an agent loop that
+// asks a model for the next program after each observation does. Variables
+// a program defines stay visible to the programs after it, and the text
+// store, budgets and final result carry over. A Run is not safe for
+// concurrent use.
+type Run struct {
+	engine  *Engine
+	session *runtime.Session
+	sink    TraceSink
+}
+
+// NewRun starts a Run with opt's host, policy, store and inputs. As with
+// Execute, opt.OpsTable must be empty.
+func (e *Engine) NewRun(opt ExecOptions) (*Run, error) {
+	if opt.OpsTable != "" {
+		return nil, fmt.Errorf("ExecOptions.OpsTable cannot be used with an Engine; build it WithOpsTable instead")
+	}
+	ts := opt.TextStore
+	if ts == nil {
+		ts = store.NewSharedStore(0)
+	}
+
+	s := runtime.NewSession(opt.Policy, ts)
+	s.Dispatcher = e.registry
+	s.Host = opt.Host
+	s.TraceSink = opt.TraceSink
+	s.Schemas = opt.Schemas
+	s.Clock = opt.Clock
+
+	// Set inputs
+	for k, v := range opt.Inputs {
+		if err := s.Env.Define(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return &Run{engine: e, session: s, sink: opt.TraceSink}, nil
+}
+
+// Execute lints p, treating the variables defined by earlier programs of
+// the Run as declared, and runs it. The result holds every variable the Run
+// has defined so far.
+func (r *Run) Execute(ctx context.Context, p *Program) (ExecResult, error) {
+	s := r.session
+	defined := make(map[string]string, len(s.VarsDelta))
+	for name, v := range s.VarsDelta {
+		defined[name] = string(v.Kind)
+	}
+
+	// Perform Linting with Trace
+	lnt := lint.NewLinter(r.engine.table).WithMode(r.engine.lintMode).WithSink(r.sink).WithSymbols(defined)
+	lintErrs := lnt.Lint(p.AST)
+	if len(lintErrs) > 0 {
+		var errs []Error
+		for _, le := range lintErrs {
+			errs = append(errs, Error{Code: le.Code, Message: le.Message, Hint: le.Hint})
+		}
+		return ExecResult{Status: "error", Errors: errs}, nil
+	}
+
+	var lastErr error
+	if p.AST.Task != nil {
+		if err := s.ExecuteTask(ctx, p.AST.Task); err != nil {
+			lastErr = err
+		}
+	}
+
+	status := "ok"
+	var errs []Error
+	if lastErr != nil {
+		status = "error"
+		var bErr *BudgetExceededError
+		var cErr *CapabilityDeniedError
+		var sErr *SchemaViolationError
+		if errors.As(lastErr, &bErr) {
+			status = "budget_exceeded"
+		} else if errors.As(lastErr, &cErr) {
+			status = "capability_denied"
+		}
+
+		if errors.As(lastErr, &sErr) {
+			for _, v := range sErr.Violations {
+				errs = append(errs, Error{
+					Code:    "ERR_SCHEMA_VIOLATION",
+					Message: v.String(),
+					Hint:    fmt.Sprintf("schema %q, keyword %q", sErr.Schema, v.Keyword),
+				})
+			}
+		} else {
+			errs = append(errs, Error{
+				Code:    "EXEC_ERROR",
+				Message: lastErr.Error(),
+			})
+		}
+	}
+
+	return s.GenerateResult(status, errs), nil
+}
+
+// Result returns the Run's state as an observation: the variables defined
+// so far, the final result if a program has set one, and the budgets used.
+func (r *Run) Result() ExecResult {
+	return r.session.GenerateResult("ok", nil)
+}
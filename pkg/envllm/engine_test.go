@@ -0,0 +1,327 @@
+package envllm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agenthands/envllm/internal/runtime"
+)
+
+type shoutModule struct{}
+
+func (shoutModule) ID() string { return "shout" }
+
+func (shoutModule) Operations() []Op {
+	return []Op{{
+		Name:         "SHOUT",
+		Capabilities: []string{"pure"},
+		ResultType:   runtime.KindText,
+		Signature:    []Param{{Kw: "SOURCE", Type: runtime.KindText}},
+		Into:         true,
+	}}
+}
+
+func (shoutModule) Handlers() map[string]OpImplementation {
+	return map[string]OpImplementation{
+		"SHOUT": func(s *runtime.Session, args Args) (runtime.Value, error) {
+			text, _ := s.Stores.Text.Get(args["SOURCE"].V.(runtime.TextHandle))
+			return runtime.Value{Kind: runtime.KindText, V: s.Stores.Text.Add(strings.ToUpper(text))}, nil
+		},
+	}
+}
+
+func TestEngine_WithModule(t *testing.T) {
+	e, err := NewEngine(WithModule(shoutModule{}))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	prog, err := e.Compile("shout.rlm", `RLMDSL 0.2
+TASK shout:
+  INPUT PROMPT: TEXT
+  CELL main:
+    SHOUT SOURCE PROMPT INTO loud: TEXT
+  OUTPUT loud
+`, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	ts := NewTextStore()
+	res, err := e.Execute(context.Background(), prog, ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add("hello")}},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+	if got, _ := ts.Get(res.Final.V.(runtime.TextHandle)); got != "HELLO" {
+		t.Errorf("expected HELLO, got %q", got)
+	}
+
+	// The default engine does not know the op.
+	res, err = prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add("hello")}},
+	})
+	if err != nil || res.Status != "error" {
+		t.Errorf("expected SHOUT to be unknown without the module, got %v %+v", err, res)
+	}
+}
+
+type statsShadowModule struct{ shoutModule }
+
+func (statsShadowModule) Operations() []Op {
+	return []Op{{Name: "STATS", Capabilities: []string{"pure"}, ResultType: runtime.KindText, Into: true}}
+}
+
+func TestEngine_RejectsConflictingModule(t *testing.T) {
+	_, err := NewEngine(WithModule(statsShadowModule{}))
+	if err == nil || !strings.Contains(err.Error(), "op STATS is already registered by module core") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	_, err = NewEngine(WithModule(shoutModule{}), WithModule(shoutModule{}))
+	if err == nil || !strings.Contains(err.Error(), "op SHOUT is already registered by module shout") {
+		t.Errorf("expected duplicate module error, got %v", err)
+	}
+}
+
+func TestEngine_WithLintMode(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK shift:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "b" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    OFFSET_ADD OFFSET pos AMOUNT 1 INTO next: OFFSET
+  OUTPUT next
+`
+	for _, tc := range []struct {
+		mode   LintMode
+		status string
+	}{{LintCompat, "ok"}, {LintStrict, "error"}} {
+		e, err := NewEngine(WithLintMode(tc.mode))
+		if err != nil {
+			t.Fatalf("NewEngine failed: %v", err)
+		}
+		prog, err := e.Compile("shift.rlm", src, ModeStrict)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		ts := NewTextStore()
+		res, err := e.Execute(context.Background(), prog, ExecOptions{
+			TextStore: ts,
+			Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add("abc")}},
+		})
+		if err != nil || res.Status != tc.status {
+			t.Errorf("lint mode %v: expected status %s, got %v %+v", tc.mode, tc.status, err, res)
+		}
+	}
+
+	e, _ := NewEngine()
+	prog, _ := e.Compile("shift.rlm", src, ModeStrict)
+	if _, err := e.Execute(context.Background(), prog, ExecOptions{OpsTable: "ops.json"}); err == nil {
+		t.Error("expected an error for ExecOptions.OpsTable on an Engine")
+	}
+}
+
+func TestEngine_WithExtensions(t *testing.T) {
+	e, err := NewEngine(WithExtensions("../../examples/extensions"), WithLintMode(LintStrict))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	prog, err := e.Compile("invoice.rlm", `RLMDSL 0.2
+TASK invoice:
+  INPUT PROMPT: TEXT
+  CELL main:
+    INVOICE_HEADER SOURCE PROMPT INTO header: STRUCT
+    GET_FIELD SOURCE header FIELD "number" INTO number: TEXT
+    INVOICE_TOTAL SOURCE PROMPT INTO total: INT
+    JSON_OBJECT KEYS ["number", "total"] VALUES [number, total] INTO out: STRUCT
+  OUTPUT out
+`, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ts := NewTextStore()
+	var got struct {
+		Number string
+		Total  int
+	}
+	res, err := e.Execute(context.Background(), prog, ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]Value{"PROMPT": {Kind: KindText, V: ts.Add("Invoice #: INV-7\nDate: 2026-01-02\nTotal: 1,500 EUR\n")}},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+	if err := FromValue(ts, *res.Final, &got); err != nil || got.Number != "INV-7" || got.Total != 1500 {
+		t.Errorf("unexpected result %+v %v", got, err)
+	}
+
+	// Extension ops are capability-checked by the linter like built-in ones.
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "ask.json"), []byte(`{"name": "ask", "provides": {"ops": [{
+		"name": "ASK", "capabilities": ["llm"], "result_type": "JSON",
+		"signature": [{"kw": "SOURCE", "type": "TEXT"}],
+		"steps": [{"op": "SUBCALL", "args": {"SOURCE": "$SOURCE", "TASK": "answer", "DEPTH_COST": 1}, "into": "reply"}]
+	}]}}`), 0o644)
+	e, err = NewEngine(WithExtensions(dir))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	prog, _ = e.Compile("ask.rlm", `RLMDSL 0.2
+TASK ask:
+  INPUT PROMPT: TEXT
+  CELL main:
+    ASK SOURCE PROMPT INTO reply: JSON
+  OUTPUT reply
+`, ModeStrict)
+	res, err = e.Execute(context.Background(), prog, ExecOptions{TextStore: ts, Inputs: map[string]Value{"PROMPT": {Kind: KindText, V: ts.Add("hi")}}})
+	if err != nil || res.Status != "error" || res.Errors[0].Code != "LINT_MISSING_REQUIRES" {
+		t.Errorf("expected LINT_MISSING_REQUIRES, got %v %+v", err, res)
+	}
+}
+
+func TestEngine_Concurrent(t *testing.T) {
+	e, err := NewEngine(WithModule(shoutModule{}), WithLintMode(LintStrict))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prog, err := e.Compile(fmt.Sprintf("p%d.rlm", i), `RLMDSL 0.2
+TASK shout:
+  INPUT PROMPT: TEXT
+  CELL main:
+    SHOUT SOURCE PROMPT INTO loud: TEXT
+    STATS SOURCE loud INTO stats: STRUCT
+  OUTPUT loud
+`, ModeStrict)
+			if err != nil {
+				errs <- err
+				return
+			}
+			ts := NewTextStore()
+			input := fmt.Sprintf("run %d", i)
+			res, err := e.Execute(context.Background(), prog, ExecOptions{
+				TextStore: ts,
+				Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add(input)}},
+			})
+			if err != nil || res.Status != "ok" {
+				errs <- fmt.Errorf("run %d: %v %+v", i, err, res.Errors)
+				return
+			}
+			if got, _ := ts.Get(res.Final.V.(runtime.TextHandle)); got != strings.ToUpper(input) {
+				errs <- fmt.Errorf("run %d: got %q", i, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestEngine_ConcurrentSharedDocs(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	ts := NewTextStore()
+	corpus, err := NewDocs(ts, []Doc{{ID: "a", Text: "alpha"}, {ID: "b", Text: "beta"}})
+	if err != nil {
+		t.Fatalf("NewDocs failed: %v", err)
+	}
+	prog, err := e.Compile("doc.rlm", `RLMDSL 0.2
+TASK pick:
+  INPUT corpus: DOCS
+  CELL main:
+    GET_DOC SOURCE corpus ID "b" INTO doc: STRUCT
+  OUTPUT doc
+`, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	// Runs share the caller's DOCS value and must only read it; run with
+	// -race to check. Each run has a store of its own so that store locks
+	// do not order the runs' accesses to the shared value.
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := e.Execute(context.Background(), prog, ExecOptions{
+				TextStore: NewTextStore(),
+				Inputs:    map[string]runtime.Value{"corpus": corpus},
+			})
+			if err != nil || res.Status != "ok" {
+				errs <- fmt.Errorf("%v %+v", err, res.Errors)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestEngine_Run(t *testing.T) {
+	e, err := NewEngine(WithModule(shoutModule{}))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	ts := NewTextStore()
+	run, err := e.NewRun(ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add("hello")}},
+	})
+	if err != nil {
+		t.Fatalf("NewRun failed: %v", err)
+	}
+
+	turns := []string{
+		"RLMDSL 0.2\nTASK first:\n  INPUT PROMPT: TEXT\n  CELL main:\n    SHOUT SOURCE PROMPT INTO loud: TEXT\n  OUTPUT loud\n",
+		// loud comes from the first program; redefining it is an error.
+		"RLMDSL 0.2\nTASK second:\n  CELL main:\n    SHOUT SOURCE loud INTO loud: TEXT\n  OUTPUT loud\n",
+		"RLMDSL 0.2\nTASK second:\n  CELL main:\n    SHOUT SOURCE loud INTO louder: TEXT\n  OUTPUT louder\n",
+	}
+	var res ExecResult
+	for i, src := range turns {
+		prog, err := e.Compile(fmt.Sprintf("turn_%d.rlm", i), src, ModeCompat)
+		if err != nil {
+			t.Fatalf("turn %d: Compile failed: %v", i, err)
+		}
+		if res, err = run.Execute(context.Background(), prog); err != nil {
+			t.Fatalf("turn %d: Execute failed: %v", i, err)
+		}
+		if i == 1 {
+			if len(res.Errors) != 1 || res.Errors[0].Code != "LINT_VAR_REUSE_FORBIDDEN" {
+				t.Fatalf("turn 1: expected LINT_VAR_REUSE_FORBIDDEN, got %s %+v", res.Status, res.Errors)
+			}
+		} else if res.Status != "ok" {
+			t.Fatalf("turn %d: unexpected status %s %+v", i, res.Status, res.Errors)
+		}
+	}
+
+	obs := run.Result()
+	if obs.Final == nil || len(obs.VarsDelta) != 2 {
+		t.Fatalf("expected final result and two variables, got %+v", obs)
+	}
+	if text, _ := ts.Get(obs.Final.V.(runtime.TextHandle)); text != "HELLO" || obs.Final.V != obs.VarsDelta["louder"].V {
+		t.Errorf("expected the second program's HELLO, got %q", text)
+	}
+}
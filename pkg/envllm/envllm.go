@@ -1,23 +1,27 @@
+// Package envllm compiles and runs RLM-DSL programs. Its exported
+// identifiers, including the re-exported value, host, policy, store, trace
+// and result types, are the supported API and keep their names and meaning
+// across minor versions; packages under internal/ may change at any time.
 package envllm
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/agenthands/envllm/internal/ast"
 	"github.com/agenthands/envllm/internal/lex"
-	"github.com/agenthands/envllm/internal/ops"
 	"github.com/agenthands/envllm/internal/parse"
-	"github.com/agenthands/envllm/internal/lint"
 	"github.com/agenthands/envllm/internal/runtime"
 	"github.com/agenthands/envllm/internal/store"
-	"github.com/agenthands/envllm/internal/trace"
 )
 
 // Program represents a compiled RLM-DSL program.
 type Program struct {
-	AST *ast.Program
+	AST *AST
 }
 
 // ParseMode defines the parser strictness.
@@ -41,86 +45,144 @@ func Compile(filename string, src string, mode ParseMode) (*Program, error) {
 
 // ExecOptions defines the options for program execution.
 type ExecOptions struct {
-	Host      runtime.Host
-	Policy    runtime.Policy
-	Inputs    map[string]runtime.Value
-	TextStore runtime.TextStore
-	TraceSink trace.Sink
+	Host      Host
+	Policy    Policy
+	Inputs    map[string]Value
+	TextStore TextStore
+	TraceSink TraceSink
+	// Schemas registers named JSON schemas for VALIDATE_JSON and ASSERT_SCHEMA.
+	// A schema may $ref another registered one as "<name>.json"; no other
+	// $ref is loaded.
+	Schemas map[string]string
+	// Clock drives NOW and relative dates ("today"); nil means time.Now.
+	Clock func() time.Time
+	// OpsTable is the path of a custom ops table (ops.json) used for both
+	// linting and execution; empty means the built-in table.
+	OpsTable string
 }
 
-// NewTextStore creates a new TextStore.
-func NewTextStore() runtime.TextStore {
-	return store.NewTextStore()
+// NewTextStore creates a new TextStore. Slices and windows of its texts are
+// views rather than copies, and it is safe for concurrent use.
+func NewTextStore() TextStore {
+	return store.NewSharedStore(0)
 }
 
-// Execute executes the program using the provided options.
-func (p *Program) Execute(ctx context.Context, opt ExecOptions) (runtime.ExecResult, error) {
-	ts := opt.TextStore
-	if ts == nil {
-		ts = store.NewTextStore()
+// NewSharedTextStore creates a TextStore for sharing between concurrent
+// sessions. Give each session its own scope (NewScope) as its TextStore:
+// handles a session no longer references are freed at the end of each cell,
+// and closing the scope frees the rest. limit caps the stored bytes (0 means
+// no limit); a session that exceeds it fails with a budget error.
+func NewSharedTextStore(limit int64) *SharedTextStore {
+	return store.NewSharedStore(limit)
+}
+
+// NewDiskTextStore creates a TextStore that keeps text in append-only files
+// under dir instead of memory, for inputs larger than RAM. READ_FILE streams
+// into it, and FIND_TEXT and FIND_REGEX scan it without loading whole texts.
+// An empty dir uses a temporary directory. Close the store when done.
+func NewDiskTextStore(dir string) (*DiskTextStore, error) {
+	return store.NewDiskStore(dir)
+}
+
+// Doc is one document of a DOCS input.
+type Doc struct {
+	ID       string
+	Metadata map[string]interface{}
+	Text     string
+}
+
+// NewDocs adds the documents' text to ts and returns a DOCS value to bind
+// to an `INPUT name: DOCS` declaration. IDs must be unique and non-empty.
+func NewDocs(ts TextStore, docs []Doc) (Value, error) {
+	items := make([]map[string]interface{}, 0, len(docs))
+	seen := make(map[string]bool, len(docs))
+	for _, d := range docs {
+		if d.ID == "" {
+			return Value{}, fmt.Errorf("document %d has no ID", len(items))
+		}
+		if seen[d.ID] {
+			return Value{}, fmt.Errorf("duplicate document ID %q", d.ID)
+		}
+		seen[d.ID] = true
+		items = append(items, runtime.NewDoc(d.ID, d.Metadata, ts.Add(d.Text)))
 	}
+	return Value{Kind: KindDocs, V: items}, nil
+}
 
-	// Load ops table (default path for now)
-	tbl, err := ops.LoadTable("assets/ops.json")
-	if err != nil {
-		// Fallback for tests or relative paths
-		tbl, err = ops.LoadTable("../assets/ops.json")
+// LoadDocs reads every regular file under dir into ts and returns them as a
+// DOCS value, in lexical path order. A document's ID is its slash-separated
+// path relative to dir; its metadata holds path, name, size and modified
+// (RFC 3339). Files and directories whose names start with "." are skipped.
+// Stores that can stream files in, such as a DiskStore, are not made to hold
+// the files in memory.
+func LoadDocs(ts TextStore, dir string) (Value, error) {
+	var items []map[string]interface{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			tbl, err = ops.LoadTable("../../assets/ops.json")
-			if err != nil {
-				return runtime.ExecResult{}, fmt.Errorf("failed to load ops table: %v", err)
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
 			}
+			return nil
 		}
-	}
-	reg := ops.NewRegistry(tbl)
-
-	// Perform Linting with Trace
-	lnt := lint.NewLinter(tbl).WithSink(opt.TraceSink)
-	lintErrs := lnt.Lint(p.AST)
-	if len(lintErrs) > 0 {
-		var errs []runtime.Error
-		for _, le := range lintErrs {
-			errs = append(errs, runtime.Error{Code: le.Code, Message: le.Message, Hint: le.Hint})
+		if !d.Type().IsRegular() {
+			return nil
 		}
-		return runtime.ExecResult{Status: "error", Errors: errs}, nil
-	}
-
-	s := runtime.NewSession(opt.Policy, ts)
-	s.Dispatcher = reg
-	s.Host = opt.Host
-	s.TraceSink = opt.TraceSink
-
-	// Set inputs
-	for k, v := range opt.Inputs {
-		if err := s.Env.Define(k, v); err != nil {
-			return runtime.ExecResult{}, err
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
 		}
-	}
 
-	var lastErr error
-	if p.AST.Task != nil {
-		if err := s.ExecuteTask(ctx, p.AST.Task); err != nil {
-			lastErr = err
+		var h TextHandle
+		if fl, ok := ts.(TextFileLoader); ok {
+			if h, err = fl.AddFile(path); err != nil {
+				return err
+			}
+		} else {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			h = ts.Add(string(data))
 		}
+		items = append(items, runtime.NewDoc(filepath.ToSlash(rel), map[string]interface{}{
+			"path":     path,
+			"name":     d.Name(),
+			"size":     int(info.Size()),
+			"modified": info.ModTime().UTC().Format(time.RFC3339),
+		}, h))
+		return nil
+	})
+	if err != nil {
+		return Value{}, fmt.Errorf("load docs: %v", err)
+	}
+	if items == nil {
+		items = []map[string]interface{}{}
 	}
+	return Value{Kind: KindDocs, V: items}, nil
+}
 
-	status := "ok"
-	var errs []runtime.Error
-	if lastErr != nil {
-		status = "error"
-		var bErr *runtime.BudgetExceededError
-		var cErr *runtime.CapabilityDeniedError
-		if errors.As(lastErr, &bErr) {
-			status = "budget_exceeded"
-		} else if errors.As(lastErr, &cErr) {
-			status = "capability_denied"
+// Execute executes the program using the provided options. Without
+// opt.OpsTable it runs on a shared default Engine, so the built-in table is
+// loaded only once; with it, an Engine is built for this call.
+func (p *Program) Execute(ctx context.Context, opt ExecOptions) (ExecResult, error) {
+	if opt.OpsTable == "" {
+		e, err := defaultEngine()
+		if err != nil {
+			return ExecResult{}, err
 		}
-		
-		errs = append(errs, runtime.Error{
-			Code:    "EXEC_ERROR",
-			Message: lastErr.Error(),
-		})
+		return e.Execute(ctx, p, opt)
 	}
-
-	return s.GenerateResult(status, errs), nil
+	e, err := NewEngine(WithOpsTable(opt.OpsTable))
+	if err != nil {
+		return ExecResult{}, err
+	}
+	opt.OpsTable = ""
+	return e.Execute(ctx, p, opt)
 }
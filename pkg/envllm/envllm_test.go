@@ -2,9 +2,16 @@ package envllm
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
 )
 
 func TestPublicAPI(t *testing.T) {
@@ -30,3 +37,594 @@ CELL test:
 		t.Errorf("expected status ok, got %s", res.Status)
 	}
 }
+
+func TestExecute_AssertSchema(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK check:
+  INPUT PROMPT: TEXT
+  CELL main:
+    EXTRACT_JSON SOURCE PROMPT INTO data: JSON
+    VALIDATE_JSON SOURCE data SCHEMA "person" INTO report: STRUCT
+    ASSERT_SCHEMA SOURCE data SCHEMA "person" INTO ok: BOOL
+  OUTPUT ok
+`
+	prog, err := Compile("schema.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	ts := NewTextStore()
+	opt := ExecOptions{
+		TextStore: ts,
+		Inputs: map[string]runtime.Value{
+			"PROMPT": {Kind: runtime.KindText, V: ts.Add(`Result: {"name": 42}`)},
+		},
+		Schemas: map[string]string{
+			"person": `{"type": "object", "required": ["name", "age"], "properties": {"name": {"type": "string"}}}`,
+		},
+	}
+	res, err := prog.Execute(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if res.Status != "error" {
+		t.Fatalf("expected status error, got %s", res.Status)
+	}
+	report := res.VarsDelta["report"].V.(map[string]interface{})
+	if report["valid"] != false || report["error_count"] != 2 {
+		t.Errorf("unexpected report: %v", report)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].Code != "ERR_SCHEMA_VIOLATION" {
+		t.Fatalf("expected 2 schema violations, got %+v", res.Errors)
+	}
+	if res.Errors[0].Message != "/name: expected string, but got number" && res.Errors[1].Message != "/name: expected string, but got number" {
+		t.Errorf("expected violation at /name, got %+v", res.Errors)
+	}
+}
+
+func TestExecute_BuildRecord(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK invoice:
+  INPUT PROMPT: TEXT
+  CELL extract:
+    EXTRACT_VALUE SOURCE PROMPT KEY "Vendor:" UNTIL "\n" INTO vendor: TEXT
+    FIND_TEXT SOURCE PROMPT NEEDLE "Total" MODE FIRST IGNORE_CASE false INTO total_pos: OFFSET
+    JSON_OBJECT KEYS ["vendor", "total_at"] VALUES [vendor, total_pos] INTO rec: STRUCT
+    JSON_SET SOURCE rec PATH "meta.source" VALUE "prompt" INTO out: STRUCT
+  OUTPUT out
+`
+	prog, err := Compile("build.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	ts := NewTextStore()
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs: map[string]runtime.Value{
+			"PROMPT": {Kind: runtime.KindText, V: ts.Add("Vendor: ACME\nTotal: 12")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if res.Status != "ok" {
+		t.Fatalf("expected status ok, got %s: %+v", res.Status, res.Errors)
+	}
+
+	data, _ := json.Marshal(res.Final)
+	want := `{"kind":"STRUCT","v":{"meta":{"source":"prompt"},"total_at":13,"vendor":"ACME"}}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
+
+func TestExecute_DatesWithClock(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK due:
+  INPUT PROMPT: TEXT
+  CELL main:
+    EXTRACT_VALUE SOURCE PROMPT KEY "Due:" UNTIL "\n" INTO due_text: TEXT
+    PARSE_DATE SOURCE due_text LAYOUT "AUTO" INTO due: TIMESTAMP
+    NOW INTO now: TIMESTAMP
+    DATE_DIFF FROM now TO due UNIT DAYS INTO days_left: INT
+  OUTPUT days_left
+`
+	prog, err := Compile("due.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	ts := NewTextStore()
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs: map[string]runtime.Value{
+			"PROMPT": {Kind: runtime.KindText, V: ts.Add("Due: 15 March 2025\n")},
+		},
+		Clock: func() time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if res.Status != "ok" || res.Final == nil || res.Final.V != 14 {
+		t.Fatalf("expected 14 days left, got %s %+v %+v", res.Status, res.Final, res.Errors)
+	}
+}
+
+func TestExecute_SharedStoreScopes(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK find:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "key=" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    WINDOW_TEXT SOURCE PROMPT CENTER pos RADIUS 8 INTO win: TEXT
+  OUTPUT win
+`
+	prog, err := Compile("find.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	const prompt = "header ... key=42 ... trailer"
+	shared := NewSharedTextStore(0)
+	ph := shared.Add(prompt)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scope := shared.NewScope()
+			defer scope.Close()
+			res, err := prog.Execute(context.Background(), ExecOptions{
+				TextStore: scope,
+				Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+			})
+			if err != nil || res.Status != "ok" {
+				t.Errorf("Execute failed: %v %+v", err, res.Errors)
+				return
+			}
+			if got, _ := scope.Get(res.Final.V.(runtime.TextHandle)); got != "der ... key=42 ." {
+				t.Errorf("unexpected window %q", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The promoted NEEDLE strings and the windows are gone; the pinned
+	// prompt remains.
+	if got := shared.Bytes(); got != int64(len(prompt)) {
+		t.Errorf("expected %d bytes after all scopes closed, got %d", len(prompt), got)
+	}
+
+	limited := NewSharedTextStore(int64(len(prompt)))
+	ph = limited.Add(prompt)
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: limited.NewScope(),
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+	})
+	if err != nil || res.Status != "budget_exceeded" {
+		t.Errorf("expected budget_exceeded over the memory limit, got %v %s", err, res.Status)
+	}
+}
+
+func TestExecute_SpanProvenance(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK cite:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "The answer" MODE FIRST IGNORE_CASE false INTO from: OFFSET
+    FIND_TEXT SOURCE PROMPT NEEDLE " outro" MODE FIRST IGNORE_CASE false INTO to: OFFSET
+    SLICE_TEXT SOURCE PROMPT START from END to INTO part: TEXT
+    WINDOW_TEXT SOURCE part CENTER from RADIUS 100 INTO win: TEXT
+    FIND_TEXT SOURCE win NEEDLE "42" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    WINDOW_TEXT SOURCE PROMPT CENTER pos RADIUS 3 INTO around: TEXT
+    AS_SPAN OFFSET pos LEN 2 INTO local: SPAN
+    RESOLVE_SPAN SPAN local INTO root: SPAN
+  OUTPUT root
+`
+	prog, err := Compile("cite.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	const prompt = "intro text. The answer is 42. outro"
+	ts := NewTextStore()
+	ph := ts.Add(prompt)
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+
+	want := runtime.Span{Start: 26, End: 28, Source: ph.ID}
+	if got := res.Final.V.(runtime.Span); got != want {
+		t.Errorf("RESOLVE_SPAN: expected %+v, got %+v", want, got)
+	}
+	// pos was found in a window of a slice; using it on PROMPT translates it.
+	around := res.VarsDelta["around"].V.(runtime.TextHandle)
+	if got, _ := ts.Get(around); got != "is 42." {
+		t.Errorf("expected translated window %q, got %q", "is 42.", got)
+	}
+	if pos := res.VarsDelta["pos"]; pos.Source == ph.ID || pos.Source == "" {
+		t.Errorf("expected pos to be anchored to the window, got source %q", pos.Source)
+	}
+
+	// Offsets cannot be used with a text they were not derived from.
+	other := `RLMDSL 0.2
+TASK mix:
+  INPUT PROMPT: TEXT
+  INPUT OTHER: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "42" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    WINDOW_TEXT SOURCE OTHER CENTER pos RADIUS 3 INTO win: TEXT
+  OUTPUT win
+`
+	prog, err = Compile("mix.rlm", other, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	res, err = prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs: map[string]runtime.Value{
+			"PROMPT": {Kind: runtime.KindText, V: ph},
+			"OTHER":  {Kind: runtime.KindText, V: ts.Add("something else entirely")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if res.Status != "error" || len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "do not derive from the same text") {
+		t.Errorf("expected cross-text offset to be rejected, got %s %+v", res.Status, res.Errors)
+	}
+}
+
+func TestExecute_CiteEvidence(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK grounded:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "Total" MODE FIRST IGNORE_CASE false INTO at: OFFSET
+    WINDOW_TEXT SOURCE PROMPT CENTER at RADIUS 20 INTO win: TEXT
+    FIND_TEXT SOURCE win NEEDLE "1,250" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    AS_SPAN OFFSET pos LEN 5 INTO amount: SPAN
+    CITE SOURCE amount INTO c: STRUCT
+    SET_FINAL SOURCE amount CITING [c]
+  OUTPUT c
+`
+	prog, err := Compile("grounded.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	const prompt = "Invoice 7\nItems: 3\nTotal: 1,250 EUR\n"
+	ts := NewTextStore()
+	ph := ts.Add(prompt)
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+	if len(res.Citations) != 1 {
+		t.Fatalf("expected 1 citation, got %d", len(res.Citations))
+	}
+	c := res.Citations[0]
+	if c["quote"] != "1,250" || c["source"] != ph.ID || c["start"] != 26 || c["start_line"] != 3 || c["start_column"] != 8 {
+		t.Errorf("unexpected citation %v", c)
+	}
+
+	// Citations survive a JSON round trip of the result.
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"citations":[{`) || !strings.Contains(string(data), `"quote":"1,250"`) {
+		t.Errorf("citations missing from JSON: %s", data)
+	}
+}
+
+func TestExecute_BinaryFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.txt")
+	if err := os.WriteFile(path, []byte("Gr\xfc\xdfe aus Z\xfcrich"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := `RLMDSL 0.2
+TASK attachment:
+  INPUT FILE: TEXT
+  REQUIRES capability="fs_read"
+  CELL main:
+    READ_FILE PATH FILE MODE BINARY INTO raw: BYTES
+    BYTES_LEN SOURCE raw INTO size: INT
+    DECODE_TEXT SOURCE raw ENCODING LATIN1 INTO text: TEXT
+    ENCODE_BASE64 SOURCE raw INTO b64: TEXT
+    READ_FILE PATH FILE MODE TEXT INTO plain: TEXT
+  OUTPUT text
+`
+	prog, err := Compile("attachment.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ts := NewTextStore()
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Policy:    runtime.Policy{AllowedCapabilities: map[string]bool{"fs_read": true}, AllowedReadPaths: []string{dir}},
+		Inputs:    map[string]runtime.Value{"FILE": {Kind: runtime.KindString, V: path}},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+
+	if got, _ := ts.Get(res.Final.V.(runtime.TextHandle)); got != "Grüße aus Zürich" {
+		t.Errorf("unexpected decoded text %q", got)
+	}
+	if size := res.VarsDelta["size"]; size.V != 16 {
+		t.Errorf("expected 16 bytes, got %v", size.V)
+	}
+	if plain := res.VarsDelta["plain"]; plain.Kind != runtime.KindText {
+		t.Errorf("READ_FILE without MODE should read TEXT, got %s", plain.Kind)
+	}
+
+	// BYTES travel through JSON as base64.
+	data, _ := json.Marshal(res.VarsDelta["raw"])
+	if string(data) != `{"kind":"BYTES","v":"R3L832UgYXVzIFr8cmljaA=="}` {
+		t.Errorf("unexpected BYTES encoding %s", data)
+	}
+}
+
+func TestExecute_Docs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tickets/101.txt": "Login fails\nuser sees a timeout after 30s\n",
+		"tickets/102.txt": "Feature request: dark mode\n",
+		"tickets/103.txt": "Checkout slow\nTimeout in payment step\nretry helps\n",
+		".git/config":     "timeout = 5\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts := NewTextStore()
+	corpus, err := LoadDocs(ts, dir)
+	if err != nil {
+		t.Fatalf("LoadDocs failed: %v", err)
+	}
+	if n := len(corpus.V.([]map[string]interface{})); n != 3 {
+		t.Fatalf("expected 3 documents (hidden files skipped), got %d", n)
+	}
+
+	src := `RLMDSL 0.2
+TASK triage:
+  INPUT corpus: DOCS
+  CELL search:
+    GREP_DOCS SOURCE corpus PATTERN "(?i)timeout" CONTEXT 0 INTO hits: ROWS
+    GET_DOC SOURCE corpus ID "tickets/102.txt" INTO doc: STRUCT
+    FOR_EACH item IN corpus LIMIT 1:
+    GET_FIELD SOURCE item FIELD "id" INTO first_id: TEXT
+  OUTPUT hits
+`
+	prog, err := Compile("triage.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"corpus": corpus},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+
+	hits := res.Final.V.([]map[string]interface{})
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 hits, got %d: %v", len(hits), hits)
+	}
+	for i, want := range []struct {
+		doc  string
+		line int
+	}{{"tickets/101.txt", 2}, {"tickets/103.txt", 2}} {
+		if hits[i]["doc_id"] != want.doc || hits[i]["line"] != want.line {
+			t.Errorf("hit %d: expected %s line %d, got %v line %v", i, want.doc, want.line, hits[i]["doc_id"], hits[i]["line"])
+		}
+	}
+	// Spans point into the matching document, so CITE-style lookups work.
+	doc3, _ := runtime.DocText(corpus.V.([]map[string]interface{})[2])
+	if sp := hits[1]["match_span"].(runtime.Span); sp.Source != doc3.ID {
+		t.Errorf("expected match span in %s, got %s", doc3.ID, sp.Source)
+	}
+
+	doc := res.VarsDelta["doc"].V.(map[string]interface{})
+	if meta := doc["metadata"].(map[string]interface{}); meta["name"] != "102.txt" || meta["size"] != 27 {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+	if id, _ := ts.Get(res.VarsDelta["first_id"].V.(runtime.TextHandle)); id != "tickets/101.txt" {
+		t.Errorf("FOR_EACH: expected first document, got %q", id)
+	}
+
+	// Documents can also be passed directly; DOCS survive a JSON round trip.
+	direct, err := NewDocs(ts, []Doc{{ID: "a", Text: "alpha"}, {ID: "b", Metadata: map[string]interface{}{"lang": "en"}, Text: "beta"}})
+	if err != nil {
+		t.Fatalf("NewDocs failed: %v", err)
+	}
+	data, _ := json.Marshal(direct)
+	var back runtime.Value
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	items := back.V.([]map[string]interface{})
+	if h, ok := runtime.DocText(items[1]); back.Kind != runtime.KindDocs || !ok || items[1]["id"] != "b" {
+		t.Errorf("unexpected round trip %s -> %+v", data, back)
+	} else if text, _ := ts.Get(h); text != "beta" {
+		t.Errorf("expected text beta, got %q", text)
+	}
+	if _, err := NewDocs(ts, []Doc{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Error("expected error for duplicate IDs")
+	}
+}
+
+func TestExecute_DocsTextOps(t *testing.T) {
+	ts := NewTextStore()
+	corpus, err := NewDocs(ts, []Doc{
+		{ID: "a", Text: "no match here"},
+		{ID: "b", Text: "first Timeout, then timeout again"},
+		{ID: "c", Text: "TIMEOUT"},
+	})
+	if err != nil {
+		t.Fatalf("NewDocs failed: %v", err)
+	}
+	src := `RLMDSL 0.2
+TASK search:
+  INPUT corpus: DOCS
+  CELL main:
+    FIND_TEXT SOURCE corpus NEEDLE "timeout" MODE LAST IGNORE_CASE true INTO hits: ROWS
+    FIND_REGEX SOURCE corpus PATTERN "(?i)time(out)" MODE FIRST INTO matches: ROWS
+  OUTPUT hits
+`
+	prog, err := Compile("search.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	res, err := prog.Execute(context.Background(), ExecOptions{
+		TextStore: ts,
+		Inputs:    map[string]runtime.Value{"corpus": corpus},
+	})
+	if err != nil || res.Status != "ok" {
+		t.Fatalf("Execute failed: %v %+v", err, res.Errors)
+	}
+
+	// One row per document with a hit, naming the document; offsets and
+	// spans point into that document's TEXT.
+	docs := corpus.V.([]map[string]interface{})
+	hits := res.Final.V.([]map[string]interface{})
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 hits, got %v", hits)
+	}
+	for i, want := range []struct {
+		doc   string
+		index int
+		pos   int
+	}{{"b", 1, 20}, {"c", 2, 0}} {
+		text, _ := runtime.DocText(docs[want.index])
+		pos := runtime.Value{Kind: runtime.KindOffset, V: want.pos, Source: text.ID}
+		if hits[i]["doc_id"] != want.doc || hits[i]["doc_index"] != want.index || hits[i]["offset"] != pos {
+			t.Errorf("hit %d: expected %s at %+v, got %v", i, want.doc, pos, hits[i])
+		}
+	}
+
+	matches := res.VarsDelta["matches"].V.([]map[string]interface{})
+	if len(matches) != 2 || matches[0]["doc_id"] != "b" || matches[1]["doc_id"] != "c" {
+		t.Fatalf("expected matches in b and c, got %v", matches)
+	}
+	textB, _ := runtime.DocText(docs[1])
+	if sp := matches[0]["span"].(runtime.Span); sp != (runtime.Span{Start: 6, End: 13, Source: textB.ID}) {
+		t.Errorf("expected the match span in b, got %+v", sp)
+	}
+	if _, err := json.Marshal(res); err != nil {
+		t.Errorf("expected the result to marshal, got %v", err)
+	}
+}
+
+func TestExecute_OpsTableOverride(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK count:
+  INPUT PROMPT: TEXT
+  CELL main:
+    STATS SOURCE PROMPT INTO stats: STRUCT
+  OUTPUT stats
+`
+	prog, err := Compile("count.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	run := func(opsTable string) (runtime.ExecResult, error) {
+		ts := NewTextStore()
+		return prog.Execute(context.Background(), ExecOptions{
+			TextStore: ts,
+			Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ts.Add("a\nb")}},
+			OpsTable:  opsTable,
+		})
+	}
+
+	// The built-in table works from any working directory.
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	if res, err := run(""); err != nil || res.Status != "ok" {
+		t.Fatalf("Execute with built-in table failed: %v %+v", err, res.Errors)
+	}
+
+	dir := t.TempDir()
+	good := filepath.Join(wd, "../../assets/ops.json")
+	if res, err := run(good); err != nil || res.Status != "ok" {
+		t.Fatalf("Execute with custom table failed: %v %+v", err, res.Errors)
+	}
+
+	broken := filepath.Join(dir, "broken.json")
+	os.WriteFile(broken, []byte(`{"version": "0.2", "ops": [{"name": ""}]}`), 0o644)
+	drifted := filepath.Join(dir, "drifted.json")
+	os.WriteFile(drifted, []byte(`{"version": "0.2", "ops": [{"name": "STATS", "capabilities": ["pure"], "result_type": "INT", "signature": [{"kw": "SOURCE", "type": "TEXT"}], "into": true}]}`), 0o644)
+	for path, want := range map[string]string{
+		filepath.Join(dir, "missing.json"): "no such file",
+		broken:                             "op 0 has no name",
+		drifted:                            "op STATS disagrees with the ops table",
+	} {
+		_, err := run(path)
+		if err == nil || !strings.Contains(err.Error(), "failed to load ops table") || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected ops table error mentioning %q, got %v", filepath.Base(path), want, err)
+		}
+	}
+}
+
+func TestExecute_SpanProvenanceRepeatedContent(t *testing.T) {
+	src := `RLMDSL 0.2
+TASK cite:
+  INPUT PROMPT: TEXT
+  CELL main:
+    FIND_TEXT SOURCE PROMPT NEEDLE "abc" MODE FIRST IGNORE_CASE false INTO a: OFFSET
+    FIND_TEXT SOURCE PROMPT NEEDLE "-" MODE FIRST IGNORE_CASE false INTO a_end: OFFSET
+    FIND_TEXT SOURCE PROMPT NEEDLE "abc" MODE LAST IGNORE_CASE false INTO b: OFFSET
+    FIND_TEXT SOURCE PROMPT NEEDLE "-" MODE LAST IGNORE_CASE false INTO b_end: OFFSET
+    SLICE_TEXT SOURCE PROMPT START a END a_end INTO first: TEXT
+    SLICE_TEXT SOURCE PROMPT START b END b_end INTO second: TEXT
+    FIND_TEXT SOURCE second NEEDLE "c" MODE FIRST IGNORE_CASE false INTO pos: OFFSET
+    AS_SPAN OFFSET pos LEN 1 INTO local: SPAN
+    RESOLVE_SPAN SPAN local INTO root: SPAN
+  OUTPUT root
+`
+	prog, err := Compile("repeat.rlm", src, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	// Both slices hold "abc"; the span found in the second must resolve to
+	// the second occurrence whatever the store.
+	disk, err := NewDiskTextStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskTextStore failed: %v", err)
+	}
+	defer disk.Close()
+	for name, ts := range map[string]TextStore{"flat": store.NewTextStore(), "rope": NewTextStore(), "disk": disk} {
+		ph := ts.Add("abc-abc-")
+		res, err := prog.Execute(context.Background(), ExecOptions{
+			TextStore: ts,
+			Inputs:    map[string]runtime.Value{"PROMPT": {Kind: runtime.KindText, V: ph}},
+		})
+		if err != nil || res.Status != "ok" {
+			t.Fatalf("%s: Execute failed: %v %+v", name, err, res.Errors)
+		}
+		want := runtime.Span{Start: 6, End: 7, Source: ph.ID}
+		if got := res.Final.V.(runtime.Span); got != want {
+			t.Errorf("%s: expected %+v, got %+v", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package envllm_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agenthands/envllm/pkg/envllm"
+)
+
+// echoHost answers every SUBCALL with the task name in upper case.
+type echoHost struct{}
+
+func (echoHost) Subcall(ctx context.Context, req envllm.SubcallRequest) (envllm.SubcallResponse, error) {
+	return envllm.SubcallResponse{Result: envllm.Value{Kind: envllm.KindJSON, V: strings.ToUpper(req.Task)}}, nil
+}
+
+// Programs are built and run with this package's types only; no internal
+// package is needed to supply inputs, a Host or a Policy.
+func Example() {
+	prog, err := envllm.Compile("hello.rlm", `RLMDSL 0.2
+TASK hello:
+  INPUT PROMPT: TEXT
+  REQUIRES capability="llm"
+  CELL main:
+    SUBCALL SOURCE PROMPT TASK "greet" DEPTH_COST 1 INTO reply: JSON
+  OUTPUT reply
+`, envllm.ModeStrict)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ts := envllm.NewTextStore()
+	res, err := prog.Execute(context.Background(), envllm.ExecOptions{
+		Host:      echoHost{},
+		Policy:    envllm.Policy{MaxSubcalls: 1, MaxRecursionDepth: 1, AllowedCapabilities: map[string]bool{"llm": true}},
+		TextStore: ts,
+		Inputs:    map[string]envllm.Value{"PROMPT": {Kind: envllm.KindText, V: ts.Add("hi")}},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(res.Status, res.Final.V)
+	// Output: ok GREET
+}
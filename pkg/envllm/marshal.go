@@ -0,0 +1,530 @@
+package envllm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Struct fields are matched to STRUCT keys and ROWS columns by their
+// `envllm` tag, then their `json` tag, then their name (case-insensitive).
+// A tag of "-" skips the field; ",omitempty" leaves zero values out of
+// ToValue.
+
+var (
+	valueType      = reflect.TypeOf(Value{})
+	textHandleType = reflect.TypeOf(TextHandle{})
+	spanType       = reflect.TypeOf(Span{})
+	timeType       = reflect.TypeOf(time.Time{})
+	bytesType      = reflect.TypeOf([]byte(nil))
+)
+
+// ToValue converts a Go value into a Value: bools, integers, floats and
+// strings become BOOL, INT, FLOAT and STRING (ops taking TEXT accept
+// STRING), []byte becomes BYTES, time.Time TIMESTAMP, TextHandle TEXT and
+// Span SPAN. Structs and string-keyed maps become STRUCT, slices of them
+// ROWS, and other slices LIST. A Value is returned unchanged and nil becomes
+// NULL.
+func ToValue(x interface{}) (Value, error) {
+	if x == nil {
+		return Value{Kind: KindNull}, nil
+	}
+	return toValue(reflect.ValueOf(x), "value")
+}
+
+func toValue(rv reflect.Value, path string) (Value, error) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return Value{Kind: KindNull}, nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Type() {
+	case valueType:
+		return rv.Interface().(Value), nil
+	case textHandleType:
+		return Value{Kind: KindText, V: rv.Interface()}, nil
+	case spanType:
+		return Value{Kind: KindSpan, V: rv.Interface()}, nil
+	case timeType:
+		return Value{Kind: KindTimestamp, V: rv.Interface()}, nil
+	case bytesType:
+		return Value{Kind: KindBytes, V: rv.Bytes()}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return Value{Kind: KindBool, V: rv.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Value{Kind: KindInt, V: int(rv.Int())}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt {
+			return Value{}, fmt.Errorf("%s: %d overflows INT", path, rv.Uint())
+		}
+		return Value{Kind: KindInt, V: int(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return Value{Kind: KindFloat, V: rv.Float()}, nil
+	case reflect.String:
+		return Value{Kind: KindString, V: rv.String()}, nil
+	case reflect.Struct, reflect.Map:
+		m, err := toFields(rv, path)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Kind: KindStruct, V: m}, nil
+	case reflect.Slice, reflect.Array:
+		elem := rv.Type().Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if isRecord(elem) {
+			rows := make([]map[string]interface{}, rv.Len())
+			for i := range rows {
+				e := rv.Index(i)
+				for e.Kind() == reflect.Pointer && !e.IsNil() {
+					e = e.Elem()
+				}
+				if e.Kind() == reflect.Pointer {
+					return Value{}, fmt.Errorf("%s[%d]: nil row", path, i)
+				}
+				m, err := toFields(e, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return Value{}, err
+				}
+				rows[i] = m
+			}
+			return Value{Kind: KindRows, V: rows}, nil
+		}
+		list := make([]Value, rv.Len())
+		for i := range list {
+			v, err := toValue(rv.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return Value{}, err
+			}
+			list[i] = v
+		}
+		return Value{Kind: KindList, V: list}, nil
+	}
+	return Value{}, fmt.Errorf("%s: cannot convert %s to a Value", path, rv.Type())
+}
+
+// isRecord reports whether values of t become STRUCT (and slices of them
+// ROWS).
+func isRecord(t reflect.Type) bool {
+	switch t {
+	case valueType, textHandleType, spanType, timeType:
+		return false
+	}
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// toFields converts a struct or string-keyed map into STRUCT fields holding
+// plain Go values, as ops produce them.
+func toFields(rv reflect.Value, path string) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if rv.Kind() == reflect.Map {
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s: map keys must be strings, got %s", path, rv.Type().Key())
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			x, err := toPlain(iter.Value(), path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = x
+		}
+		return m, nil
+	}
+	for _, f := range structFields(rv.Type()) {
+		fv := rv.FieldByIndex(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		x, err := toPlain(fv, path+"."+f.name)
+		if err != nil {
+			return nil, err
+		}
+		m[f.name] = x
+	}
+	return m, nil
+}
+
+// toPlain converts a field value into the Go value ops store inside a
+// STRUCT or row: scalars, TextHandle, Span, time.Time and []byte as they
+// are, nested records as maps and other slices as []interface{}.
+func toPlain(rv reflect.Value, path string) (interface{}, error) {
+	v, err := toValue(rv, path)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.V.(type) {
+	case []Value:
+		out := make([]interface{}, len(x))
+		for i, e := range x {
+			out[i] = e.V
+		}
+		return out, nil
+	case []map[string]interface{}:
+		out := make([]interface{}, len(x))
+		for i, e := range x {
+			out[i] = e
+		}
+		return out, nil
+	}
+	return v.V, nil
+}
+
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the exported fields of t with their DSL names.
+func structFields(t reflect.Type) []fieldInfo {
+	var out []fieldInfo
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("envllm")
+		if !ok {
+			tag = f.Tag.Get("json")
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out = append(out, fieldInfo{name: name, index: f.Index, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+	}
+	return out
+}
+
+// FromValue decodes v into dst, which must be a non-nil pointer. TEXT
+// values are read from ts when dst wants a string; a TextHandle
+// destination receives the handle itself. STRUCT and JSON objects decode
+// into structs and maps, ROWS and DOCS into slices of them, LIST into
+// slices, and numbers convert between integer and float types when no
+// precision is lost. An interface{} destination receives plain Go values
+// with texts resolved. ts may be nil if v holds no TEXT.
+func FromValue(ts TextStore, v Value, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode: destination must be a non-nil pointer, got %T", dst)
+	}
+	d := decoder{ts: ts}
+	return d.decode(rv.Elem(), v, "value")
+}
+
+type decoder struct {
+	ts TextStore
+}
+
+func (d decoder) text(h TextHandle, path string) (string, error) {
+	if d.ts == nil {
+		return "", fmt.Errorf("%s: TEXT %s needs a text store to decode", path, h.ID)
+	}
+	s, ok := d.ts.Get(h)
+	if !ok {
+		return "", fmt.Errorf("%s: TEXT %s is not in the text store", path, h.ID)
+	}
+	return s, nil
+}
+
+// decode stores x, a Value or a plain Go value found inside one, in dst.
+func (d decoder) decode(dst reflect.Value, x interface{}, path string) error {
+	if v, ok := x.(Value); ok {
+		if v.Kind == KindNull {
+			x = nil
+		} else {
+			x = v.V
+		}
+	}
+	if dst.Type() == valueType {
+		dst.Set(reflect.ValueOf(toAnyValue(x)))
+		return nil
+	}
+	if x == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.decode(dst.Elem(), x, path)
+	}
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		plain, err := d.plain(x, path)
+		if err != nil {
+			return err
+		}
+		if plain != nil {
+			dst.Set(reflect.ValueOf(plain))
+		}
+		return nil
+	}
+
+	src := reflect.ValueOf(x)
+	if src.Type().AssignableTo(dst.Type()) && (dst.Kind() != reflect.Slice && dst.Kind() != reflect.Map || dst.Type() == bytesType) {
+		dst.Set(src)
+		return nil
+	}
+	if h, ok := x.(TextHandle); ok {
+		s, err := d.text(h, path)
+		if err != nil {
+			return err
+		}
+		return d.decode(dst, s, path)
+	}
+
+	switch dst.Type() {
+	case timeType:
+		s, ok := x.(string)
+		if !ok {
+			return mismatch(path, x, dst)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case bytesType:
+		s, ok := x.(string)
+		if !ok {
+			return mismatch(path, x, dst)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		dst.SetBytes(b)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		if s, ok := x.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := x.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := integer(x); ok {
+			if dst.OverflowInt(i) {
+				return overflow(path, x, dst)
+			}
+			dst.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, ok := integer(x); ok {
+			if i < 0 || dst.OverflowUint(uint64(i)) {
+				return overflow(path, x, dst)
+			}
+			dst.SetUint(uint64(i))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := number(x); ok {
+			if dst.OverflowFloat(f) {
+				return overflow(path, x, dst)
+			}
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice {
+			break
+		}
+		out := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := d.decode(out.Index(i), src.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Map:
+		m, ok := x.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			break
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(m))
+		for k, e := range m {
+			ev := reflect.New(dst.Type().Elem()).Elem()
+			if err := d.decode(ev, e, path+"."+k); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), ev)
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Struct:
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, f := range structFields(dst.Type()) {
+			e, ok := m[f.name]
+			if !ok {
+				e, ok = lookupFold(m, f.name)
+			}
+			if !ok {
+				continue
+			}
+			if err := d.decode(dst.FieldByIndex(f.index), e, path+"."+f.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return mismatch(path, x, dst)
+}
+
+// plain returns x as plain Go values with texts resolved to strings.
+func (d decoder) plain(x interface{}, path string) (interface{}, error) {
+	switch v := x.(type) {
+	case Value:
+		if v.Kind == KindNull {
+			return nil, nil
+		}
+		return d.plain(v.V, path)
+	case TextHandle:
+		return d.text(v, path)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			p, err := d.plain(e, path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = p
+		}
+		return out, nil
+	}
+	rv := reflect.ValueOf(x)
+	if rv.Kind() == reflect.Slice && rv.Type() != bytesType {
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			p, err := d.plain(rv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = p
+		}
+		return out, nil
+	}
+	return x, nil
+}
+
+// toAnyValue wraps a plain Go value found inside a Value so that a Value
+// destination can hold it.
+func toAnyValue(x interface{}) Value {
+	if x == nil {
+		return Value{Kind: KindNull}
+	}
+	if v, ok := x.(Value); ok {
+		return v
+	}
+	if v, err := ToValue(x); err == nil {
+		return v
+	}
+	return Value{Kind: KindJSON, V: x}
+}
+
+func lookupFold(m map[string]interface{}, name string) (interface{}, bool) {
+	for k, e := range m {
+		if strings.EqualFold(k, name) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func number(x interface{}) (float64, bool) {
+	switch n := x.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// integer returns x as an int64 when it holds a whole number. Integer
+// sources are converted directly so that values beyond 2^53 stay exact.
+func integer(x interface{}) (int64, bool) {
+	switch n := x.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+	}
+	f, ok := number(x)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func mismatch(path string, x interface{}, dst reflect.Value) error {
+	return fmt.Errorf("%s: cannot decode %T into %s", path, x, dst.Type())
+}
+
+func overflow(path string, x interface{}, dst reflect.Value) error {
+	return fmt.Errorf("%s: %v overflows %s", path, x, dst.Type())
+}
+
+// ExecuteInto executes p and decodes its OUTPUT variable into a T with
+// FromValue. A run that does not finish with status "ok" is an error; the
+// result is returned either way. Without opt.TextStore a fresh store is
+// used so that TEXT outputs can be read back.
+func ExecuteInto[T any](ctx context.Context, p *Program, opt ExecOptions) (T, ExecResult, error) {
+	var out T
+	if opt.TextStore == nil {
+		opt.TextStore = NewTextStore()
+	}
+	res, err := p.Execute(ctx, opt)
+	if err != nil {
+		return out, res, err
+	}
+	if res.Status != "ok" {
+		msgs := make([]string, len(res.Errors))
+		for i, e := range res.Errors {
+			msgs[i] = e.Code + ": " + e.Message
+		}
+		return out, res, fmt.Errorf("execution %s: %s", res.Status, strings.Join(msgs, "; "))
+	}
+	if res.Final == nil {
+		return out, res, fmt.Errorf("program produced no output")
+	}
+	if err := FromValue(opt.TextStore, *res.Final, &out); err != nil {
+		return out, res, fmt.Errorf("decode output: %v", err)
+	}
+	return out, res, nil
+}
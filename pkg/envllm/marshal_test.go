@@ -0,0 +1,178 @@
+package envllm
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type invoice struct {
+	ID     string `envllm:"id"`
+	Amount int    `json:"amount"`
+	Note   string `json:"note,omitempty"`
+	Secret string `envllm:"-"`
+	Rate   float64
+}
+
+func TestToValue(t *testing.T) {
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in   interface{}
+		kind Kind
+		v    interface{}
+	}{
+		{nil, KindNull, nil},
+		{true, KindBool, true},
+		{int64(7), KindInt, 7},
+		{2.5, KindFloat, 2.5},
+		{"hi", KindString, "hi"},
+		{[]byte{1, 2}, KindBytes, []byte{1, 2}},
+		{when, KindTimestamp, when},
+		{Span{Start: 1, End: 3}, KindSpan, Span{Start: 1, End: 3}},
+		{[]int{1, 2}, KindList, []Value{{Kind: KindInt, V: 1}, {Kind: KindInt, V: 2}}},
+		{invoice{ID: "a", Amount: 3, Secret: "x", Rate: 0.5}, KindStruct, map[string]interface{}{"id": "a", "amount": 3, "Rate": 0.5}},
+		{[]*invoice{{ID: "a", Note: "n"}}, KindRows, []map[string]interface{}{{"id": "a", "amount": 0, "note": "n", "Rate": 0.0}}},
+		{map[string][]string{"tags": {"x"}}, KindStruct, map[string]interface{}{"tags": []interface{}{"x"}}},
+	}
+	for _, tc := range tests {
+		v, err := ToValue(tc.in)
+		if err != nil {
+			t.Errorf("ToValue(%v) failed: %v", tc.in, err)
+			continue
+		}
+		if v.Kind != tc.kind || !reflect.DeepEqual(v.V, tc.v) {
+			t.Errorf("ToValue(%v) = %s %#v, expected %s %#v", tc.in, v.Kind, v.V, tc.kind, tc.v)
+		}
+	}
+
+	if _, err := ToValue(map[int]string{1: "a"}); err == nil {
+		t.Error("expected an error for a map without string keys")
+	}
+	if _, err := ToValue(make(chan int)); err == nil {
+		t.Error("expected an error for a channel")
+	}
+	if _, err := ToValue(uint64(math.MaxUint64)); err == nil || !strings.Contains(err.Error(), "overflows INT") {
+		t.Errorf("expected an overflow error for MaxUint64, got %v", err)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	ts := NewTextStore()
+	note := ts.Add("paid late")
+
+	rows := Value{Kind: KindRows, V: []map[string]interface{}{
+		{"id": "a", "amount": 3.0, "note": note, "rate": 1},
+		{"ID": "b", "amount": 4, "Secret": "ignored"},
+	}}
+	var got []invoice
+	if err := FromValue(ts, rows, &got); err != nil {
+		t.Fatalf("FromValue failed: %v", err)
+	}
+	want := []invoice{{ID: "a", Amount: 3, Note: "paid late", Rate: 1}, {ID: "b", Amount: 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	var s string
+	if err := FromValue(ts, Value{Kind: KindText, V: note}, &s); err != nil || s != "paid late" {
+		t.Errorf("expected TEXT to resolve, got %q %v", s, err)
+	}
+	var h TextHandle
+	if err := FromValue(ts, Value{Kind: KindText, V: note}, &h); err != nil || h.ID != note.ID {
+		t.Errorf("expected the handle itself, got %v %v", h, err)
+	}
+
+	var plain interface{}
+	if err := FromValue(ts, Value{Kind: KindStruct, V: map[string]interface{}{"n": note, "xs": []Value{{Kind: KindInt, V: 1}}}}, &plain); err != nil {
+		t.Fatalf("FromValue into interface{} failed: %v", err)
+	}
+	if !reflect.DeepEqual(plain, map[string]interface{}{"n": "paid late", "xs": []interface{}{1}}) {
+		t.Errorf("unexpected plain value %#v", plain)
+	}
+
+	var ptr *invoice
+	if err := FromValue(ts, Value{Kind: KindJSON, V: map[string]interface{}{"id": "c"}}, &ptr); err != nil || ptr == nil || ptr.ID != "c" {
+		t.Errorf("expected a decoded pointer, got %+v %v", ptr, err)
+	}
+
+	var n int
+	for _, tc := range []struct {
+		v    Value
+		ts   TextStore
+		want string
+	}{
+		{Value{Kind: KindFloat, V: 2.5}, ts, "cannot decode float64 into int"},
+		{Value{Kind: KindText, V: note}, nil, "needs a text store"},
+		{Value{Kind: KindRows, V: []map[string]interface{}{{"amount": "x"}}}, ts, "value[0].amount: cannot decode string into int"},
+	} {
+		var err error
+		if tc.v.Kind == KindRows {
+			var rows []invoice
+			err = FromValue(tc.ts, tc.v, &rows)
+		} else {
+			err = FromValue(tc.ts, tc.v, &n)
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("expected error %q, got %v", tc.want, err)
+		}
+	}
+	if err := FromValue(ts, Value{Kind: KindInt, V: 1}, n); err == nil {
+		t.Error("expected an error for a non-pointer destination")
+	}
+
+	var big int64
+	if err := FromValue(ts, Value{Kind: KindInt, V: 1<<53 + 1}, &big); err != nil || big != 1<<53+1 {
+		t.Errorf("expected %d to decode exactly, got %d %v", 1<<53+1, big, err)
+	}
+	var small int8
+	if err := FromValue(ts, Value{Kind: KindInt, V: 300}, &small); err == nil || !strings.Contains(err.Error(), "overflows int8") {
+		t.Errorf("expected an int8 overflow error, got %v", err)
+	}
+	var u uint
+	if err := FromValue(ts, Value{Kind: KindInt, V: -1}, &u); err == nil || !strings.Contains(err.Error(), "overflows uint") {
+		t.Errorf("expected a uint overflow error, got %v", err)
+	}
+	var f32 float32
+	if err := FromValue(ts, Value{Kind: KindFloat, V: 1e300}, &f32); err == nil || !strings.Contains(err.Error(), "overflows float32") {
+		t.Errorf("expected a float32 overflow error, got %v", err)
+	}
+}
+
+func TestExecuteInto(t *testing.T) {
+	prog, err := Compile("sort.rlm", `RLMDSL 0.2
+TASK sort:
+  INPUT invoices: ROWS
+  CELL main:
+    SORT_ROWS SOURCE invoices KEY "amount" ORDER DESC INTO sorted: ROWS
+  OUTPUT sorted
+`, ModeStrict)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	in, err := ToValue([]invoice{{ID: "a", Amount: 3}, {ID: "b", Amount: 9}})
+	if err != nil {
+		t.Fatalf("ToValue failed: %v", err)
+	}
+
+	got, res, err := ExecuteInto[[]invoice](context.Background(), prog, ExecOptions{
+		Inputs: map[string]Value{"invoices": in},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteInto failed: %v %+v", err, res.Errors)
+	}
+	if len(got) != 2 || got[0].ID != "b" || got[1].Amount != 3 {
+		t.Errorf("unexpected rows %+v", got)
+	}
+
+	if _, _, err := ExecuteInto[string](context.Background(), prog, ExecOptions{
+		Inputs: map[string]Value{"invoices": in},
+	}); err == nil || !strings.Contains(err.Error(), "decode output") {
+		t.Errorf("expected a decode error, got %v", err)
+	}
+	if _, res, err := ExecuteInto[[]invoice](context.Background(), prog, ExecOptions{}); err == nil || res.Status == "ok" {
+		t.Errorf("expected a failed run to be an error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package envllm
+
+import (
+	"github.com/agenthands/envllm/internal/ast"
+	"github.com/agenthands/envllm/internal/runtime"
+	"github.com/agenthands/envllm/internal/store"
+	"github.com/agenthands/envllm/internal/trace"
+)
+
+// The types below are the supported public names for values, hosts,
+// policies, text stores, trace sinks, results and errors. They are aliases,
+// so values flow freely between them and the runtime, but code outside this
+// module should only name them through this package: their names, fields
+// and methods follow the module's compatibility guarantees, the internal
+// packages they point at do not.
+
+// Values.
+type (
+	// Kind is the type of a DSL value, such as KindText or KindRows.
+	Kind = runtime.Kind
+	// Value is a typed DSL value. V holds a Go value that depends on Kind:
+	// int (INT, OFFSET), float64 (FLOAT), bool (BOOL), string (STRING),
+	// TextHandle (TEXT), Span (SPAN), []byte (BYTES), time.Time
+	// (TIMESTAMP), map[string]interface{} (STRUCT), []map[string]interface{}
+	// (ROWS, DOCS), []Value (LIST) and decoded JSON (JSON).
+	Value = runtime.Value
+	// TextHandle refers to a text held in a TextStore.
+	TextHandle = runtime.TextHandle
+	// Span is a byte range of a text.
+	Span = runtime.Span
+)
+
+const (
+	KindInt       = runtime.KindInt
+	KindFloat     = runtime.KindFloat
+	KindBool      = runtime.KindBool
+	KindText      = runtime.KindText
+	KindJSON      = runtime.KindJSON
+	KindSpan      = runtime.KindSpan
+	KindString    = runtime.KindString
+	KindList      = runtime.KindList
+	KindNull      = runtime.KindNull
+	KindOffset    = runtime.KindOffset
+	KindCost      = runtime.KindCost
+	KindStruct    = runtime.KindStruct
+	KindRows      = runtime.KindRows
+	KindTimestamp = runtime.KindTimestamp
+	KindBytes     = runtime.KindBytes
+	KindDocs      = runtime.KindDocs
+)
+
+// Hosts, sessions and policies.
+type (
+	// Host answers SUBCALL requests, usually by prompting a model.
+	Host = runtime.Host
+	// SubcallRequest is what a program asks its Host to do.
+	SubcallRequest = runtime.SubcallRequest
+	// SubcallResponse is the Host's answer to a SubcallRequest.
+	SubcallResponse = runtime.SubcallResponse
+	// Policy bounds what a program may do and spend.
+	Policy = runtime.Policy
+	// Session is the state of one execution, as seen by op handlers.
+	Session = runtime.Session
+)
+
+// Text stores.
+type (
+	// TextStore holds the TEXT values of a session.
+	TextStore = runtime.TextStore
+	// TextFileLoader is implemented by stores that can add a file without
+	// reading it into memory.
+	TextFileLoader = runtime.TextFileLoader
+	// SharedTextStore is a TextStore sessions can share; see
+	// NewSharedTextStore.
+	SharedTextStore = store.SharedStore
+	// TextStoreScope is a view of a SharedTextStore with its own handles.
+	TextStoreScope = store.Scope
+	// DiskTextStore keeps texts in files; see NewDiskTextStore.
+	DiskTextStore = store.DiskStore
+)
+
+// Trace sinks.
+type (
+	// TraceSink receives a TraceStep for each decision of the linter and
+	// runtime.
+	TraceSink = trace.Sink
+	// TraceStep is one recorded step of a program's lifecycle.
+	TraceStep = trace.TraceStep
+	// TraceError describes why a TraceStep was rejected.
+	TraceError = trace.TraceError
+	// MemoryTraceSink keeps trace steps in memory.
+	MemoryTraceSink = trace.MemorySink
+)
+
+// NewJSONLTraceSink returns a TraceSink appending one JSON line per step to
+// the file at path.
+func NewJSONLTraceSink(path string) (TraceSink, error) {
+	return trace.NewJSONLSink(path)
+}
+
+// Results and errors.
+type (
+	// ExecResult is the outcome of an execution.
+	ExecResult = runtime.ExecResult
+	// Error is one error reported in an ExecResult.
+	Error = runtime.Error
+	// BudgetExceededError is returned when a Policy limit is hit.
+	BudgetExceededError = runtime.BudgetExceededError
+	// CapabilityDeniedError is returned when an op needs a capability the
+	// Policy does not allow.
+	CapabilityDeniedError = runtime.CapabilityDeniedError
+	// SchemaViolationError is returned when a value fails a JSON schema.
+	SchemaViolationError = runtime.SchemaViolationError
+	// SchemaViolation is one failed check of a SchemaViolationError.
+	SchemaViolation = runtime.SchemaViolation
+)
+
+// AST is the syntax tree of a compiled program.
+type AST = ast.Program